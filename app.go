@@ -28,6 +28,7 @@ func NewApp() *App {
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
 	a.OSSService.SetContext(ctx)
+	go a.OSSService.runScheduledTrashPurge(ctx)
 }
 
 // Greet returns a greeting for the given name
@@ -7,10 +7,11 @@ import AboutModal from './components/AboutModal';
 import FileBrowser from './components/FileBrowser';
 import TransferModal from './components/TransferModal';
 import { main } from '../wailsjs/go/models';
-import { CheckOssutilInstalled, GetSettings, GetTransferHistory, MoveObject } from '../wailsjs/go/main/OSSService';
+import { CheckOssutilInstalled, EnqueueCrossProfileCopy, GetOperationJournal, GetProfile, GetSettings, GetTransferHistory, MoveObject, ResumeCopyTransfer, UndoLastOperation } from '../wailsjs/go/main/OSSService';
 import { GetAppInfo, OpenFile, OpenInFinder } from '../wailsjs/go/main/App';
 import { EventsEmit, EventsOn, OnFileDrop, OnFileDropOff } from '../wailsjs/runtime/runtime';
-import { canReadOssDragPayload, readOssDragPayload } from './ossDrag';
+import { canReadOssDragPayload, copySourcePaths, isCrossProfileDrag, readOssDragPayload } from './ossDrag';
+import { childObjectKey, objectKeyOf } from './objectKeys';
 import { enqueueUploadWithRenamePrompt } from './upload';
 
 type GlobalView = 'session' | 'settings';
@@ -24,7 +25,7 @@ type AppTab = {
 };
 
 type TransferStatus = 'queued' | 'in-progress' | 'success' | 'error';
-type TransferType = 'upload' | 'download';
+type TransferType = 'upload' | 'download' | 'storage-class' | 'restore' | 'rename' | 'copy';
 type TransferView = 'all' | TransferType;
 
 type TransferItem = {
@@ -526,6 +527,25 @@ function App() {
     return () => off();
   }, [activeTransferProfile, sessionConfig, toTransferItem]);
 
+  useEffect(() => {
+    const off = EventsOn('restore:update', (payload: any) => {
+      if (!sessionConfig) return;
+      const update = payload as { profileName?: string; key: string; state: string; expiry?: string; downloadTransferId?: string; message?: string };
+      if (normalizeTransferProfileName(update?.profileName) !== activeTransferProfile) return;
+      const name = (update?.key || '').split('/').filter(Boolean).pop() || update?.key || '';
+      if (update?.state !== 'restored') {
+        showToast('error', `Restore of ${name} did not finish${update?.message ? `: ${update.message}` : ''}`, 5000);
+      } else if (update.message) {
+        showToast('error', `${name} is restored, but ${update.message}`, 5000);
+      } else if (update.downloadTransferId) {
+        showToast('success', `${name} is restored; its download has started`, 4000);
+      } else {
+        showToast('success', `${name} is restored${update.expiry ? ` until ${update.expiry}` : ''}`, 4000);
+      }
+    });
+    return () => off();
+  }, [activeTransferProfile, sessionConfig, showToast]);
+
   useEffect(() => {
     const off = EventsOn('app:about', () => {
       openAbout();
@@ -744,15 +764,26 @@ function App() {
       return;
     }
 
+    if (isCrossProfileDrag(payload, sessionProfileName)) {
+      try {
+        const source = await GetProfile(payload.source?.profileName || '');
+        const paths = copySourcePaths(payload);
+        await EnqueueCrossProfileCopy(source.config, paths, sessionConfig, destBucket, destPrefix);
+        showToast('info', `Copying ${payload.items.length} item(s) from ${source.name}`);
+      } catch (err: any) {
+        showToast('error', err?.message || 'Copy failed');
+      }
+      return;
+    }
+
     try {
       for (const item of payload.items) {
         const parsed = parseOssObjectPath(item.path);
         if (!parsed?.bucket) continue;
 
         const srcBucket = parsed.bucket;
-        const srcKey = parsed.key;
-        const cleanName = (item.name || '').replace(/\/+$/, '');
-        const destKey = `${destPrefix || ''}${cleanName}${item.isFolder ? '/' : ''}`;
+        const srcKey = objectKeyOf(item, srcBucket);
+        const destKey = childObjectKey(destPrefix, item, item.isFolder);
 
         if (srcBucket === destBucket && srcKey === destKey) continue;
 
@@ -774,13 +805,45 @@ function App() {
         EventsEmit('objects:changed', { bucket: destBucket, prefix: destPrefix });
       }
 
-      showToast('success', payload.items.length > 1 ? `Moved ${payload.items.length} items` : 'Moved 1 item');
+      showToast('success', `${payload.items.length > 1 ? `Moved ${payload.items.length} items` : 'Moved 1 item'} (Ctrl/Cmd+Z to undo)`, 4000);
       openTab(tab.id);
     } catch (err: any) {
       showToast('error', err?.message || 'Move failed');
     }
   };
 
+  const undoLastOperation = async () => {
+    if (!sessionConfig) return;
+    try {
+      // The journal outlives the session, so name the operation and ask before reversing it.
+      const journal = await GetOperationJournal(sessionConfig);
+      const last = (journal || []).find((item) => !item.undoneAtMs);
+      if (!last) {
+        showToast('info', 'There is nothing to undo');
+        return;
+      }
+      if (!last.undoable) {
+        showToast('error', `Cannot undo ${last.description}: ${last.reason || 'not reversible'}`, 5000);
+        return;
+      }
+      if (!window.confirm(`Undo ${last.description}?`)) return;
+      const entry = await UndoLastOperation(sessionConfig, last.id);
+      const parentPrefix = (key: string) => {
+        const trimmed = (key || '').replace(/\/+$/, '');
+        const index = trimmed.lastIndexOf('/');
+        return index >= 0 ? trimmed.slice(0, index + 1) : '';
+      };
+      const locations = [{ bucket: entry.bucket, prefix: parentPrefix(entry.key) }];
+      if (entry.destBucket) {
+        locations.push({ bucket: entry.destBucket, prefix: parentPrefix(entry.destKey || entry.key) });
+      }
+      EventsEmit('objects:changed', ...locations);
+      showToast('success', `Undone: ${entry.description}`);
+    } catch (err: any) {
+      showToast('error', err?.message || 'Undo failed', 5000);
+    }
+  };
+
   const addTab = () => {
     if (!sessionConfig) return;
     const number = nextTabNumber.current++;
@@ -872,6 +935,15 @@ function App() {
         addTab();
       }
 
+      // Cmd/Ctrl + Z: undo the last file operation, unless a text field has focus
+      if (key === 'z' && !e.shiftKey) {
+        const target = e.target as HTMLElement | null;
+        if (target && (target.isContentEditable || ['INPUT', 'TEXTAREA', 'SELECT'].includes(target.tagName))) return;
+        e.preventDefault();
+        void undoLastOperation();
+        return;
+      }
+
       // Cmd/Ctrl + W: close active tab
       if (key === 'w') {
         e.preventDefault();
@@ -885,7 +957,7 @@ function App() {
     return () => {
       window.removeEventListener('keydown', handleKeyDown);
     };
-  }, [activeTabId, tabs]);
+  }, [activeTabId, tabs, sessionConfig]);
 
   const inProgressCount = transferSummary.upload.taskCount + transferSummary.download.taskCount;
   const transferSummaryIdle = transferSummary.upload.taskCount <= 0 && transferSummary.download.taskCount <= 0;
@@ -1079,6 +1151,9 @@ function App() {
           onClose={() => setShowTransfers(false)}
           onReveal={(p) => OpenInFinder(p)}
           onOpen={(p) => OpenFile(p)}
+          onResume={(id) => {
+            ResumeCopyTransfer(id).catch((err: any) => showToast('error', err?.message || 'Resume failed'));
+          }}
         />
 	        {toast && !showTransfers && (
 	          <div className={`toast toast-${toast.type}`} role="status">
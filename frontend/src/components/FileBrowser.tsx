@@ -1,11 +1,12 @@
 import { useCallback, useEffect, useLayoutEffect, useMemo, useRef, useState } from 'react';
 import { main } from '../../wailsjs/go/models';
-import { CreateFile, CreateFolder, DeleteObject, EnqueueDownload, EnqueueDownloadFolder, ListBuckets, ListObjectsPage, MoveObject, PresignObject } from '../../wailsjs/go/main/OSSService';
+import { childObjectKey, objectKeyOf } from '../objectKeys';
+import { BuildFolderIndex, CreateFile, CreateFolder, DeleteObject, EnqueueCrossProfileCopy, EnqueueDownload, EnqueueDownloadAfterRestore, EnqueueDownloadFolder, GetFolderStats, GetProfile, GetThumbnails, GetWebDAVStatus, IsOfflineMode, ListBuckets, ListIndexedObjectsPage, LoadProfiles, ListObjectKeyVersions, ListObjectsPage, ListObjectsPageCached, ListObjectVersionsPage, MoveObject, RestoreObjects, RestoreObjectVersion, SetOfflineMode, StartWebDAVServer, StopWebDAVServer } from '../../wailsjs/go/main/OSSService';
 import { SelectDirectory, SelectFile, SelectSaveFile } from '../../wailsjs/go/main/App';
 import ConfirmationModal from './ConfirmationModal';
 import FilePreviewModal from './FilePreviewModal';
 import { EventsEmit, EventsOn } from '../../wailsjs/runtime/runtime';
-import { canReadOssDragPayload, OssDragPayload, readOssDragPayload, writeOssDragPayload } from '../ossDrag';
+import { canReadOssDragPayload, copySourcePaths, isCrossProfileDrag, OssDragPayload, readOssDragPayload, writeOssDragPayload } from '../ossDrag';
 import { enqueueUploadWithRenamePrompt } from '../upload';
 import './FileBrowser.css';
 import './Modal.css';
@@ -34,6 +35,12 @@ const DETAILS_PANE_RESIZER_WIDTH = 10;
 const TABLE_PANE_MIN_WIDTH = 420;
 const DETAILS_PANE_WIDTH_STORAGE_KEY = 'walioss:filebrowser:detailsPaneWidth';
 
+// Thumbnails are rendered at THUMBNAIL_SIZE pixels, enough for the details pane, and
+// requested in batches of visible rows.
+const THUMBNAIL_SIZE = 320;
+const THUMBNAIL_BATCH_SIZE = 60;
+const THUMBNAIL_BATCH_DELAY_MS = 40;
+
 const IMAGE_THUMB_EXTENSIONS = new Set(['png', 'jpg', 'jpeg', 'gif', 'webp', 'bmp', 'svg', 'ico', 'tif', 'tiff']);
 
 const getFileExtensionLower = (name: string) => {
@@ -139,6 +146,22 @@ interface ContextMenuState {
   object: main.ObjectInfo | null;
 }
 
+// Listings come back from OSS in name order; any other order is served from a folder index.
+type ListSort = {
+  by: 'name' | 'size' | 'modified';
+  descending: boolean;
+};
+
+type VersionsPanelState = {
+  path: string;
+  items: main.ObjectVersionInfo[];
+  nextKeyMarker: string;
+  nextVersionIdMarker: string;
+  hasMore: boolean;
+  loading: boolean;
+  error: string | null;
+};
+
 type CrumbPopoverState = {
   bucket: string;
   prefix: string;
@@ -183,6 +206,18 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
   
   const [buckets, setBuckets] = useState<main.BucketInfo[]>([]);
   const [objects, setObjects] = useState<main.ObjectInfo[]>([]);
+  // folderStats holds running and finished size calculations by folder path.
+  const [folderStats, setFolderStats] = useState<Map<string, main.FolderStats>>(() => new Map());
+  const folderStatsTasksRef = useRef<Map<string, string>>(new Map());
+  const [restoreTargets, setRestoreTargets] = useState<main.ObjectInfo[] | null>(null);
+  const [restoreDays, setRestoreDays] = useState('1');
+  const [restoreTier, setRestoreTier] = useState('Standard');
+  const [versionsPanel, setVersionsPanel] = useState<VersionsPanelState | null>(null);
+  const [restoringVersionId, setRestoringVersionId] = useState<string | null>(null);
+  const [listSort, setListSort] = useState<ListSort>({ by: 'name', descending: false });
+  const [indexStatus, setIndexStatus] = useState<main.FolderIndexStatus | null>(null);
+  const [listingSource, setListingSource] = useState<{ cached: boolean; offline: boolean; fetchedAtMs: number } | null>(null);
+  const [offlineMode, setOfflineMode] = useState(false);
   const [bookmarks, setBookmarks] = useState<Bookmark[]>([]);
   const [selectedPaths, setSelectedPaths] = useState<Set<string>>(() => new Set());
   const [activePath, setActivePath] = useState<string | null>(null);
@@ -216,8 +251,11 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
     containerWidth: 0,
   });
 
-  const thumbUrlCacheRef = useRef<Map<string, string>>(new Map());
+  // Thumbnails are cached per path together with the ETag they were rendered from.
+  const thumbUrlCacheRef = useRef<Map<string, { etag: string; url: string }>>(new Map());
   const thumbLoadingRef = useRef<Set<string>>(new Set());
+  const thumbQueueRef = useRef<Map<string, main.ObjectInfo>>(new Map());
+  const thumbFlushTimerRef = useRef<number | null>(null);
   const thumbObserverRef = useRef<IntersectionObserver | null>(null);
   const thumbObjectByPathRef = useRef<Map<string, main.ObjectInfo>>(new Map());
   const [_thumbTick, setThumbTick] = useState(0);
@@ -228,6 +266,7 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
   useEffect(() => {
     thumbUrlCacheRef.current.clear();
     thumbLoadingRef.current.clear();
+    thumbQueueRef.current.clear();
     thumbObjectByPathRef.current.clear();
     thumbObserverRef.current?.disconnect();
     thumbObserverRef.current = null;
@@ -571,7 +610,7 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
     if (!currentBucket) return;
     loadObjectsFirstPage(currentBucket, currentPrefix);
     // eslint-disable-next-line react-hooks/exhaustive-deps
-  }, [pageSize]);
+  }, [pageSize, listSort]);
 
   useEffect(() => {
     if (!createFolderModalOpen) return;
@@ -659,6 +698,93 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
     return () => off();
   }, [config, currentBucket, currentPrefix, pageIndex, pageMarkers, pageSize]);
 
+  useEffect(() => {
+    if (!currentBucket) return;
+    const off = EventsOn('listing:update', (payload: any) => {
+      if (isIndexedSort) return;
+      const update = payload as main.CachedObjectListPage;
+      if (normalizeBucketName(update?.bucket || '') !== currentBucket) return;
+      if (normalizePrefix(update?.prefix || '') !== normalizePrefix(currentPrefix)) return;
+      if ((update?.marker || '') !== markerForPage(pageIndex)) return;
+      const result = update.page;
+      setObjects(result?.items || []);
+      setListingSource({ cached: false, offline: false, fetchedAtMs: update.fetchedAtMs || Date.now() });
+      const hasNext = !!result?.isTruncated && !!result?.nextMarker;
+      setPageHasNext(hasNext);
+      setPageMarkers((prev) => {
+        const next = [...prev];
+        if (hasNext) {
+          next[pageIndex] = result.nextMarker;
+        } else {
+          next.length = Math.min(next.length, pageIndex);
+        }
+        return next;
+      });
+      setKnownLastPage(hasNext ? null : pageIndex);
+    });
+    return () => off();
+  }, [config, currentBucket, currentPrefix, pageIndex, pageMarkers, pageSize, listSort]);
+
+  useEffect(() => {
+    const off = EventsOn('folder-stats:update', (payload: any) => {
+      const stats = payload as main.FolderStats;
+      const path = stats?.taskId ? folderStatsTasksRef.current.get(stats.taskId) : undefined;
+      if (!path) return;
+      if (stats.done) {
+        folderStatsTasksRef.current.delete(stats.taskId!);
+        if (stats.error) {
+          onNotify?.({ type: 'error', message: `Folder size failed: ${stats.error}` });
+        }
+      }
+      applyFolderStats(path, stats);
+    });
+    return () => off();
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, []);
+
+  useEffect(() => {
+    if (!currentBucket || !isIndexedSort) return;
+    const off = EventsOn('folder-index:update', (payload: any) => {
+      const status = payload as main.FolderIndexStatus;
+      if (normalizeBucketName(status?.bucket || '') !== currentBucket) return;
+      if (normalizePrefix(status?.prefix || '') !== normalizePrefix(currentPrefix)) return;
+      setIndexStatus(status);
+      loadIndexedPage(currentBucket, currentPrefix, pageIndex, { silent: true });
+    });
+    return () => off();
+  }, [config, currentBucket, currentPrefix, pageIndex, pageSize, listSort]);
+
+  useEffect(() => {
+    // App shows the outcome; the browser only refreshes the folder the object is in.
+    const off = EventsOn('restore:update', (payload: any) => {
+      const update = payload as { bucket: string; key: string };
+      if (!currentBucket || normalizeBucketName(update?.bucket || '') !== currentBucket) return;
+      const key = update?.key || '';
+      const parent = key.includes('/') ? key.slice(0, key.lastIndexOf('/') + 1) : '';
+      if (parent === normalizePrefix(currentPrefix)) handleRefresh();
+    });
+    return () => off();
+  }, [config, currentBucket, currentPrefix, pageIndex, pageMarkers, pageSize, listSort]);
+
+  useEffect(() => {
+    IsOfflineMode()
+      .then((enabled) => setOfflineMode(!!enabled))
+      .catch(() => {});
+  }, []);
+
+  const toggleOfflineMode = async () => {
+    const next = !offlineMode;
+    try {
+      await SetOfflineMode(next);
+      setOfflineMode(next);
+      if (currentBucket) {
+        loadObjectsFirstPage(currentBucket, currentPrefix);
+      }
+    } catch (err: any) {
+      setError(err?.message || 'Failed to switch offline mode');
+    }
+  };
+
   // Close menus on click elsewhere
   useEffect(() => {
     const handleClick = () => {
@@ -697,13 +823,81 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
     return pageMarkers[page - 1] ?? '';
   };
 
+  const isIndexedSort = listSort.by !== 'name' || listSort.descending;
+
+  // loadIndexedPage shows a page of the folder index, building the index on first use.
+  // silent reloads, used while the index fills, keep the current rows on screen.
+  const loadIndexedPage = async (bucket: string, prefix: string, targetPage: number, opts?: { silent?: boolean; rebuild?: boolean }) => {
+    if (!opts?.silent) {
+      setLoading(true);
+      setError(null);
+    }
+    try {
+      const options = {
+        sortBy: listSort.by,
+        descending: listSort.descending,
+        filter: '',
+        type: '',
+        storageClass: '',
+        offset: (targetPage - 1) * pageSize,
+        limit: pageSize,
+      };
+      let result: main.IndexedObjectPageResult;
+      if (opts?.rebuild) {
+        setIndexStatus(await BuildFolderIndex(config, bucket, prefix));
+        result = await ListIndexedObjectsPage(config, bucket, prefix, options);
+      } else {
+        try {
+          result = await ListIndexedObjectsPage(config, bucket, prefix, options);
+        } catch {
+          setIndexStatus(await BuildFolderIndex(config, bucket, prefix));
+          result = await ListIndexedObjectsPage(config, bucket, prefix, options);
+        }
+      }
+      if (currentBucketRef.current !== bucket) return;
+      const items = result?.items || [];
+      const total = result?.total || 0;
+      const ready = result?.status?.state === 'ready';
+      setObjects(items);
+      setPageIndex(targetPage);
+      setIndexStatus(result?.status || null);
+      setListingSource(null);
+      setPageHasNext(options.offset + items.length < total);
+      setKnownLastPage(ready ? Math.max(1, Math.ceil(total / pageSize)) : null);
+    } catch (err: any) {
+      if (!opts?.silent) setError(err?.message || 'Failed to sort this folder');
+    } finally {
+      if (!opts?.silent) setLoading(false);
+    }
+  };
+
+  const toggleSort = (by: ListSort['by']) => {
+    setListSort((prev) => {
+      if (prev.by === by) return { by, descending: !prev.descending };
+      // Sizes and dates start with the largest and newest.
+      return { by, descending: by !== 'name' };
+    });
+  };
+
+  const sortIndicator = (by: ListSort['by']) => {
+    if (listSort.by !== by) return '';
+    return listSort.descending ? ' ▼' : ' ▲';
+  };
+
   const loadObjectsPage = async (bucket: string, prefix: string, marker: string, targetPage: number) => {
+    if (isIndexedSort) {
+      await loadIndexedPage(bucket, prefix, targetPage);
+      return;
+    }
+    setIndexStatus(null);
     setLoading(true);
     setError(null);
     try {
-      const result = await ListObjectsPage(config, bucket, prefix, marker, pageSize);
+      const cached = await ListObjectsPageCached(config, bucket, prefix, marker, pageSize);
+      const result = cached.page;
       setObjects(result?.items || []);
       setPageIndex(targetPage);
+      setListingSource({ cached: !!cached.cached, offline: !!cached.offline, fetchedAtMs: cached.fetchedAtMs || 0 });
 
       const hasNext = !!result?.isTruncated && !!result?.nextMarker;
       setPageHasNext(hasNext);
@@ -748,6 +942,11 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
     const bucket = currentBucket;
     const prefix = currentPrefix;
 
+    if (isIndexedSort) {
+      await loadIndexedPage(bucket, prefix, targetPage);
+      return;
+    }
+
     setLoading(true);
     setError(null);
     try {
@@ -757,7 +956,7 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
       while (markers.length < targetPage) {
         const currentPage = markers.length;
         const pageMarker = markers[currentPage - 1] ?? '';
-        const res = await ListObjectsPage(config, bucket, prefix, pageMarker, pageSize);
+        const res = (await ListObjectsPageCached(config, bucket, prefix, pageMarker, pageSize)).page;
         const hasMore = !!res?.isTruncated && !!res?.nextMarker;
         if (!hasMore) {
           lastPage = currentPage;
@@ -774,9 +973,11 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
       }
 
       const targetMarker = markers[targetPage - 1] ?? '';
-      const result = await ListObjectsPage(config, bucket, prefix, targetMarker, pageSize);
+      const cached = await ListObjectsPageCached(config, bucket, prefix, targetMarker, pageSize);
+      const result = cached.page;
       setObjects(result?.items || []);
       setPageIndex(targetPage);
+      setListingSource({ cached: !!cached.cached, offline: !!cached.offline, fetchedAtMs: cached.fetchedAtMs || 0 });
 
       const hasNext = !!result?.isTruncated && !!result?.nextMarker;
       setPageHasNext(hasNext);
@@ -873,11 +1074,17 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
     navigateTo(currentBucket, newPrefix);
   };
 
-  const handleRefresh = () => {
+  // handleRefresh reloads the current page. The app's own changes are patched into a folder
+  // index, so only an explicit refresh rescans it.
+  const handleRefresh = (opts?: { rebuildIndex?: boolean }) => {
     if (!currentBucket) {
       loadBuckets();
       return;
     }
+    if (isIndexedSort) {
+      loadIndexedPage(currentBucket, currentPrefix, pageIndex, { rebuild: !!opts?.rebuildIndex });
+      return;
+    }
     const marker = markerForPage(pageIndex);
     loadObjectsPage(currentBucket, currentPrefix, marker, pageIndex);
   };
@@ -893,6 +1100,10 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
     if (!currentBucket) return;
     if (!pageHasNext) return;
     const target = pageIndex + 1;
+    if (isIndexedSort) {
+      loadIndexedPage(currentBucket, currentPrefix, target);
+      return;
+    }
     const marker = markerForPage(target);
     if (!marker) return;
     loadObjectsPage(currentBucket, currentPrefix, marker, target);
@@ -1207,15 +1418,28 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
     if (!destBucket) return;
 
     const normalizedDestPrefix = normalizePrefix(destPrefix);
+    if (isCrossProfileDrag(payload, profileName)) {
+      try {
+        const source = await GetProfile(payload.source?.profileName || '');
+        const paths = copySourcePaths(payload);
+        await EnqueueCrossProfileCopy(source.config, paths, config, destBucket, normalizedDestPrefix);
+        onNotify?.({ type: 'info', message: `Copying ${payload.items.length} item(s) from ${source.name}` });
+      } catch (err: any) {
+        alert('Copy failed: ' + (err?.message || String(err)));
+      } finally {
+        setDropTargetPath(null);
+      }
+      return;
+    }
+
     setOperationLoading(true);
     try {
       for (const item of payload.items) {
         const parsed = parseObjectPath(item.path);
         if (!parsed?.bucket) continue;
         const srcBucket = parsed.bucket;
-        const srcKey = parsed.key;
-        const cleanName = objectNameForKey(item.name);
-        const destKey = `${normalizedDestPrefix || ''}${cleanName}${item.isFolder ? '/' : ''}`;
+        const srcKey = objectKeyOf(item, srcBucket);
+        const destKey = childObjectKey(normalizedDestPrefix, item, item.isFolder);
 
         if (srcBucket === destBucket && srcKey === destKey) continue;
 
@@ -1260,6 +1484,7 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
       .filter((o) => !!o.path)
       .map((o) => ({
         path: o.path as string,
+        rawKey: o.rawKey || undefined,
         name: objectNameForKey(o.name),
         isFolder: isFolder(o),
       }));
@@ -1272,7 +1497,7 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
     e.dataTransfer.effectAllowed = 'move';
     writeOssDragPayload(e.dataTransfer, {
       type: 'walioss-oss-objects',
-      source: { bucket: currentBucket, prefix: currentPrefix },
+      source: { bucket: currentBucket, prefix: currentPrefix, profileName },
       items,
     });
   };
@@ -1476,6 +1701,56 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
     setMoveModalOpen(true);
   };
 
+  const [copyProfileModalOpen, setCopyProfileModalOpen] = useState(false);
+  const [copyProfileTargets, setCopyProfileTargets] = useState<main.ObjectInfo[]>([]);
+  const [copyProfiles, setCopyProfiles] = useState<main.OSSProfile[]>([]);
+  const [copyProfileName, setCopyProfileName] = useState('');
+  const [copyProfileDest, setCopyProfileDest] = useState('');
+
+  // Copying to another profile streams the objects through the app with both profiles'
+  // credentials, so it works between accounts that cannot copy server-side.
+  const requestCopyToProfile = async (targets: main.ObjectInfo[]) => {
+    if (!targets.length) return;
+    try {
+      const profiles = (await LoadProfiles()).filter((p) => p.name !== profileName);
+      if (!profiles.length) {
+        onNotify?.({ type: 'info', message: 'Save another profile first to copy into it.' });
+        return;
+      }
+      setCopyProfiles(profiles);
+      setCopyProfileName(profiles[0].name);
+      setCopyProfileDest('oss://');
+      setCopyProfileTargets(targets);
+      setCopyProfileModalOpen(true);
+    } catch (err: any) {
+      onNotify?.({ type: 'error', message: err?.message || 'Failed to load profiles' });
+    }
+  };
+
+  const confirmCopyToProfile = async () => {
+    const target = copyProfiles.find((p) => p.name === copyProfileName);
+    const dest = copyProfileDest.trim().startsWith('oss://') ? parseMoveDestination(copyProfileDest) : null;
+    if (!target || !dest?.bucket || !currentBucket) {
+      alert('Enter the destination as oss://bucket/path/');
+      return;
+    }
+    const paths = copyProfileTargets.map((obj) => {
+      if (obj.rawKey) return `oss://${currentBucket}/${obj.rawKey}`;
+      return isFolder(obj) && !obj.path.endsWith('/') ? `${obj.path}/` : obj.path;
+    });
+    setOperationLoading(true);
+    try {
+      await EnqueueCrossProfileCopy(config, paths, target.config, dest.bucket, dest.prefix);
+      setCopyProfileModalOpen(false);
+      setCopyProfileTargets([]);
+      onNotify?.({ type: 'info', message: `Copying ${paths.length} item(s) to ${target.name}` });
+    } catch (err: any) {
+      alert('Copy failed: ' + (err?.message || String(err)));
+    } finally {
+      setOperationLoading(false);
+    }
+  };
+
   const confirmMoveTo = async () => {
     if (!moveTargets.length) return;
     const dest = parseMoveDestination(moveDestValue);
@@ -1490,9 +1765,9 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
         const parsed = parseObjectPath(obj.path);
         if (!parsed?.bucket) continue;
         const srcBucket = parsed.bucket;
-        const srcKey = parsed.key;
-        const folder = isFolder(obj) || srcKey.endsWith('/');
-        const destKey = `${dest.prefix || ''}${objectNameForKey(obj.name)}${folder ? '/' : ''}`;
+        const srcKey = objectKeyOf(obj, srcBucket);
+        const folder = isFolder(obj) || parsed.key.endsWith('/');
+        const destKey = childObjectKey(dest.prefix, obj, folder);
         await MoveObject(config, srcBucket, srcKey, dest.bucket, destKey);
       }
 
@@ -1512,24 +1787,81 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
   const handleDownload = async (target?: main.ObjectInfo) => {
     const obj = target || contextMenu.object;
     if (!obj || !currentBucket) return;
-    const parsed = parseObjectPath(obj.path);
-    if (!parsed?.key) return;
+    const key = objectKeyOf(obj, currentBucket);
+    if (!key) return;
 
     try {
       if (isFolder(obj)) {
         const dirPath = await SelectDirectory(`Download "${obj.name}" To`);
         if (!dirPath) return;
-        await EnqueueDownloadFolder(config, currentBucket, parsed.key, dirPath);
+        await EnqueueDownloadFolder(config, currentBucket, key, dirPath);
       } else {
         const savePath = await SelectSaveFile(obj.name);
         if (!savePath) return;
-        await EnqueueDownload(config, currentBucket, parsed.key, savePath, obj.size);
+        await EnqueueDownload(config, currentBucket, key, savePath, obj.size);
       }
     } catch (err: any) {
       alert("Download failed: " + err.message);
     }
   };
 
+  const restoreBadge = (obj: main.ObjectInfo) => {
+    switch (obj.restoreState) {
+      case 'archived':
+        return { label: 'Archived', title: `${obj.storageClass}: restore it before downloading` };
+      case 'ongoing':
+        return { label: 'Restoring', title: 'Restore in progress' };
+      case 'restored':
+        return { label: 'Restored', title: obj.restoreExpiry ? `Readable until ${obj.restoreExpiry}` : 'Readable' };
+      default:
+        return null;
+    }
+  };
+
+  const openRestoreModal = (targets: main.ObjectInfo[]) => {
+    setContextMenu((prev) => (prev.visible ? { ...prev, visible: false } : prev));
+    if (!targets.length) return;
+    setRestoreTargets(targets);
+  };
+
+  const confirmRestore = async () => {
+    if (!restoreTargets || !currentBucket) return;
+    const days = parseInt(restoreDays, 10);
+    const keys = restoreTargets.map((obj) => objectKeyOf(obj, currentBucket)).filter(Boolean);
+    setOperationLoading(true);
+    try {
+      await RestoreObjects(config, currentBucket, keys, Number.isFinite(days) ? days : 1, restoreTier);
+      onNotify?.({ type: 'info', message: `Restore requested for ${keys.length} item(s). You will be notified when it finishes.` });
+      setRestoreTargets(null);
+      handleRefresh();
+    } catch (err: any) {
+      onNotify?.({ type: 'error', message: err?.message || 'Restore failed' });
+    } finally {
+      setOperationLoading(false);
+    }
+  };
+
+  // handleDownloadWhenRestored queues a download that starts once the object is readable.
+  const handleDownloadWhenRestored = async (target?: main.ObjectInfo) => {
+    const obj = target || contextMenu.object;
+    setContextMenu((prev) => (prev.visible ? { ...prev, visible: false } : prev));
+    if (!obj || !currentBucket || isFolder(obj)) return;
+    const key = objectKeyOf(obj, currentBucket);
+    if (!key) return;
+    try {
+      const savePath = await SelectSaveFile(obj.name);
+      if (!savePath) return;
+      const transferId = await EnqueueDownloadAfterRestore(config, currentBucket, key, savePath);
+      onNotify?.(
+        transferId
+          ? { type: 'success', message: 'Download task created successfully' }
+          : { type: 'info', message: `${obj.name} downloads when its restore finishes. Keep Walioss open until then.` },
+      );
+    } catch (err: any) {
+      onNotify?.({ type: 'error', message: err?.message || 'Download failed' });
+    }
+  };
+
   const requestDelete = (targets: main.ObjectInfo[]) => {
     if (!targets.length) return;
     setDeleteTargets(targets);
@@ -1551,7 +1883,7 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
       for (const obj of deleteTargets) {
         const parsed = parseObjectPath(obj.path);
         if (!parsed?.bucket) continue;
-        await DeleteObject(config, parsed.bucket, parsed.key);
+        await DeleteObject(config, parsed.bucket, objectKeyOf(obj, parsed.bucket));
       }
       setDeleteModalOpen(false);
       setDeleteTargets([]);
@@ -1572,6 +1904,74 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
     setContextMenu({ ...contextMenu, visible: false });
   };
 
+  const [webdavStatus, setWebdavStatus] = useState<main.WebDAVStatus | null>(null);
+
+  useEffect(() => {
+    GetWebDAVStatus().then(setWebdavStatus).catch(() => setWebdavStatus(null));
+  }, []);
+
+  const [webdavModalOpen, setWebdavModalOpen] = useState(false);
+  const [webdavUsername, setWebdavUsername] = useState('');
+  const [webdavPassword, setWebdavPassword] = useState('');
+  const [webdavPort, setWebdavPort] = useState('');
+  const [webdavBusy, setWebdavBusy] = useState(false);
+
+  const openWebDAVModal = async () => {
+    try {
+      setWebdavStatus(await GetWebDAVStatus());
+    } catch {
+      setWebdavStatus(null);
+    }
+    setWebdavModalOpen(true);
+  };
+
+  const handleStartWebDAV = async () => {
+    if (!currentBucket) return;
+    const port = webdavPort.trim() ? parseInt(webdavPort.trim(), 10) : 0;
+    if (!Number.isFinite(port) || port < 0 || port > 65535) {
+      onNotify?.({ type: 'error', message: 'Port must be a number between 0 and 65535' });
+      return;
+    }
+    setWebdavBusy(true);
+    try {
+      const started = await StartWebDAVServer(config, {
+        bucket: currentBucket,
+        prefix: currentPrefix,
+        port,
+        username: webdavUsername.trim(),
+        password: webdavPassword,
+      });
+      setWebdavStatus(started);
+      setWebdavPassword('');
+    } catch (err: any) {
+      onNotify?.({ type: 'error', message: err?.message || 'WebDAV server failed' });
+    } finally {
+      setWebdavBusy(false);
+    }
+  };
+
+  const handleStopWebDAV = async () => {
+    setWebdavBusy(true);
+    try {
+      await StopWebDAVServer();
+      setWebdavStatus(null);
+      onNotify?.({ type: 'info', message: 'WebDAV server stopped' });
+    } catch (err: any) {
+      onNotify?.({ type: 'error', message: err?.message || 'Failed to stop the WebDAV server' });
+    } finally {
+      setWebdavBusy(false);
+    }
+  };
+
+  const copyWebDAVValue = async (label: string, value: string | undefined) => {
+    try {
+      await navigator.clipboard.writeText(value || '');
+      onNotify?.({ type: 'success', message: `${label} copied to clipboard` });
+    } catch {
+      onNotify?.({ type: 'error', message: `Failed to copy ${label.toLowerCase()}` });
+    }
+  };
+
   const handleCopyObjectPath = async (obj: main.ObjectInfo | null) => {
     if (!obj?.path) return;
     try {
@@ -1582,6 +1982,47 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
     }
   };
 
+  const applyFolderStats = (path: string, stats: main.FolderStats) => {
+    setFolderStats((prev) => {
+      const next = new Map(prev);
+      next.set(path, stats);
+      return next;
+    });
+    if (!stats.done || stats.cancelled || stats.error) return;
+    setObjects((prev) =>
+      prev.map((obj) =>
+        obj.path === path
+          ? { ...obj, size: stats.totalBytes, objectCount: stats.objectCount, sizeComputedAtMs: stats.computedAtMs || Date.now() }
+          : obj,
+      ),
+    );
+  };
+
+  // handleCalculateFolderSize sums everything below a folder; the result also fills the
+  // folder's size in later listings.
+  const handleCalculateFolderSize = async (target?: main.ObjectInfo) => {
+    const obj = target || contextMenu.object;
+    setContextMenu((prev) => (prev.visible ? { ...prev, visible: false } : prev));
+    if (!obj?.path || !isFolder(obj) || !currentBucket) return;
+    const path = obj.path;
+    try {
+      const stats = await GetFolderStats(config, currentBucket, objectKeyOf(obj, currentBucket));
+      if (stats.taskId && !stats.done) {
+        folderStatsTasksRef.current.set(stats.taskId, path);
+      }
+      applyFolderStats(path, stats);
+    } catch (err: any) {
+      onNotify?.({ type: 'error', message: err?.message || 'Failed to calculate folder size' });
+    }
+  };
+
+  const formatFolderSize = (obj: main.ObjectInfo) => {
+    const stats = obj.path ? folderStats.get(obj.path) : undefined;
+    if (stats && !stats.done) return `${formatSize(stats.totalBytes)}…`;
+    if (obj.sizeComputedAtMs) return formatSize(obj.size);
+    return '-';
+  };
+
   const handleOpenFolder = () => {
     const obj = contextMenu.object;
     if (!obj || !isFolder(obj)) return;
@@ -1701,6 +2142,43 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
     handlePreview(obj);
   };
 
+  const thumbUrlFor = (obj: main.ObjectInfo | null | undefined) => {
+    if (!obj?.path) return '';
+    const cached = thumbUrlCacheRef.current.get(obj.path);
+    return cached && cached.etag === (obj.etag || '') ? cached.url : '';
+  };
+
+  // flushThumbQueue renders the queued thumbnails in one GetThumbnails call, so a screenful
+  // of images costs one request per image on OSS and none once they are cached locally.
+  const flushThumbQueue = useCallback(async () => {
+    thumbFlushTimerRef.current = null;
+    const bucket = currentBucket;
+    const queued = Array.from(thumbQueueRef.current.values()).slice(0, THUMBNAIL_BATCH_SIZE);
+    for (const obj of queued) thumbQueueRef.current.delete(obj.path);
+    if (thumbQueueRef.current.size > 0) {
+      thumbFlushTimerRef.current = window.setTimeout(() => void flushThumbQueue(), 0);
+    }
+    if (!bucket || queued.length === 0) return;
+
+    try {
+      const requests = queued.map((obj) => ({ key: objectKeyOf(obj, bucket), etag: obj.etag || '' }));
+      const results = (await GetThumbnails(config, bucket, requests, THUMBNAIL_SIZE)) || [];
+      if (currentBucketRef.current !== bucket) return;
+      let changed = false;
+      results.forEach((result, i) => {
+        const obj = queued[i];
+        if (!obj || !result?.dataUrl) return;
+        thumbUrlCacheRef.current.set(obj.path, { etag: obj.etag || '', url: result.dataUrl });
+        changed = true;
+      });
+      if (changed) setThumbTick((t) => t + 1);
+    } catch {
+      // Ignore thumbnail failures and fall back to generic icons.
+    } finally {
+      for (const obj of queued) thumbLoadingRef.current.delete(obj.path);
+    }
+  }, [config, currentBucket]);
+
   const ensureThumbUrl = useCallback(
     async (obj: main.ObjectInfo) => {
       if (!obj?.path) return;
@@ -1708,26 +2186,16 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
       if (!isImageObjectInfo(obj)) return;
 
       const cacheKey = obj.path;
-      if (thumbUrlCacheRef.current.has(cacheKey)) return;
+      if (thumbUrlCacheRef.current.get(cacheKey)?.etag === (obj.etag || '')) return;
       if (thumbLoadingRef.current.has(cacheKey)) return;
+      if (!objectKeyOf(obj, currentBucket)) return;
       thumbLoadingRef.current.add(cacheKey);
-
-      try {
-        const ossPrefix = `oss://${currentBucket}/`;
-        const key = obj.path.startsWith(ossPrefix) ? obj.path.slice(ossPrefix.length) : '';
-        if (!key) return;
-
-        const url = await PresignObject(config, currentBucket, key, '30m');
-        if (!url) return;
-        thumbUrlCacheRef.current.set(cacheKey, url);
-        setThumbTick((t) => t + 1);
-      } catch {
-        // Ignore thumbnail failures and fall back to generic icons.
-      } finally {
-        thumbLoadingRef.current.delete(cacheKey);
+      thumbQueueRef.current.set(cacheKey, obj);
+      if (thumbFlushTimerRef.current === null) {
+        thumbFlushTimerRef.current = window.setTimeout(() => void flushThumbQueue(), THUMBNAIL_BATCH_DELAY_MS);
       }
     },
-    [config, currentBucket],
+    [currentBucket, flushThumbQueue],
   );
 
   useEffect(() => {
@@ -2009,7 +2477,7 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
     }, [activePath, objects, selectedPaths]);
     const focusedThumbUrl =
       focusedObject && focusedObject.path && isImageObjectInfo(focusedObject)
-        ? thumbUrlCacheRef.current.get(focusedObject.path) || ''
+        ? thumbUrlFor(focusedObject)
         : '';
 	  const previewableFiles = objects.filter((obj) => !isFolder(obj));
 	  const previewIndex =
@@ -2022,6 +2490,74 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
       void ensureThumbUrl(focusedObject);
     }, [focusedObject, ensureThumbUrl, isFinderView]);
 
+    useEffect(() => {
+      setVersionsPanel((prev) => (prev && prev.path !== focusedObject?.path ? null : prev));
+    }, [focusedObject?.path]);
+
+    // loadVersions lists the history of a file, or one page of the versions below a folder.
+    const loadVersions = async (obj: main.ObjectInfo, append: boolean) => {
+      if (!currentBucket || !obj.path) return;
+      const path = obj.path;
+      const key = objectKeyOf(obj, currentBucket);
+      const previous = append && versionsPanel?.path === path ? versionsPanel : null;
+      setVersionsPanel({
+        path,
+        items: previous?.items || [],
+        nextKeyMarker: previous?.nextKeyMarker || '',
+        nextVersionIdMarker: previous?.nextVersionIdMarker || '',
+        hasMore: false,
+        loading: true,
+        error: null,
+      });
+      try {
+        let items: main.ObjectVersionInfo[];
+        let next = { nextKeyMarker: '', nextVersionIdMarker: '', hasMore: false };
+        if (isFolder(obj)) {
+          const page = await ListObjectVersionsPage(
+            config,
+            currentBucket,
+            key,
+            previous?.nextKeyMarker || '',
+            previous?.nextVersionIdMarker || '',
+            100,
+          );
+          items = (page?.items || []).filter((item) => item.type !== 'Folder');
+          next = {
+            nextKeyMarker: page?.nextKeyMarker || '',
+            nextVersionIdMarker: page?.nextVersionIdMarker || '',
+            hasMore: !!page?.isTruncated && !!page?.nextKeyMarker,
+          };
+        } else {
+          items = (await ListObjectKeyVersions(config, currentBucket, key)) || [];
+        }
+        setVersionsPanel((prev) => {
+          if (!prev || prev.path !== path) return prev;
+          return { ...prev, ...next, items: [...(previous?.items || []), ...items], loading: false };
+        });
+      } catch (err: any) {
+        setVersionsPanel((prev) => {
+          if (!prev || prev.path !== path) return prev;
+          return { ...prev, loading: false, error: err?.message || 'Failed to list versions' };
+        });
+      }
+    };
+
+    const handleRestoreVersion = async (obj: main.ObjectInfo, version: main.ObjectVersionInfo) => {
+      if (!currentBucket) return;
+      const key = version.rawKey || version.key;
+      setRestoringVersionId(version.versionId || '');
+      try {
+        await RestoreObjectVersion(config, currentBucket, key, version.versionId || '');
+        onNotify?.({ type: 'success', message: `Restored ${version.name || obj.name} to the version from ${version.lastModified}` });
+        handleRefresh();
+        await loadVersions(obj, false);
+      } catch (err: any) {
+        onNotify?.({ type: 'error', message: err?.message || 'Failed to restore version' });
+      } finally {
+        setRestoringVersionId(null);
+      }
+    };
+
 	  const handlePreviewNavigate = (direction: -1 | 1) => {
 	    if (previewIndex < 0) return;
 	    const target = previewableFiles[previewIndex + direction];
@@ -2098,7 +2634,7 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
                 </div>
                 <div className="details-subtitle">
                   {displayType(focusedObject)}
-                  {!folder ? ` · ${formatSize(focusedObject.size)}` : ''}
+                  {!folder ? ` · ${formatSize(focusedObject.size)}` : formatFolderSize(focusedObject) !== '-' ? ` · ${formatFolderSize(focusedObject)}` : ''}
                 </div>
               </div>
             </div>
@@ -2120,15 +2656,38 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
                   <button className="action-btn" type="button" onClick={() => void handleDownload(focusedObject)}>
                     Download
                   </button>
+                  <button
+                    className="action-btn"
+                    type="button"
+                    onClick={() => void handleCalculateFolderSize(focusedObject)}
+                    disabled={!!focusedObject.path && folderStats.get(focusedObject.path)?.done === false}
+                  >
+                    {focusedObject.path && folderStats.get(focusedObject.path)?.done === false ? 'Calculating…' : 'Calculate Size'}
+                  </button>
                 </>
               ) : (
                 <>
                   <button className="action-btn" type="button" onClick={() => handlePreview(focusedObject)}>
                     Preview
                   </button>
-                  <button className="action-btn" type="button" onClick={() => void handleDownload(focusedObject)}>
-                    Download
-                  </button>
+                  {focusedObject.restoreState === 'archived' || focusedObject.restoreState === 'ongoing' ? (
+                    <>
+                      {focusedObject.restoreState === 'archived' && (
+                        <button className="action-btn" type="button" onClick={() => openRestoreModal([focusedObject])}>
+                          Restore
+                        </button>
+                      )}
+                      {focusedObject.restoreState === 'ongoing' && (
+                        <button className="action-btn" type="button" onClick={() => void handleDownloadWhenRestored(focusedObject)}>
+                          Download When Restored
+                        </button>
+                      )}
+                    </>
+                  ) : (
+                    <button className="action-btn" type="button" onClick={() => void handleDownload(focusedObject)}>
+                      Download
+                    </button>
+                  )}
                 </>
               )}
               <button className="action-btn" type="button" onClick={() => void handleCopyObjectPath(focusedObject)}>
@@ -2141,12 +2700,27 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
                 <span className="details-label">Last Modified</span>
                 <span className="details-value">{focusedObject.lastModified || '-'}</span>
               </div>
+              {folder && focusedObject.sizeComputedAtMs ? (
+                <div className="details-row">
+                  <span className="details-label">Objects</span>
+                  <span className="details-value">{(focusedObject.objectCount || 0).toLocaleString()}</span>
+                </div>
+              ) : null}
               {!folder && (
                 <div className="details-row">
                   <span className="details-label">Storage Class</span>
                   <span className="details-value">{focusedObject.storageClass || '-'}</span>
                 </div>
               )}
+              {restoreBadge(focusedObject) && (
+                <div className="details-row">
+                  <span className="details-label">Restore</span>
+                  <span className="details-value">
+                    <span className={`restore-badge ${focusedObject.restoreState}`}>{restoreBadge(focusedObject)!.label}</span>
+                    {focusedObject.restoreState === 'restored' && focusedObject.restoreExpiry ? ` until ${focusedObject.restoreExpiry}` : ''}
+                  </span>
+                </div>
+              )}
               <div className="details-row details-row-path">
                 <span className="details-label">Path</span>
                 <button
@@ -2160,6 +2734,67 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
               </div>
               {!folder && <div className="details-hint">Tip: Double-click or press Space to preview.</div>}
             </div>
+
+            <div className="details-versions">
+              <div className="details-versions-header">
+                <span className="details-label">Versions</span>
+                <button
+                  className="mini-link"
+                  type="button"
+                  onClick={() => void loadVersions(focusedObject, false)}
+                  disabled={versionsPanel?.path === focusedObject.path && versionsPanel.loading}
+                >
+                  {versionsPanel?.path === focusedObject.path ? 'Reload' : folder ? 'Show versions in folder' : 'Show versions'}
+                </button>
+              </div>
+              {versionsPanel?.path === focusedObject.path && (
+                <div className="details-versions-list">
+                  {versionsPanel.items.map((version) => (
+                    <div
+                      key={`${version.key}\u0000${version.versionId}`}
+                      className={`details-version ${version.isLatest ? 'latest' : ''} ${version.isDeleteMarker ? 'delete-marker' : ''}`}
+                    >
+                      <div className="details-version-info">
+                        {folder && (
+                          <div className="details-version-name" title={version.name}>
+                            {version.name}
+                          </div>
+                        )}
+                        <div className="details-version-meta">
+                          {version.lastModified || '-'}
+                          {version.isDeleteMarker ? ' · Deleted' : ` · ${formatSize(version.size)}`}
+                          {version.isLatest ? ' · Current' : ''}
+                        </div>
+                        <div className="details-version-id" title={version.versionId}>
+                          {version.versionId}
+                        </div>
+                      </div>
+                      {!version.isLatest && !version.isDeleteMarker && (
+                        <button
+                          className="mini-link"
+                          type="button"
+                          onClick={() => void handleRestoreVersion(focusedObject, version)}
+                          disabled={restoringVersionId !== null}
+                          title="Make this version current again; the current version is kept as an older version"
+                        >
+                          {restoringVersionId === version.versionId ? 'Restoring…' : 'Restore'}
+                        </button>
+                      )}
+                    </div>
+                  ))}
+                  {!versionsPanel.loading && !versionsPanel.error && versionsPanel.items.length === 0 && (
+                    <div className="details-hint">No versions</div>
+                  )}
+                  {versionsPanel.error && <div className="details-hint">{versionsPanel.error}</div>}
+                  {versionsPanel.loading && <div className="details-hint">Loading…</div>}
+                  {!versionsPanel.loading && versionsPanel.hasMore && (
+                    <button className="mini-link" type="button" onClick={() => void loadVersions(focusedObject, true)}>
+                      Load more
+                    </button>
+                  )}
+                </div>
+              )}
+            </div>
           </div>
         </div>
       );
@@ -2289,7 +2924,22 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
           <button className="nav-btn" onClick={handleGoBack} disabled={!canGoBack} title="Back">←</button>
           <button className="nav-btn" onClick={handleGoForward} disabled={!canGoForward} title="Forward">→</button>
           <button className="nav-btn" onClick={handleGoUp} disabled={!currentBucket} title="Up">↑</button>
-          <button className="nav-btn" onClick={handleRefresh} disabled={loading} title="Refresh">↻</button>
+          <button className="nav-btn" onClick={() => handleRefresh({ rebuildIndex: true })} disabled={loading} title="Refresh">↻</button>
+          <button
+            className="nav-btn"
+            onClick={() => void openWebDAVModal()}
+            disabled={!webdavStatus?.running && !currentBucket}
+            title={webdavStatus?.running ? `WebDAV server running at ${webdavStatus.url}` : 'Serve this folder over WebDAV on 127.0.0.1'}
+          >
+            {webdavStatus?.running ? '■' : '⇅'}
+          </button>
+          <button
+            className={`nav-btn ${offlineMode ? 'active' : ''}`}
+            onClick={() => void toggleOfflineMode()}
+            title={offlineMode ? 'Offline: browsing cached folders only. Click to go online' : 'Go offline and browse cached folders only'}
+          >
+            {offlineMode ? '⊘' : '☁'}
+          </button>
         </div>
 	        <div className="breadcrumbs" onClick={!addressBarEditing ? handleAddressBarClick : undefined}>
 	          {addressBarEditing ? (
@@ -2372,6 +3022,24 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
 	                </button>
 	              )}
 	            </div>
+	            {indexStatus && isIndexedSort && indexStatus.state !== 'ready' && (
+	              <span className={`listing-source-pill ${indexStatus.state === 'error' ? 'offline' : ''}`} title={indexStatus.error || undefined}>
+	                {indexStatus.state === 'error'
+	                  ? 'Sorting failed'
+	                  : `Indexing… ${(indexStatus.indexedCount || 0).toLocaleString()} items`}
+	              </span>
+	            )}
+	            {indexStatus && isIndexedSort && indexStatus.state === 'ready' && indexStatus.refreshing && (
+	              <span className="listing-source-pill">Refreshing index… {(indexStatus.indexedCount || 0).toLocaleString()} items</span>
+	            )}
+	            {listingSource?.cached && (
+	              <span
+	                className={`listing-source-pill ${listingSource.offline ? 'offline' : ''}`}
+	                title={listingSource.fetchedAtMs ? `Listed ${new Date(listingSource.fetchedAtMs).toLocaleString()}` : undefined}
+	              >
+	                {listingSource.offline ? 'Offline · cached listing' : 'Cached listing'}
+	              </span>
+	            )}
 	          </div>
 	          <div className="browser-actions-right">
               <div className="upload-menu-wrap">
@@ -2406,6 +3074,15 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
 	            <button className="action-btn" type="button" onClick={() => requestMoveTo(selectedObjects)} disabled={selectedCount === 0} title="Move To">
 	              Move To
 	            </button>
+	            <button
+	              className="action-btn"
+	              type="button"
+	              onClick={() => void requestCopyToProfile(selectedObjects)}
+	              disabled={selectedCount === 0}
+	              title="Copy to another profile"
+	            >
+	              Copy to Profile
+	            </button>
 	            <button
 	              className="action-btn danger"
 	              type="button"
@@ -2511,11 +3188,15 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
 			                        </label>
 			                      </th>
 		                      <th className="resizable">
-		                        <span className="th-label">Name</span>
+		                        <button className="th-label th-sort" type="button" onClick={() => toggleSort('name')} title="Sort by name">
+		                          {'Name'}{sortIndicator('name')}
+		                        </button>
 		                        <div className="col-resizer" onPointerDown={(e) => startColumnResize(1, e)} />
 		                      </th>
 		                      <th className="resizable">
-		                        <span className="th-label">Size</span>
+		                        <button className="th-label th-sort" type="button" onClick={() => toggleSort('size')} title="Sort by size">
+		                          {'Size'}{sortIndicator('size')}
+		                        </button>
 		                        <div className="col-resizer" onPointerDown={(e) => startColumnResize(2, e)} />
 		                      </th>
 		                      <th className="resizable">
@@ -2523,7 +3204,9 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
 		                        <div className="col-resizer" onPointerDown={(e) => startColumnResize(3, e)} />
 		                      </th>
 		                      <th className="resizable">
-		                        <span className="th-label">Last Modified</span>
+		                        <button className="th-label th-sort" type="button" onClick={() => toggleSort('modified')} title="Sort by last modified">
+		                          {'Last Modified'}{sortIndicator('modified')}
+		                        </button>
 		                        <div className="col-resizer" onPointerDown={(e) => startColumnResize(4, e)} />
 		                      </th>
 		                      <th>
@@ -2588,10 +3271,10 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
 		                                   <path d="M10 4H4c-1.1 0-1.99.9-1.99 2L2 18c0 1.1.9 2 2 2h16c1.1 0 2-.9 2-2V8c0-1.1-.9-2-2-2h-8l-2-2z"/>
 		                                 </svg>
 		                               ) : isImageObjectInfo(obj) ? (
-		                                 thumbUrlCacheRef.current.get(obj.path) ? (
+		                                 thumbUrlFor(obj) ? (
 		                                   <img
 		                                     className="file-thumb"
-		                                     src={thumbUrlCacheRef.current.get(obj.path)}
+		                                     src={thumbUrlFor(obj)}
 		                                     alt=""
 		                                     aria-hidden="true"
 		                                     draggable={false}
@@ -2614,9 +3297,14 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
 		                               )}
 			                            </div>
 	                            <span className="file-name-text">{obj.name}</span>
+	                            {restoreBadge(obj) && (
+	                              <span className={`restore-badge ${obj.restoreState}`} title={restoreBadge(obj)!.title}>
+	                                {restoreBadge(obj)!.label}
+	                              </span>
+	                            )}
 	                          </div>
 	                        </td>
-	                        <td>{!isFolder(obj) ? formatSize(obj.size) : '-'}</td>
+	                        <td>{!isFolder(obj) ? formatSize(obj.size) : formatFolderSize(obj)}</td>
 	                        <td>{displayType(obj)}</td>
 	                        <td>{obj.lastModified || '-'}</td>
 	                        <td className="file-actions-td">
@@ -2772,6 +3460,36 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
               Download
             </div>
           )}
+          {contextMenu.object && (isFolder(contextMenu.object) || contextMenu.object.restoreState === 'archived') && (
+            <div className="context-menu-item" onClick={() => contextMenu.object && openRestoreModal([contextMenu.object])}>
+              <span className="context-menu-icon">
+                <svg width="16" height="16" viewBox="0 0 24 24" fill="currentColor">
+                  <path d="M13 3a9 9 0 00-9 9H1l3.89 3.89.07.14L9 12H6c0-3.87 3.13-7 7-7s7 3.13 7 7-3.13 7-7 7c-1.93 0-3.68-.79-4.94-2.06l-1.42 1.42A8.954 8.954 0 0013 21a9 9 0 000-18zm-1 5v5l4.28 2.54.72-1.21-3.5-2.08V8H12z"/>
+                </svg>
+              </span>
+              {isFolder(contextMenu.object) ? 'Restore Archived Objects…' : 'Restore…'}
+            </div>
+          )}
+          {contextMenu.object && !isFolder(contextMenu.object) && contextMenu.object.restoreState === 'ongoing' && (
+            <div className="context-menu-item" onClick={() => void handleDownloadWhenRestored()}>
+              <span className="context-menu-icon">
+                <svg width="16" height="16" viewBox="0 0 24 24" fill="currentColor">
+                  <path d="M19 9h-4V3H9v6H5l7 7 7-7zM5 18v2h14v-2H5z"/>
+                </svg>
+              </span>
+              Download When Restored
+            </div>
+          )}
+          {contextMenu.object && isFolder(contextMenu.object) && (
+            <div className="context-menu-item" onClick={() => void handleCalculateFolderSize()}>
+              <span className="context-menu-icon">
+                <svg width="16" height="16" viewBox="0 0 24 24" fill="currentColor">
+                  <path d="M3 13h2v-2H3v2zm0 4h2v-2H3v2zm0-8h2V7H3v2zm4 4h14v-2H7v2zm0 4h14v-2H7v2zM7 7v2h14V7H7z"/>
+                </svg>
+              </span>
+              Calculate Size
+            </div>
+          )}
           {contextMenu.object && !isFolder(contextMenu.object) && (
             <div className="context-menu-item" onClick={() => handlePreview()}>
               <span className="context-menu-icon">
@@ -2821,6 +3539,50 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
 	        </div>
 	      )}
 
+	      {restoreTargets && (
+	        <div className="modal-overlay" onClick={() => setRestoreTargets(null)}>
+	          <div className="modal-content" onClick={(e) => e.stopPropagation()}>
+	            <div className="modal-header">
+	              <h3 className="modal-title">Restore</h3>
+	            </div>
+	            <p className="modal-description">
+	              {restoreTargets.length === 1 ? `Restore "${restoreTargets[0].name}"` : `Restore ${restoreTargets.length} items`} so it can be read
+	              for the given number of days. Folders restore every archived object below them. You are notified when the
+	              restore finishes while Walioss is open.
+	            </p>
+	            <input
+	              className="modal-input"
+	              type="number"
+	              min="1"
+	              max="365"
+	              value={restoreDays}
+	              onChange={(e) => setRestoreDays(e.target.value)}
+	              placeholder="Days"
+	              disabled={operationLoading}
+	            />
+	            <select
+	              className="modal-input"
+	              value={restoreTier}
+	              onChange={(e) => setRestoreTier(e.target.value)}
+	              disabled={operationLoading}
+	              title="Retrieval tier (Cold Archive and Deep Cold Archive only)"
+	            >
+	              <option value="Expedited">Expedited</option>
+	              <option value="Standard">Standard</option>
+	              <option value="Bulk">Bulk</option>
+	            </select>
+	            <div className="modal-actions">
+	              <button className="modal-btn modal-btn-cancel" type="button" onClick={() => setRestoreTargets(null)} disabled={operationLoading}>
+	                Cancel
+	              </button>
+	              <button className="modal-btn modal-btn-primary" type="button" onClick={() => void confirmRestore()} disabled={operationLoading}>
+	                {operationLoading ? 'Restoring…' : 'Restore'}
+	              </button>
+	            </div>
+	          </div>
+	        </div>
+	      )}
+
 	      {createFolderModalOpen && (
 	        <div className="modal-overlay" onClick={() => setCreateFolderModalOpen(false)}>
 	          <div className="modal-content" onClick={(e) => e.stopPropagation()}>
@@ -2932,6 +3694,134 @@ function FileBrowser({ config, profileName, listViewMode = 'finder', initialPath
 	        </div>
 	      )}
 
+	      {copyProfileModalOpen && (
+	        <div className="modal-overlay" onClick={() => setCopyProfileModalOpen(false)}>
+	          <div className="modal-content" onClick={(e) => e.stopPropagation()}>
+	            <div className="modal-header">
+	              <h3 className="modal-title">Copy to Profile</h3>
+	            </div>
+	            <p className="modal-description">
+	              {`Copy ${copyProfileTargets.length} item(s) into a location of another profile. The data streams through this app.`}
+	            </p>
+	            <select
+	              className="modal-input"
+	              value={copyProfileName}
+	              onChange={(e) => setCopyProfileName(e.target.value)}
+	              disabled={operationLoading}
+	            >
+	              {copyProfiles.map((p) => (
+	                <option key={p.name} value={p.name}>
+	                  {p.name}
+	                </option>
+	              ))}
+	            </select>
+	            <input
+	              className="modal-input mono"
+	              type="text"
+	              value={copyProfileDest}
+	              onChange={(e) => setCopyProfileDest(e.target.value)}
+	              onKeyDown={(e) => {
+	                if (e.key === 'Enter') void confirmCopyToProfile();
+	                if (e.key === 'Escape') setCopyProfileModalOpen(false);
+	              }}
+	              placeholder="oss://bucket/path/"
+	              disabled={operationLoading}
+	            />
+	            <div className="modal-actions">
+	              <button className="modal-btn modal-btn-cancel" type="button" onClick={() => setCopyProfileModalOpen(false)} disabled={operationLoading}>
+	                Cancel
+	              </button>
+	              <button
+	                className="modal-btn modal-btn-primary"
+	                type="button"
+	                onClick={() => void confirmCopyToProfile()}
+	                disabled={operationLoading || !copyProfileName || !copyProfileDest.trim()}
+	              >
+	                {operationLoading ? 'Copying…' : 'Copy'}
+	              </button>
+	            </div>
+	          </div>
+	        </div>
+	      )}
+
+	      {webdavModalOpen && (
+	        <div className="modal-overlay" onClick={() => setWebdavModalOpen(false)}>
+	          <div className="modal-content properties-modal" onClick={(e) => e.stopPropagation()}>
+	            <div className="modal-header">
+	              <h3 className="modal-title">WebDAV Server</h3>
+	            </div>
+	            {webdavStatus?.running ? (
+	              <div className="properties-body">
+	                <div className="property-row">
+	                  <span className="property-label">URL</span>
+	                  <span className="property-value mono" onClick={() => void copyWebDAVValue('URL', webdavStatus.url)} title="Click to copy">{webdavStatus.url}</span>
+	                </div>
+	                <div className="property-row">
+	                  <span className="property-label">Serving</span>
+	                  <span className="property-value mono">{`oss://${webdavStatus.bucket}/${webdavStatus.prefix || ''}`}</span>
+	                </div>
+	                <div className="property-row">
+	                  <span className="property-label">Username</span>
+	                  <span className="property-value mono" onClick={() => void copyWebDAVValue('Username', webdavStatus.username)} title="Click to copy">{webdavStatus.username}</span>
+	                </div>
+	                <div className="property-row">
+	                  <span className="property-label">Password</span>
+	                  <span className="property-value mono" onClick={() => void copyWebDAVValue('Password', webdavStatus.password)} title="Click to copy">{webdavStatus.password}</span>
+	                </div>
+	              </div>
+	            ) : (
+	              <>
+	                <p className="modal-description">
+	                  {`Serve oss://${currentBucket}/${currentPrefix || ''} on 127.0.0.1 with basic auth. Leave the password empty to generate one.`}
+	                </p>
+	                <input
+	                  className="modal-input mono"
+	                  type="text"
+	                  value={webdavUsername}
+	                  onChange={(e) => setWebdavUsername(e.target.value)}
+	                  placeholder="Username (walioss)"
+	                  disabled={webdavBusy}
+	                />
+	                <input
+	                  className="modal-input mono"
+	                  type="password"
+	                  value={webdavPassword}
+	                  onChange={(e) => setWebdavPassword(e.target.value)}
+	                  placeholder="Password (generated)"
+	                  disabled={webdavBusy}
+	                />
+	                <input
+	                  className="modal-input mono"
+	                  type="text"
+	                  value={webdavPort}
+	                  onChange={(e) => setWebdavPort(e.target.value)}
+	                  onKeyDown={(e) => {
+	                    if (e.key === 'Enter') void handleStartWebDAV();
+	                    if (e.key === 'Escape') setWebdavModalOpen(false);
+	                  }}
+	                  placeholder="Port (any free port)"
+	                  disabled={webdavBusy}
+	                />
+	              </>
+	            )}
+	            <div className="modal-actions">
+	              <button className="modal-btn modal-btn-cancel" type="button" onClick={() => setWebdavModalOpen(false)} disabled={webdavBusy}>
+	                Close
+	              </button>
+	              {webdavStatus?.running ? (
+	                <button className="modal-btn modal-btn-primary" type="button" onClick={() => void handleStopWebDAV()} disabled={webdavBusy}>
+	                  {webdavBusy ? 'Stopping…' : 'Stop'}
+	                </button>
+	              ) : (
+	                <button className="modal-btn modal-btn-primary" type="button" onClick={() => void handleStartWebDAV()} disabled={webdavBusy || !currentBucket}>
+	                  {webdavBusy ? 'Starting…' : 'Start'}
+	                </button>
+	              )}
+	            </div>
+	          </div>
+	        </div>
+	      )}
+
 	      <FilePreviewModal
 	        isOpen={previewModalOpen}
 	        config={config}
@@ -1,6 +1,7 @@
 import { useCallback, useEffect, useLayoutEffect, useMemo, useRef, useState } from 'react';
 import { main } from '../../wailsjs/go/models';
-import { GetObjectText, PresignObject, PutObjectText } from '../../wailsjs/go/main/OSSService';
+import { objectKeyOf } from '../objectKeys';
+import { GetObjectText, ListTextEncodings, OpenObjectText, PresignObject, SaveObjectText } from '../../wailsjs/go/main/OSSService';
 import './FilePreviewModal.css';
 import './Modal.css';
 
@@ -291,7 +292,20 @@ export default function FilePreviewModal({
   const [mediaFallbackTried, setMediaFallbackTried] = useState(false);
   const [text, setText] = useState<string>('');
   const [originalText, setOriginalText] = useState<string>('');
+  const [textEtag, setTextEtag] = useState<string>('');
+  const [textEncoding, setTextEncoding] = useState<{ encoding: string; bom: boolean }>({ encoding: '', bom: false });
+  // saveEncoding starts as the encoding the text was opened with and can be changed to save it as another.
+  const [saveEncoding, setSaveEncoding] = useState<{ encoding: string; bom: boolean }>({ encoding: '', bom: false });
+  const [encodings, setEncodings] = useState<string[]>([]);
   const [truncated, setTruncated] = useState(false);
+  // conflict holds the remote side of a save that was rejected because the object changed.
+  const [conflict, setConflict] = useState<{
+    message: string;
+    remoteEtag: string;
+    remoteContent: string;
+    remoteTruncated: boolean;
+    remoteDeleted: boolean;
+  } | null>(null);
   const [loadElapsedMs, setLoadElapsedMs] = useState<number | null>(null);
   const [pathCopyState, setPathCopyState] = useState<'idle' | 'copied' | 'failed'>('idle');
   const [highlightHtml, setHighlightHtml] = useState<string>('');
@@ -304,9 +318,8 @@ export default function FilePreviewModal({
 
   const fileKey = useMemo(() => {
     if (!object?.path || !bucket) return '';
-    const prefix = `oss://${bucket}/`;
-    return object.path.startsWith(prefix) ? object.path.slice(prefix.length) : '';
-  }, [bucket, object?.path]);
+    return objectKeyOf(object, bucket);
+  }, [bucket, object?.path, object?.rawKey]);
 
   const extension = useMemo(() => (object ? getFileExtension(object.name) : ''), [object]);
 
@@ -339,7 +352,19 @@ export default function FilePreviewModal({
     return object.size <= MAX_TEXT_PREVIEW_BYTES;
   }, [kindFromName, object]);
 
-  const dirty = canEditText && text !== originalText;
+  const dirty =
+    canEditText &&
+    (text !== originalText || saveEncoding.encoding !== textEncoding.encoding || saveEncoding.bom !== textEncoding.bom);
+
+  const applyTextDocument = (doc: main.ObjectTextDocument) => {
+    setText(doc.content);
+    setOriginalText(doc.content);
+    setTextEtag(doc.etag);
+    setTextEncoding({ encoding: doc.encoding, bom: doc.hasBom });
+    setSaveEncoding({ encoding: doc.encoding, bom: doc.hasBom });
+    setTruncated(doc.truncated);
+    setConflict(null);
+  };
 
   const requestClose = useCallback(() => {
     if (dirty) {
@@ -364,6 +389,9 @@ export default function FilePreviewModal({
     setMediaFallbackTried(false);
     setText('');
     setOriginalText('');
+    setTextEtag('');
+    setTextEncoding({ encoding: '', bom: false });
+    setSaveEncoding({ encoding: '', bom: false });
     setTruncated(false);
     setLoadElapsedMs(null);
     setPathCopyState('idle');
@@ -393,11 +421,9 @@ export default function FilePreviewModal({
 
         if (kindFromName === 'text') {
           const maxBytes = canEditText ? MAX_TEXT_EDIT_BYTES : MAX_TEXT_PREVIEW_BYTES;
-          const content = await GetObjectText(config, bucket, fileKey, maxBytes);
+          const doc = await OpenObjectText(config, bucket, fileKey, maxBytes, '');
           if (loadSeqRef.current !== seq) return;
-          setText(content);
-          setOriginalText(content);
-          setTruncated(!!object.size && object.size > maxBytes);
+          applyTextDocument(doc);
           return;
         }
 
@@ -429,6 +455,15 @@ export default function FilePreviewModal({
     void load();
   }, [bucket, canEditText, config, fileKey, isOpen, kindFromName, object, shouldTryTextFallback]);
 
+  useEffect(() => {
+    if (!isOpen || encodings.length > 0) return;
+    ListTextEncodings()
+      .then((list) => setEncodings(list || []))
+      .catch(() => {
+        // Without the list the encoding is shown but cannot be changed.
+      });
+  }, [encodings.length, isOpen]);
+
   useEffect(() => {
     return () => {
       if (pathCopyTimerRef.current) {
@@ -515,21 +550,87 @@ export default function FilePreviewModal({
   const effectivePresignedUrl = presignedForPath && presignedForPath === object.path ? presignedUrl : '';
   const effectiveMediaUrl = presignedForPath && presignedForPath === object.path ? mediaUrl : '';
 
-  const handleSave = async () => {
+  // reopenWithEncoding decodes the object again when the detected encoding was wrong.
+  const reopenWithEncoding = async (encoding: string) => {
+    if (!fileKey || !encoding || encoding === textEncoding.encoding) return;
+    if (dirty && !window.confirm('Re-opening the file discards your unsaved changes. Continue?')) return;
+    const seq = (loadSeqRef.current += 1);
+    setLoading(true);
+    setError(null);
+    try {
+      const doc = await OpenObjectText(config, bucket, fileKey, canEditText ? MAX_TEXT_EDIT_BYTES : MAX_TEXT_PREVIEW_BYTES, encoding);
+      if (loadSeqRef.current !== seq) return;
+      applyTextDocument(doc);
+    } catch (err: any) {
+      if (loadSeqRef.current !== seq) return;
+      setError(err?.message || `Failed to open the file as ${encoding}`);
+    } finally {
+      if (loadSeqRef.current === seq) setLoading(false);
+    }
+  };
+
+  const supportsBom = (encoding: string) => encoding.toUpperCase().startsWith('UTF');
+
+  const saveText = async (expectedEtag: string) => {
     if (!canEditText || !fileKey) return;
     setSaving(true);
     setError(null);
     try {
-      await PutObjectText(config, bucket, fileKey, text);
+      const result = await SaveObjectText(config, bucket, fileKey, text, {
+        expectedEtag,
+        backup: false,
+        backupKey: '',
+        encoding: saveEncoding.encoding,
+        bom: saveEncoding.bom,
+      });
       setOriginalText(text);
+      setTextEtag(result.etag);
+      setTextEncoding(saveEncoding);
+      setConflict(null);
       onSaved?.();
     } catch (err: any) {
-      setError(err?.message || 'Save failed');
+      if (err?.kind === 'conflict') {
+        setConflict({
+          message: err.message || 'The file was changed since it was opened.',
+          remoteEtag: err.remoteEtag || '',
+          remoteContent: err.remoteContent || '',
+          remoteTruncated: !!err.remoteTruncated,
+          remoteDeleted: !!err.remoteDeleted,
+        });
+      } else {
+        setError(err?.message || 'Save failed');
+      }
     } finally {
       setSaving(false);
     }
   };
 
+  const handleSave = () => saveText(textEtag);
+
+  // Overwriting saves against the remote ETag, so a third change in between is still caught.
+  const handleOverwriteRemote = () => {
+    if (!conflict) return;
+    void saveText(conflict.remoteEtag);
+  };
+
+  const handleReloadRemote = async () => {
+    if (!fileKey) return;
+    if (!window.confirm('Reloading discards your changes and shows the remote file. Continue?')) return;
+    const seq = (loadSeqRef.current += 1);
+    setLoading(true);
+    setError(null);
+    try {
+      const doc = await OpenObjectText(config, bucket, fileKey, MAX_TEXT_EDIT_BYTES, textEncoding.encoding);
+      if (loadSeqRef.current !== seq) return;
+      applyTextDocument(doc);
+    } catch (err: any) {
+      if (loadSeqRef.current !== seq) return;
+      setError(err?.message || 'Failed to reload the file');
+    } finally {
+      if (loadSeqRef.current === seq) setLoading(false);
+    }
+  };
+
   const renderBody = () => {
     if (loading) {
       return (
@@ -623,6 +724,62 @@ export default function FilePreviewModal({
         );
       }
 
+      if (conflict) {
+        return (
+          <div className="preview-text split">
+            <div className="preview-banner preview-conflict-banner">
+              <span>
+                {conflict.message}.{' '}
+                {conflict.remoteDeleted
+                  ? 'Overwriting creates it again with your text.'
+                  : 'Reload the remote file to drop your changes, or overwrite it with your text.'}
+              </span>
+              <div className="preview-conflict-actions">
+                {!conflict.remoteDeleted && (
+                  <button className="preview-btn" type="button" onClick={() => void handleReloadRemote()} disabled={saving}>
+                    Reload remote
+                  </button>
+                )}
+                <button className="preview-btn primary" type="button" onClick={handleOverwriteRemote} disabled={saving}>
+                  {saving ? 'Saving…' : 'Overwrite'}
+                </button>
+                <button className="preview-btn" type="button" onClick={() => setConflict(null)} disabled={saving}>
+                  Keep editing
+                </button>
+              </div>
+            </div>
+            <div className="editor-split">
+              <div className="editor-pane">
+                <div className="pane-title">Your changes</div>
+                <textarea
+                  className="text-editor"
+                  value={text}
+                  onChange={(e) => setText(e.target.value)}
+                  wrap="off"
+                  spellCheck={false}
+                  disabled={saving}
+                />
+              </div>
+              <div className="preview-pane">
+                <div className="pane-title">Remote</div>
+                {conflict.remoteDeleted ? (
+                  <div className="pane-hint">The file no longer exists.</div>
+                ) : (
+                  <>
+                    {conflict.remoteTruncated && (
+                      <div className="pane-hint">Only the beginning of the remote file is shown.</div>
+                    )}
+                    <pre className="preview-code">
+                      <code>{conflict.remoteContent}</code>
+                    </pre>
+                  </>
+                )}
+              </div>
+            </div>
+          </div>
+        );
+      }
+
       return (
         <div className="preview-text">
           {truncated && <div className="preview-banner">Preview is truncated to {formatBytes(MAX_TEXT_EDIT_BYTES)}.</div>}
@@ -720,6 +877,29 @@ export default function FilePreviewModal({
                   <span className="meta-value">{formatDuration(videoMeta.duration)}</span>
                 </div>
               ) : null}
+              {kind === 'text' && textEncoding.encoding && (
+                <div className="meta-chip" title="Detected text encoding; pick another to re-open the file with it">
+                  <span className="meta-label">Encoding</span>
+                  {encodings.length > 0 ? (
+                    <select
+                      className="meta-select"
+                      value={textEncoding.encoding}
+                      onChange={(e) => void reopenWithEncoding(e.target.value)}
+                      disabled={loading || saving}
+                      aria-label="Re-open with encoding"
+                    >
+                      {encodings.map((name) => (
+                        <option key={name} value={name}>
+                          {name}
+                        </option>
+                      ))}
+                    </select>
+                  ) : (
+                    <span className="meta-value">{textEncoding.encoding}</span>
+                  )}
+                  {textEncoding.bom && <span className="meta-label">BOM</span>}
+                </div>
+              )}
               {object.storageClass && (
                 <div className="meta-chip">
                   <span className="meta-label">Storage</span>
@@ -741,6 +921,38 @@ export default function FilePreviewModal({
             </div>
           </div>
           <div className="preview-actions">
+            {kind === 'text' && canEditText && textEncoding.encoding && encodings.length > 0 && (
+              <div className="preview-save-encoding">
+                <label>
+                  Save as
+                  <select
+                    value={saveEncoding.encoding}
+                    onChange={(e) => {
+                      const encoding = e.target.value;
+                      setSaveEncoding((prev) => ({ encoding, bom: supportsBom(encoding) && prev.bom }));
+                    }}
+                    disabled={saving}
+                  >
+                    {encodings.map((name) => (
+                      <option key={name} value={name}>
+                        {name}
+                      </option>
+                    ))}
+                  </select>
+                </label>
+                {supportsBom(saveEncoding.encoding) && (
+                  <label title="Write a byte order mark at the start of the file">
+                    <input
+                      type="checkbox"
+                      checked={saveEncoding.bom}
+                      onChange={(e) => setSaveEncoding((prev) => ({ ...prev, bom: e.target.checked }))}
+                      disabled={saving}
+                    />
+                    BOM
+                  </label>
+                )}
+              </div>
+            )}
             {kind === 'text' && canEditText && (
               <button className="preview-btn primary" onClick={handleSave} disabled={!dirty || saving}>
                 {saving ? 'Saving…' : 'Save'}
@@ -3,7 +3,7 @@ import './TransferModal.css';
 import './Modal.css';
 
 type TransferStatus = 'queued' | 'in-progress' | 'success' | 'error';
-type TransferType = 'upload' | 'download';
+type TransferType = 'upload' | 'download' | 'storage-class' | 'restore' | 'rename' | 'copy';
 type TransferView = 'all' | TransferType;
 
 export type TransferRecord = {
@@ -119,11 +119,37 @@ function transferMatches(t: TransferRecord, q: string) {
 }
 
 function transferTypeLabel(type: TransferType) {
-  return type === 'upload' ? 'Upload' : 'Download';
+  switch (type) {
+    case 'upload':
+      return 'Upload';
+    case 'storage-class':
+      return 'Storage class';
+    case 'restore':
+      return 'Restore';
+    case 'rename':
+      return 'Rename';
+    case 'copy':
+      return 'Copy';
+    default:
+      return 'Download';
+  }
 }
 
 function transferTypeIcon(type: TransferType) {
-  return type === 'upload' ? '↑' : '↓';
+  switch (type) {
+    case 'upload':
+      return '↑';
+    case 'storage-class':
+      return '⇄';
+    case 'restore':
+      return '⟲';
+    case 'rename':
+      return '✎';
+    case 'copy':
+      return '⧉';
+    default:
+      return '↓';
+  }
 }
 
 function transferStatusLabel(status: TransferStatus) {
@@ -170,9 +196,10 @@ interface TransferModalProps {
   onClose: () => void;
   onReveal: (path: string) => void;
   onOpen: (path: string) => void;
+  onResume?: (id: string) => void;
 }
 
-export default function TransferModal({ isOpen, activeTab, onTabChange, transfers, onClose, onReveal, onOpen }: TransferModalProps) {
+export default function TransferModal({ isOpen, activeTab, onTabChange, transfers, onClose, onReveal, onOpen, onResume }: TransferModalProps) {
   const [search, setSearch] = useState('');
   const [expandedItemIds, setExpandedItemIds] = useState<Record<string, boolean>>({});
 
@@ -333,10 +360,18 @@ export default function TransferModal({ isOpen, activeTab, onTabChange, transfer
     );
   };
 
-  const renderTransferActions = (t: TransferRecord) =>
-    t.type === 'download' &&
-    t.status === 'success' &&
-    t.localPath && (
+  const renderTransferActions = (t: TransferRecord) => {
+    if (t.type === 'copy' && t.status === 'error' && !t.parentId && onResume) {
+      return (
+        <div className="transfer-actions">
+          <button className="transfer-action-btn primary" type="button" onClick={() => onResume(t.id)}>
+            Resume
+          </button>
+        </div>
+      );
+    }
+    if (t.type !== 'download' || t.status !== 'success' || !t.localPath) return null;
+    return (
       <div className="transfer-actions">
         <button className="transfer-action-btn" type="button" onClick={() => onReveal(t.localPath!)}>
           Reveal
@@ -346,6 +381,7 @@ export default function TransferModal({ isOpen, activeTab, onTabChange, transfer
         </button>
       </div>
     );
+  };
 
   const renderStatusMeta = (t: TransferRecord, isCompleted: boolean, speedForMeta?: number) => (
     <div className={`transfer-status-meta ${isCompleted ? 'completed' : ''}`} aria-label="Transfer summary">
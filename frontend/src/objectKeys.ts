@@ -0,0 +1,34 @@
+// Keys with trailing spaces, control characters or invalid UTF-8 do not survive JSON or
+// trimming, so listings send them as rawKey: ENCODED_OBJECT_KEY_PREFIX followed by the
+// URL-encoded key. Every backend API taking a key accepts that form in place of the key.
+export const ENCODED_OBJECT_KEY_PREFIX = '\u0000url:';
+
+type KeyedObject = { path?: string; rawKey?: string };
+
+const keyFromOssPath = (path: string | undefined, bucket?: string) => {
+  let p = (path || '').trim();
+  if (!p.startsWith('oss://')) return '';
+  p = p.substring(6).replace(/^\/+/, '');
+  const slash = p.indexOf('/');
+  if (slash < 0) return '';
+  if (bucket && p.slice(0, slash) !== bucket) return '';
+  return p.slice(slash + 1);
+};
+
+// objectKeyOf returns the key to pass to backend APIs for a listed object.
+export const objectKeyOf = (obj: KeyedObject | null | undefined, bucket?: string) => {
+  if (!obj) return '';
+  return obj.rawKey || keyFromOssPath(obj.path, bucket);
+};
+
+// childObjectKey returns the key of obj once moved under destPrefix, keeping the encoded
+// form when the object's own key needed it.
+export const childObjectKey = (destPrefix: string, obj: KeyedObject & { name: string }, isFolder: boolean) => {
+  if (!obj.rawKey?.startsWith(ENCODED_OBJECT_KEY_PREFIX)) {
+    return `${destPrefix || ''}${(obj.name || '').replace(/\/+$/, '')}${isFolder ? '/' : ''}`;
+  }
+  // The encoded form escapes "/" as %2F, so the last non-empty segment is the encoded name.
+  const segments = obj.rawKey.slice(ENCODED_OBJECT_KEY_PREFIX.length).split('%2F').filter(Boolean);
+  const name = segments[segments.length - 1] || '';
+  return `${ENCODED_OBJECT_KEY_PREFIX}${encodeURIComponent(destPrefix || '')}${name}${isFolder ? '%2F' : ''}`;
+};
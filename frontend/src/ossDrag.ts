@@ -2,13 +2,14 @@ export const OSS_OBJECTS_DRAG_TYPE = 'application/x-walioss-oss-objects';
 
 export type OssDragItem = {
   path: string;
+  rawKey?: string;
   name: string;
   isFolder: boolean;
 };
 
 export type OssDragPayload = {
   type: 'walioss-oss-objects';
-  source?: { bucket: string; prefix: string };
+  source?: { bucket: string; prefix: string; profileName?: string | null };
   items: OssDragItem[];
 };
 
@@ -21,6 +22,24 @@ export const canReadOssDragPayload = (dt: DataTransfer | null | undefined) => {
   }
 };
 
+// Items dragged from a session with another profile cannot be moved with this session's
+// credentials; they are copied by streaming them between the two profiles instead.
+export const isCrossProfileDrag = (payload: OssDragPayload, profileName: string | null | undefined) => {
+  const sourceProfile = payload.source?.profileName;
+  return !!sourceProfile && sourceProfile !== (profileName || null);
+};
+
+// copySourcePaths returns the oss:// paths EnqueueCrossProfileCopy takes for the dragged
+// items, with the encoded raw key in place of keys that are not plain text.
+export const copySourcePaths = (payload: OssDragPayload) =>
+  payload.items.map((item) => {
+    if (item.rawKey) {
+      const bucket = (payload.source?.bucket || '').replace(/\/+$/, '');
+      return `oss://${bucket}/${item.rawKey}`;
+    }
+    return item.isFolder && !item.path.endsWith('/') ? `${item.path}/` : item.path;
+  });
+
 export const readOssDragPayload = (dt: DataTransfer | null | undefined): OssDragPayload | null => {
   if (!dt) return null;
   let raw = '';
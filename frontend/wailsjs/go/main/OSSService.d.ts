@@ -3,64 +3,198 @@
 import {main} from '../models';
 import {context} from '../models';
 
+export function BuildFolderIndex(arg1:main.OSSConfig,arg2:string,arg3:string):Promise<main.FolderIndexStatus>;
+
+export function CancelTask(arg1:string):Promise<void>;
+
 export function CheckOssutilInstalled():Promise<main.ConnectionResult>;
 
 export function CheckUploadNameCollisions(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:Array<string>):Promise<Array<main.UploadNameCollision>>;
 
+export function ClearListingCache(arg1:main.OSSConfig):Promise<void>;
+
+export function ClearThumbnailCache():Promise<void>;
+
+export function CompareLocalAndRemote(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:string,arg5:string):Promise<string>;
+
+export function CompareLocations(arg1:main.OSSConfig,arg2:string,arg3:main.OSSConfig,arg4:string,arg5:main.CompareLocationsOptions):Promise<string>;
+
+export function CopyShareLink(arg1:main.OSSConfig,arg2:string):Promise<void>;
+
 export function CreateFile(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:string):Promise<void>;
 
 export function CreateFolder(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:string):Promise<void>;
 
+export function CreateSymlink(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:string):Promise<void>;
+
 export function DeleteObject(arg1:main.OSSConfig,arg2:string,arg3:string):Promise<void>;
 
+export function DeleteObjectVersions(arg1:main.OSSConfig,arg2:string,arg3:Array<main.ObjectVersionRef>):Promise<void>;
+
 export function DeleteProfile(arg1:string):Promise<void>;
 
+export function DetectObjectFormat(arg1:main.OSSConfig,arg2:string,arg3:string):Promise<main.ObjectFormatInfo>;
+
 export function DownloadFile(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:string):Promise<void>;
 
+export function EnqueueComparisonDownloads(arg1:main.OSSConfig,arg2:string,arg3:boolean):Promise<string>;
+
+export function EnqueueComparisonUploads(arg1:main.OSSConfig,arg2:string,arg3:boolean):Promise<string>;
+
+export function EnqueueCrossProfileCopy(arg1:main.OSSConfig,arg2:Array<string>,arg3:main.OSSConfig,arg4:string,arg5:string):Promise<Array<string>>;
+
 export function EnqueueDownload(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:string,arg5:number):Promise<string>;
 
+export function EnqueueDownloadAfterRestore(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:string):Promise<string>;
+
 export function EnqueueDownloadFolder(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:string):Promise<string>;
 
+export function EnqueueDownloadVersion(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:string,arg5:string,arg6:number):Promise<string>;
+
+export function EnqueueStorageClassChange(arg1:main.OSSConfig,arg2:string,arg3:Array<string>,arg4:string):Promise<string>;
+
 export function EnqueueUpload(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:string):Promise<string>;
 
 export function EnqueueUploadPaths(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:Array<string>):Promise<Array<string>>;
 
 export function EnqueueUploadRoots(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:Array<main.UploadRootSpec>):Promise<Array<string>>;
 
+export function EstimateStorageClassChange(arg1:main.OSSConfig,arg2:string,arg3:Array<string>,arg4:string):Promise<main.StorageClassChangeEstimate>;
+
+export function ExportComparison(arg1:string,arg2:string,arg3:string,arg4:boolean):Promise<void>;
+
+export function ExportLocationComparison(arg1:string,arg2:string,arg3:string,arg4:boolean):Promise<void>;
+
+export function ExportShareLinks(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:string):Promise<void>;
+
+export function ExtractArchiveEntry(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:string,arg5:string):Promise<main.ArchiveEntryContent>;
+
+export function GetComparisonEntries(arg1:string,arg2:Array<string>):Promise<Array<main.CompareEntry>>;
+
 export function GetDefaultProfile():Promise<main.OSSProfile>;
 
+export function GetFolderIndexStatus(arg1:main.OSSConfig,arg2:string,arg3:string):Promise<main.FolderIndexStatus>;
+
+export function GetFolderStats(arg1:main.OSSConfig,arg2:string,arg3:string):Promise<main.FolderStats>;
+
+export function GetObjectHexDump(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:number,arg5:number):Promise<main.HexDumpPage>;
+
+export function GetObjectInfo(arg1:main.OSSConfig,arg2:string,arg3:string):Promise<main.ObjectInfo>;
+
+export function GetObjectRange(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:number,arg5:number):Promise<main.ObjectRangeResult>;
+
 export function GetObjectText(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:number):Promise<string>;
 
+export function GetObjectVersionText(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:string,arg5:number):Promise<string>;
+
+export function GetOperationJournal(arg1:main.OSSConfig):Promise<Array<main.OperationEntry>>;
+
 export function GetOssutilPath():Promise<string>;
 
 export function GetProfile(arg1:string):Promise<main.OSSProfile>;
 
+export function GetRecycleBinSettings(arg1:main.OSSConfig):Promise<main.RecycleBinSettings>;
+
 export function GetSettings():Promise<main.AppSettings>;
 
+export function GetThumbnails(arg1:main.OSSConfig,arg2:string,arg3:Array<main.ThumbnailRequest>,arg4:number):Promise<Array<main.ThumbnailResult>>;
+
 export function GetTransferHistory():Promise<Array<main.TransferUpdate>>;
 
+export function GetWebDAVStatus():Promise<main.WebDAVStatus>;
+
+export function IsOfflineMode():Promise<boolean>;
+
+export function ListArchiveEntries(arg1:main.OSSConfig,arg2:string,arg3:string):Promise<main.ArchiveListing>;
+
 export function ListBuckets(arg1:main.OSSConfig):Promise<Array<main.BucketInfo>>;
 
+export function ListIndexedObjectsPage(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:main.FolderIndexPageOptions):Promise<main.IndexedObjectPageResult>;
+
+export function ListObjectKeyVersions(arg1:main.OSSConfig,arg2:string,arg3:string):Promise<Array<main.ObjectVersionInfo>>;
+
+export function ListObjectVersionsPage(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:string,arg5:string,arg6:number):Promise<main.ObjectVersionListPageResult>;
+
 export function ListObjects(arg1:main.OSSConfig,arg2:string,arg3:string):Promise<Array<main.ObjectInfo>>;
 
 export function ListObjectsPage(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:string,arg5:number):Promise<main.ObjectListPageResult>;
 
+export function ListObjectsPageCached(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:string,arg5:number):Promise<main.CachedObjectListPage>;
+
+export function ListShareLinks(arg1:main.OSSConfig,arg2:string):Promise<Array<main.ShareLink>>;
+
+export function ListTextEncodings():Promise<Array<string>>;
+
+export function ListTrash(arg1:main.OSSConfig,arg2:string):Promise<Array<main.TrashItem>>;
+
 export function LoadProfiles():Promise<Array<main.OSSProfile>>;
 
 export function MoveObject(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:string,arg5:string):Promise<void>;
 
+export function OpenObjectText(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:number,arg5:string):Promise<main.ObjectTextDocument>;
+
+export function PresignFolder(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:string,arg5:main.PresignFolderOptions):Promise<main.PresignFolderResult>;
+
 export function PresignObject(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:string):Promise<string>;
 
-export function PutObjectText(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:string):Promise<void>;
+export function PresignObjectVersion(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:string,arg5:string):Promise<string>;
+
+export function PresignObjectWithOptions(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:main.PresignOptions):Promise<main.PresignResult>;
+
+export function PurgeTrash(arg1:main.OSSConfig,arg2:string,arg3:number):Promise<number>;
+
+export function PutObjectText(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:string,arg5:string,arg6:boolean):Promise<void>;
+
+export function ReadObjectLines(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:number,arg5:boolean,arg6:number):Promise<main.ObjectLinesPage>;
+
+export function RefreshFolderStats(arg1:main.OSSConfig,arg2:string,arg3:string):Promise<main.FolderStats>;
+
+export function ResolveSymlink(arg1:main.OSSConfig,arg2:string,arg3:string):Promise<main.ObjectInfo>;
+
+export function RestoreObjectVersion(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:string):Promise<void>;
+
+export function RestoreObjects(arg1:main.OSSConfig,arg2:string,arg3:Array<string>,arg4:number,arg5:string):Promise<string>;
+
+export function RestoreTrashItems(arg1:main.OSSConfig,arg2:string,arg3:Array<string>,arg4:boolean):Promise<main.TrashRestoreResult>;
+
+export function ResumeCopyTransfer(arg1:string):Promise<string>;
+
+export function SanitizeObjectKeys(arg1:main.OSSConfig,arg2:string,arg3:Array<string>):Promise<string>;
+
+export function SaveObjectText(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:string,arg5:main.SaveTextOptions):Promise<main.SaveTextResult>;
 
 export function SaveProfile(arg1:main.OSSProfile):Promise<void>;
 
+export function SaveRecycleBinSettings(arg1:main.OSSConfig,arg2:main.RecycleBinSettings):Promise<void>;
+
 export function SaveSettings(arg1:main.AppSettings):Promise<void>;
 
+export function SearchCachedObjects(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:main.ObjectSearchQuery):Promise<Array<main.ObjectInfo>>;
+
+export function SearchObjects(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:main.ObjectSearchQuery):Promise<string>;
+
+export function SelectObject(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:string,arg5:main.SelectInputFormat,arg6:main.SelectOutputOptions):Promise<main.SelectResult>;
+
 export function SetContext(arg1:context.Context):Promise<void>;
 
+export function SetOfflineMode(arg1:boolean):Promise<void>;
+
 export function SetOssutilPath(arg1:string):Promise<void>;
 
+export function ShareObject(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:main.PresignOptions,arg5:string):Promise<main.ShareLink>;
+
+export function StartWebDAVServer(arg1:main.OSSConfig,arg2:main.WebDAVOptions):Promise<main.WebDAVStatus>;
+
+export function StopWebDAVServer():Promise<void>;
+
+export function SuggestSafeObjectKey(arg1:string):Promise<string>;
+
+export function TailObject(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:number,arg5:string):Promise<main.ObjectTailEvent>;
+
 export function TestConnection(arg1:main.OSSConfig):Promise<main.ConnectionResult>;
 
+export function UndeleteObjects(arg1:main.OSSConfig,arg2:string,arg3:Array<string>):Promise<number>;
+
+export function UndoLastOperation(arg1:main.OSSConfig,arg2:string):Promise<main.OperationEntry>;
+
 export function UploadFile(arg1:main.OSSConfig,arg2:string,arg3:string,arg4:string):Promise<void>;
@@ -2,6 +2,14 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function BuildFolderIndex(arg1, arg2, arg3) {
+  return window['go']['main']['OSSService']['BuildFolderIndex'](arg1, arg2, arg3);
+}
+
+export function CancelTask(arg1) {
+  return window['go']['main']['OSSService']['CancelTask'](arg1);
+}
+
 export function CheckOssutilInstalled() {
   return window['go']['main']['OSSService']['CheckOssutilInstalled']();
 }
@@ -10,6 +18,26 @@ export function CheckUploadNameCollisions(arg1, arg2, arg3, arg4) {
   return window['go']['main']['OSSService']['CheckUploadNameCollisions'](arg1, arg2, arg3, arg4);
 }
 
+export function ClearListingCache(arg1) {
+  return window['go']['main']['OSSService']['ClearListingCache'](arg1);
+}
+
+export function ClearThumbnailCache() {
+  return window['go']['main']['OSSService']['ClearThumbnailCache']();
+}
+
+export function CompareLocalAndRemote(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['OSSService']['CompareLocalAndRemote'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function CompareLocations(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['OSSService']['CompareLocations'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function CopyShareLink(arg1, arg2) {
+  return window['go']['main']['OSSService']['CopyShareLink'](arg1, arg2);
+}
+
 export function CreateFile(arg1, arg2, arg3, arg4) {
   return window['go']['main']['OSSService']['CreateFile'](arg1, arg2, arg3, arg4);
 }
@@ -18,26 +46,62 @@ export function CreateFolder(arg1, arg2, arg3, arg4) {
   return window['go']['main']['OSSService']['CreateFolder'](arg1, arg2, arg3, arg4);
 }
 
+export function CreateSymlink(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['OSSService']['CreateSymlink'](arg1, arg2, arg3, arg4);
+}
+
 export function DeleteObject(arg1, arg2, arg3) {
   return window['go']['main']['OSSService']['DeleteObject'](arg1, arg2, arg3);
 }
 
+export function DeleteObjectVersions(arg1, arg2, arg3) {
+  return window['go']['main']['OSSService']['DeleteObjectVersions'](arg1, arg2, arg3);
+}
+
 export function DeleteProfile(arg1) {
   return window['go']['main']['OSSService']['DeleteProfile'](arg1);
 }
 
+export function DetectObjectFormat(arg1, arg2, arg3) {
+  return window['go']['main']['OSSService']['DetectObjectFormat'](arg1, arg2, arg3);
+}
+
 export function DownloadFile(arg1, arg2, arg3, arg4) {
   return window['go']['main']['OSSService']['DownloadFile'](arg1, arg2, arg3, arg4);
 }
 
+export function EnqueueComparisonDownloads(arg1, arg2, arg3) {
+  return window['go']['main']['OSSService']['EnqueueComparisonDownloads'](arg1, arg2, arg3);
+}
+
+export function EnqueueComparisonUploads(arg1, arg2, arg3) {
+  return window['go']['main']['OSSService']['EnqueueComparisonUploads'](arg1, arg2, arg3);
+}
+
+export function EnqueueCrossProfileCopy(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['OSSService']['EnqueueCrossProfileCopy'](arg1, arg2, arg3, arg4, arg5);
+}
+
 export function EnqueueDownload(arg1, arg2, arg3, arg4, arg5) {
   return window['go']['main']['OSSService']['EnqueueDownload'](arg1, arg2, arg3, arg4, arg5);
 }
 
+export function EnqueueDownloadAfterRestore(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['OSSService']['EnqueueDownloadAfterRestore'](arg1, arg2, arg3, arg4);
+}
+
 export function EnqueueDownloadFolder(arg1, arg2, arg3, arg4) {
   return window['go']['main']['OSSService']['EnqueueDownloadFolder'](arg1, arg2, arg3, arg4);
 }
 
+export function EnqueueDownloadVersion(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['main']['OSSService']['EnqueueDownloadVersion'](arg1, arg2, arg3, arg4, arg5, arg6);
+}
+
+export function EnqueueStorageClassChange(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['OSSService']['EnqueueStorageClassChange'](arg1, arg2, arg3, arg4);
+}
+
 export function EnqueueUpload(arg1, arg2, arg3, arg4) {
   return window['go']['main']['OSSService']['EnqueueUpload'](arg1, arg2, arg3, arg4);
 }
@@ -50,14 +114,66 @@ export function EnqueueUploadRoots(arg1, arg2, arg3, arg4) {
   return window['go']['main']['OSSService']['EnqueueUploadRoots'](arg1, arg2, arg3, arg4);
 }
 
+export function EstimateStorageClassChange(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['OSSService']['EstimateStorageClassChange'](arg1, arg2, arg3, arg4);
+}
+
+export function ExportComparison(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['OSSService']['ExportComparison'](arg1, arg2, arg3, arg4);
+}
+
+export function ExportLocationComparison(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['OSSService']['ExportLocationComparison'](arg1, arg2, arg3, arg4);
+}
+
+export function ExportShareLinks(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['OSSService']['ExportShareLinks'](arg1, arg2, arg3, arg4);
+}
+
+export function ExtractArchiveEntry(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['OSSService']['ExtractArchiveEntry'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function GetComparisonEntries(arg1, arg2) {
+  return window['go']['main']['OSSService']['GetComparisonEntries'](arg1, arg2);
+}
+
 export function GetDefaultProfile() {
   return window['go']['main']['OSSService']['GetDefaultProfile']();
 }
 
+export function GetFolderIndexStatus(arg1, arg2, arg3) {
+  return window['go']['main']['OSSService']['GetFolderIndexStatus'](arg1, arg2, arg3);
+}
+
+export function GetFolderStats(arg1, arg2, arg3) {
+  return window['go']['main']['OSSService']['GetFolderStats'](arg1, arg2, arg3);
+}
+
+export function GetObjectHexDump(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['OSSService']['GetObjectHexDump'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function GetObjectInfo(arg1, arg2, arg3) {
+  return window['go']['main']['OSSService']['GetObjectInfo'](arg1, arg2, arg3);
+}
+
+export function GetObjectRange(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['OSSService']['GetObjectRange'](arg1, arg2, arg3, arg4, arg5);
+}
+
 export function GetObjectText(arg1, arg2, arg3, arg4) {
   return window['go']['main']['OSSService']['GetObjectText'](arg1, arg2, arg3, arg4);
 }
 
+export function GetObjectVersionText(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['OSSService']['GetObjectVersionText'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function GetOperationJournal(arg1) {
+  return window['go']['main']['OSSService']['GetOperationJournal'](arg1);
+}
+
 export function GetOssutilPath() {
   return window['go']['main']['OSSService']['GetOssutilPath']();
 }
@@ -66,18 +182,50 @@ export function GetProfile(arg1) {
   return window['go']['main']['OSSService']['GetProfile'](arg1);
 }
 
+export function GetRecycleBinSettings(arg1) {
+  return window['go']['main']['OSSService']['GetRecycleBinSettings'](arg1);
+}
+
 export function GetSettings() {
   return window['go']['main']['OSSService']['GetSettings']();
 }
 
+export function GetThumbnails(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['OSSService']['GetThumbnails'](arg1, arg2, arg3, arg4);
+}
+
 export function GetTransferHistory() {
   return window['go']['main']['OSSService']['GetTransferHistory']();
 }
 
+export function GetWebDAVStatus() {
+  return window['go']['main']['OSSService']['GetWebDAVStatus']();
+}
+
+export function IsOfflineMode() {
+  return window['go']['main']['OSSService']['IsOfflineMode']();
+}
+
+export function ListArchiveEntries(arg1, arg2, arg3) {
+  return window['go']['main']['OSSService']['ListArchiveEntries'](arg1, arg2, arg3);
+}
+
 export function ListBuckets(arg1) {
   return window['go']['main']['OSSService']['ListBuckets'](arg1);
 }
 
+export function ListIndexedObjectsPage(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['OSSService']['ListIndexedObjectsPage'](arg1, arg2, arg3, arg4);
+}
+
+export function ListObjectKeyVersions(arg1, arg2, arg3) {
+  return window['go']['main']['OSSService']['ListObjectKeyVersions'](arg1, arg2, arg3);
+}
+
+export function ListObjectVersionsPage(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['main']['OSSService']['ListObjectVersionsPage'](arg1, arg2, arg3, arg4, arg5, arg6);
+}
+
 export function ListObjects(arg1, arg2, arg3) {
   return window['go']['main']['OSSService']['ListObjects'](arg1, arg2, arg3);
 }
@@ -86,6 +234,22 @@ export function ListObjectsPage(arg1, arg2, arg3, arg4, arg5) {
   return window['go']['main']['OSSService']['ListObjectsPage'](arg1, arg2, arg3, arg4, arg5);
 }
 
+export function ListObjectsPageCached(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['OSSService']['ListObjectsPageCached'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function ListShareLinks(arg1, arg2) {
+  return window['go']['main']['OSSService']['ListShareLinks'](arg1, arg2);
+}
+
+export function ListTextEncodings() {
+  return window['go']['main']['OSSService']['ListTextEncodings']();
+}
+
+export function ListTrash(arg1, arg2) {
+  return window['go']['main']['OSSService']['ListTrash'](arg1, arg2);
+}
+
 export function LoadProfiles() {
   return window['go']['main']['OSSService']['LoadProfiles']();
 }
@@ -94,34 +258,138 @@ export function MoveObject(arg1, arg2, arg3, arg4, arg5) {
   return window['go']['main']['OSSService']['MoveObject'](arg1, arg2, arg3, arg4, arg5);
 }
 
+export function OpenObjectText(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['OSSService']['OpenObjectText'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function PresignFolder(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['OSSService']['PresignFolder'](arg1, arg2, arg3, arg4, arg5);
+}
+
 export function PresignObject(arg1, arg2, arg3, arg4) {
   return window['go']['main']['OSSService']['PresignObject'](arg1, arg2, arg3, arg4);
 }
 
-export function PutObjectText(arg1, arg2, arg3, arg4) {
-  return window['go']['main']['OSSService']['PutObjectText'](arg1, arg2, arg3, arg4);
+export function PresignObjectVersion(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['OSSService']['PresignObjectVersion'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function PresignObjectWithOptions(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['OSSService']['PresignObjectWithOptions'](arg1, arg2, arg3, arg4);
+}
+
+export function PurgeTrash(arg1, arg2, arg3) {
+  return window['go']['main']['OSSService']['PurgeTrash'](arg1, arg2, arg3);
+}
+
+export function PutObjectText(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['main']['OSSService']['PutObjectText'](arg1, arg2, arg3, arg4, arg5, arg6);
+}
+
+export function ReadObjectLines(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['main']['OSSService']['ReadObjectLines'](arg1, arg2, arg3, arg4, arg5, arg6);
+}
+
+export function RefreshFolderStats(arg1, arg2, arg3) {
+  return window['go']['main']['OSSService']['RefreshFolderStats'](arg1, arg2, arg3);
+}
+
+export function ResolveSymlink(arg1, arg2, arg3) {
+  return window['go']['main']['OSSService']['ResolveSymlink'](arg1, arg2, arg3);
+}
+
+export function RestoreObjectVersion(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['OSSService']['RestoreObjectVersion'](arg1, arg2, arg3, arg4);
+}
+
+export function RestoreObjects(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['OSSService']['RestoreObjects'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function RestoreTrashItems(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['OSSService']['RestoreTrashItems'](arg1, arg2, arg3, arg4);
+}
+
+export function ResumeCopyTransfer(arg1) {
+  return window['go']['main']['OSSService']['ResumeCopyTransfer'](arg1);
+}
+
+export function SanitizeObjectKeys(arg1, arg2, arg3) {
+  return window['go']['main']['OSSService']['SanitizeObjectKeys'](arg1, arg2, arg3);
+}
+
+export function SaveObjectText(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['OSSService']['SaveObjectText'](arg1, arg2, arg3, arg4, arg5);
 }
 
 export function SaveProfile(arg1) {
   return window['go']['main']['OSSService']['SaveProfile'](arg1);
 }
 
+export function SaveRecycleBinSettings(arg1, arg2) {
+  return window['go']['main']['OSSService']['SaveRecycleBinSettings'](arg1, arg2);
+}
+
 export function SaveSettings(arg1) {
   return window['go']['main']['OSSService']['SaveSettings'](arg1);
 }
 
+export function SearchCachedObjects(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['OSSService']['SearchCachedObjects'](arg1, arg2, arg3, arg4);
+}
+
+export function SearchObjects(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['OSSService']['SearchObjects'](arg1, arg2, arg3, arg4);
+}
+
+export function SelectObject(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['main']['OSSService']['SelectObject'](arg1, arg2, arg3, arg4, arg5, arg6);
+}
+
 export function SetContext(arg1) {
   return window['go']['main']['OSSService']['SetContext'](arg1);
 }
 
+export function SetOfflineMode(arg1) {
+  return window['go']['main']['OSSService']['SetOfflineMode'](arg1);
+}
+
 export function SetOssutilPath(arg1) {
   return window['go']['main']['OSSService']['SetOssutilPath'](arg1);
 }
 
+export function ShareObject(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['OSSService']['ShareObject'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function StartWebDAVServer(arg1, arg2) {
+  return window['go']['main']['OSSService']['StartWebDAVServer'](arg1, arg2);
+}
+
+export function StopWebDAVServer() {
+  return window['go']['main']['OSSService']['StopWebDAVServer']();
+}
+
+export function SuggestSafeObjectKey(arg1) {
+  return window['go']['main']['OSSService']['SuggestSafeObjectKey'](arg1);
+}
+
+export function TailObject(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['OSSService']['TailObject'](arg1, arg2, arg3, arg4, arg5);
+}
+
 export function TestConnection(arg1) {
   return window['go']['main']['OSSService']['TestConnection'](arg1);
 }
 
+export function UndeleteObjects(arg1, arg2, arg3) {
+  return window['go']['main']['OSSService']['UndeleteObjects'](arg1, arg2, arg3);
+}
+
+export function UndoLastOperation(arg1, arg2) {
+  return window['go']['main']['OSSService']['UndoLastOperation'](arg1, arg2);
+}
+
 export function UploadFile(arg1, arg2, arg3, arg4) {
   return window['go']['main']['OSSService']['UploadFile'](arg1, arg2, arg3, arg4);
 }
@@ -42,6 +42,92 @@ export namespace main {
 	        this.fileListViewMode = source["fileListViewMode"];
 	    }
 	}
+	export class ArchiveEntry {
+	    name: string;
+	    size: number;
+	    compressedSize: number;
+	    modifiedMs: number;
+	    isDir: boolean;
+	    method?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ArchiveEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.size = source["size"];
+	        this.compressedSize = source["compressedSize"];
+	        this.modifiedMs = source["modifiedMs"];
+	        this.isDir = source["isDir"];
+	        this.method = source["method"];
+	    }
+	}
+	export class ArchiveEntryContent {
+	    name: string;
+	    size: number;
+	    localPath?: string;
+	    contentType?: string;
+	    text?: string;
+	    dataUrl?: string;
+	    truncated?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ArchiveEntryContent(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.size = source["size"];
+	        this.localPath = source["localPath"];
+	        this.contentType = source["contentType"];
+	        this.text = source["text"];
+	        this.dataUrl = source["dataUrl"];
+	        this.truncated = source["truncated"];
+	    }
+	}
+	export class ArchiveListing {
+	    bucket: string;
+	    key: string;
+	    format: string;
+	    objectSize: number;
+	    entries: ArchiveEntry[];
+	    truncated: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ArchiveListing(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.bucket = source["bucket"];
+	        this.key = source["key"];
+	        this.format = source["format"];
+	        this.objectSize = source["objectSize"];
+	        this.entries = this.convertValues(source["entries"], ArchiveEntry);
+	        this.truncated = source["truncated"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class BucketInfo {
 	    name: string;
 	    region: string;
@@ -53,59 +139,850 @@ export namespace main {
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.name = source["name"];
-	        this.region = source["region"];
-	        this.creationDate = source["creationDate"];
+	        this.name = source["name"];
+	        this.region = source["region"];
+	        this.creationDate = source["creationDate"];
+	    }
+	}
+	export class ObjectInfo {
+	    name: string;
+	    path: string;
+	    rawKey?: string;
+	    size: number;
+	    type: string;
+	    lastModified: string;
+	    storageClass: string;
+	    etag?: string;
+	    restoreState?: string;
+	    restoreExpiry?: string;
+	    isSymlink?: boolean;
+	    symlinkTarget?: string;
+	    objectCount?: number;
+	    sizeComputedAtMs?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ObjectInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.path = source["path"];
+	        this.rawKey = source["rawKey"];
+	        this.size = source["size"];
+	        this.type = source["type"];
+	        this.lastModified = source["lastModified"];
+	        this.storageClass = source["storageClass"];
+	        this.etag = source["etag"];
+	        this.restoreState = source["restoreState"];
+	        this.restoreExpiry = source["restoreExpiry"];
+	        this.isSymlink = source["isSymlink"];
+	        this.symlinkTarget = source["symlinkTarget"];
+	        this.objectCount = source["objectCount"];
+	        this.sizeComputedAtMs = source["sizeComputedAtMs"];
+	    }
+	}
+	export class ObjectListPageResult {
+	    items: ObjectInfo[];
+	    nextMarker: string;
+	    isTruncated: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ObjectListPageResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.items = this.convertValues(source["items"], ObjectInfo);
+	        this.nextMarker = source["nextMarker"];
+	        this.isTruncated = source["isTruncated"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CachedObjectListPage {
+	    bucket: string;
+	    prefix: string;
+	    marker: string;
+	    page: ObjectListPageResult;
+	    cached: boolean;
+	    offline?: boolean;
+	    fetchedAtMs: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CachedObjectListPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.bucket = source["bucket"];
+	        this.prefix = source["prefix"];
+	        this.marker = source["marker"];
+	        this.page = this.convertValues(source["page"], ObjectListPageResult);
+	        this.cached = source["cached"];
+	        this.offline = source["offline"];
+	        this.fetchedAtMs = source["fetchedAtMs"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CompareEntry {
+	    path: string;
+	    status: string;
+	    reason?: string;
+	    localSize: number;
+	    remoteSize: number;
+	    localModifiedMs?: number;
+	    remoteModifiedMs?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CompareEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.status = source["status"];
+	        this.reason = source["reason"];
+	        this.localSize = source["localSize"];
+	        this.remoteSize = source["remoteSize"];
+	        this.localModifiedMs = source["localModifiedMs"];
+	        this.remoteModifiedMs = source["remoteModifiedMs"];
+	    }
+	}
+	export class CompareLocationsOptions {
+	    mode: string;
+	    compareMetadata: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new CompareLocationsOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.mode = source["mode"];
+	        this.compareMetadata = source["compareMetadata"];
+	    }
+	}
+	export class ConnectionResult {
+	    success: boolean;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConnectionResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.success = source["success"];
+	        this.message = source["message"];
+	    }
+	}
+	export class FolderIndexPageOptions {
+	    sortBy: string;
+	    descending: boolean;
+	    filter: string;
+	    type: string;
+	    storageClass: string;
+	    offset: number;
+	    limit: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new FolderIndexPageOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.sortBy = source["sortBy"];
+	        this.descending = source["descending"];
+	        this.filter = source["filter"];
+	        this.type = source["type"];
+	        this.storageClass = source["storageClass"];
+	        this.offset = source["offset"];
+	        this.limit = source["limit"];
+	    }
+	}
+	export class FolderIndexStatus {
+	    bucket: string;
+	    prefix: string;
+	    state: string;
+	    refreshing: boolean;
+	    indexedCount: number;
+	    totalCount: number;
+	    builtAtMs?: number;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FolderIndexStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.bucket = source["bucket"];
+	        this.prefix = source["prefix"];
+	        this.state = source["state"];
+	        this.refreshing = source["refreshing"];
+	        this.indexedCount = source["indexedCount"];
+	        this.totalCount = source["totalCount"];
+	        this.builtAtMs = source["builtAtMs"];
+	        this.error = source["error"];
+	    }
+	}
+	export class FolderStats {
+	    taskId?: string;
+	    bucket: string;
+	    prefix: string;
+	    totalBytes: number;
+	    objectCount: number;
+	    storageClassBytes: Record<string, number>;
+	    storageClassCounts: Record<string, number>;
+	    multipartUploadCount: number;
+	    multipartPartCount: number;
+	    multipartBytes: number;
+	    computedAtMs?: number;
+	    cached?: boolean;
+	    done: boolean;
+	    cancelled?: boolean;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FolderStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.taskId = source["taskId"];
+	        this.bucket = source["bucket"];
+	        this.prefix = source["prefix"];
+	        this.totalBytes = source["totalBytes"];
+	        this.objectCount = source["objectCount"];
+	        this.storageClassBytes = source["storageClassBytes"];
+	        this.storageClassCounts = source["storageClassCounts"];
+	        this.multipartUploadCount = source["multipartUploadCount"];
+	        this.multipartPartCount = source["multipartPartCount"];
+	        this.multipartBytes = source["multipartBytes"];
+	        this.computedAtMs = source["computedAtMs"];
+	        this.cached = source["cached"];
+	        this.done = source["done"];
+	        this.cancelled = source["cancelled"];
+	        this.error = source["error"];
+	    }
+	}
+	export class HexDumpLine {
+	    offset: number;
+	    hex: string;
+	    ascii: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new HexDumpLine(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.offset = source["offset"];
+	        this.hex = source["hex"];
+	        this.ascii = source["ascii"];
+	    }
+	}
+	export class HexDumpPage {
+	    bucket: string;
+	    key: string;
+	    offset: number;
+	    length: number;
+	    objectSize: number;
+	    lines: HexDumpLine[];
+	    hasMore: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new HexDumpPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.bucket = source["bucket"];
+	        this.key = source["key"];
+	        this.offset = source["offset"];
+	        this.length = source["length"];
+	        this.objectSize = source["objectSize"];
+	        this.lines = this.convertValues(source["lines"], HexDumpLine);
+	        this.hasMore = source["hasMore"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class IndexedObjectPageResult {
+	    items: ObjectInfo[];
+	    total: number;
+	    offset: number;
+	    status: FolderIndexStatus;
+	
+	    static createFrom(source: any = {}) {
+	        return new IndexedObjectPageResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.items = this.convertValues(source["items"], ObjectInfo);
+	        this.total = source["total"];
+	        this.offset = source["offset"];
+	        this.status = this.convertValues(source["status"], FolderIndexStatus);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class OSSConfig {
+	    accessKeyId: string;
+	    accessKeySecret: string;
+	    region: string;
+	    endpoint: string;
+	    defaultPath: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new OSSConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.accessKeyId = source["accessKeyId"];
+	        this.accessKeySecret = source["accessKeySecret"];
+	        this.region = source["region"];
+	        this.endpoint = source["endpoint"];
+	        this.defaultPath = source["defaultPath"];
+	    }
+	}
+	export class OSSProfile {
+	    name: string;
+	    config: OSSConfig;
+	    isDefault: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new OSSProfile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.config = this.convertValues(source["config"], OSSConfig);
+	        this.isDefault = source["isDefault"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ObjectFormatField {
+	    name: string;
+	    value: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ObjectFormatField(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.value = source["value"];
+	    }
+	}
+	export class ObjectFormatInfo {
+	    bucket: string;
+	    key: string;
+	    objectSize: number;
+	    type: string;
+	    description: string;
+	    fields: ObjectFormatField[];
+	    warnings: string[];
+	    headHex: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ObjectFormatInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.bucket = source["bucket"];
+	        this.key = source["key"];
+	        this.objectSize = source["objectSize"];
+	        this.type = source["type"];
+	        this.description = source["description"];
+	        this.fields = this.convertValues(source["fields"], ObjectFormatField);
+	        this.warnings = source["warnings"];
+	        this.headHex = source["headHex"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class ObjectLinesPage {
+	    bucket: string;
+	    key: string;
+	    lines: string[];
+	    startOffset: number;
+	    endOffset: number;
+	    objectSize: number;
+	    hasMoreBefore: boolean;
+	    hasMoreAfter: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ObjectLinesPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.bucket = source["bucket"];
+	        this.key = source["key"];
+	        this.lines = source["lines"];
+	        this.startOffset = source["startOffset"];
+	        this.endOffset = source["endOffset"];
+	        this.objectSize = source["objectSize"];
+	        this.hasMoreBefore = source["hasMoreBefore"];
+	        this.hasMoreAfter = source["hasMoreAfter"];
+	    }
+	}
+	
+	export class ObjectRangeResult {
+	    bucket: string;
+	    key: string;
+	    offset: number;
+	    length: number;
+	    objectSize: number;
+	    text: string;
+	    eof: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ObjectRangeResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.bucket = source["bucket"];
+	        this.key = source["key"];
+	        this.offset = source["offset"];
+	        this.length = source["length"];
+	        this.objectSize = source["objectSize"];
+	        this.text = source["text"];
+	        this.eof = source["eof"];
+	    }
+	}
+	export class ObjectSearchQuery {
+	    namePattern: string;
+	    nameIsRegex: boolean;
+	    caseSensitive: boolean;
+	    minSize: number;
+	    maxSize: number;
+	    modifiedAfter: string;
+	    modifiedBefore: string;
+	    storageClasses: string[];
+	    extensions: string[];
+	    maxResults: number;
+	    problemKeysOnly: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ObjectSearchQuery(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.namePattern = source["namePattern"];
+	        this.nameIsRegex = source["nameIsRegex"];
+	        this.caseSensitive = source["caseSensitive"];
+	        this.minSize = source["minSize"];
+	        this.maxSize = source["maxSize"];
+	        this.modifiedAfter = source["modifiedAfter"];
+	        this.modifiedBefore = source["modifiedBefore"];
+	        this.storageClasses = source["storageClasses"];
+	        this.extensions = source["extensions"];
+	        this.maxResults = source["maxResults"];
+	        this.problemKeysOnly = source["problemKeysOnly"];
+	    }
+	}
+	export class ObjectTailEvent {
+	    taskId: string;
+	    bucket: string;
+	    key: string;
+	    lines: string[];
+	    startOffset: number;
+	    endOffset: number;
+	    objectSize: number;
+	    reset?: boolean;
+	    skipped?: boolean;
+	    done?: boolean;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ObjectTailEvent(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.taskId = source["taskId"];
+	        this.bucket = source["bucket"];
+	        this.key = source["key"];
+	        this.lines = source["lines"];
+	        this.startOffset = source["startOffset"];
+	        this.endOffset = source["endOffset"];
+	        this.objectSize = source["objectSize"];
+	        this.reset = source["reset"];
+	        this.skipped = source["skipped"];
+	        this.done = source["done"];
+	        this.error = source["error"];
+	    }
+	}
+	export class ObjectTextDocument {
+	    bucket: string;
+	    key: string;
+	    content: string;
+	    etag: string;
+	    versionId?: string;
+	    size: number;
+	    lastModifiedMs: number;
+	    truncated: boolean;
+	    encoding: string;
+	    hasBom: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ObjectTextDocument(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.bucket = source["bucket"];
+	        this.key = source["key"];
+	        this.content = source["content"];
+	        this.etag = source["etag"];
+	        this.versionId = source["versionId"];
+	        this.size = source["size"];
+	        this.lastModifiedMs = source["lastModifiedMs"];
+	        this.truncated = source["truncated"];
+	        this.encoding = source["encoding"];
+	        this.hasBom = source["hasBom"];
+	    }
+	}
+	export class ObjectVersionInfo {
+	    name: string;
+	    path: string;
+	    key: string;
+	    rawKey?: string;
+	    versionId?: string;
+	    type: string;
+	    isLatest: boolean;
+	    isDeleteMarker: boolean;
+	    size: number;
+	    lastModified: string;
+	    storageClass: string;
+	    etag?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ObjectVersionInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.path = source["path"];
+	        this.key = source["key"];
+	        this.rawKey = source["rawKey"];
+	        this.versionId = source["versionId"];
+	        this.type = source["type"];
+	        this.isLatest = source["isLatest"];
+	        this.isDeleteMarker = source["isDeleteMarker"];
+	        this.size = source["size"];
+	        this.lastModified = source["lastModified"];
+	        this.storageClass = source["storageClass"];
+	        this.etag = source["etag"];
+	    }
+	}
+	export class ObjectVersionListPageResult {
+	    items: ObjectVersionInfo[];
+	    nextKeyMarker: string;
+	    nextVersionIdMarker: string;
+	    isTruncated: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ObjectVersionListPageResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.items = this.convertValues(source["items"], ObjectVersionInfo);
+	        this.nextKeyMarker = source["nextKeyMarker"];
+	        this.nextVersionIdMarker = source["nextVersionIdMarker"];
+	        this.isTruncated = source["isTruncated"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ObjectVersionRef {
+	    key: string;
+	    versionId: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ObjectVersionRef(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.versionId = source["versionId"];
 	    }
 	}
-	export class ConnectionResult {
-	    success: boolean;
-	    message: string;
+	export class OperationMove {
+	    from: string;
+	    to: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new ConnectionResult(source);
+	        return new OperationMove(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.success = source["success"];
-	        this.message = source["message"];
+	        this.from = source["from"];
+	        this.to = source["to"];
 	    }
 	}
-	export class OSSConfig {
-	    accessKeyId: string;
-	    accessKeySecret: string;
-	    region: string;
-	    endpoint: string;
-	    defaultPath: string;
+	export class OperationEntry {
+	    id: string;
+	    kind: string;
+	    description: string;
+	    bucket: string;
+	    key: string;
+	    destBucket?: string;
+	    destKey?: string;
+	    moves?: OperationMove[];
+	    deleteMarkers?: ObjectVersionRef[];
+	    trashBucket?: string;
+	    trashKeys?: string[];
+	    objectCount: number;
+	    undoable: boolean;
+	    reason?: string;
+	    createdAtMs: number;
+	    undoneAtMs?: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new OSSConfig(source);
+	        return new OperationEntry(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.accessKeyId = source["accessKeyId"];
-	        this.accessKeySecret = source["accessKeySecret"];
-	        this.region = source["region"];
-	        this.endpoint = source["endpoint"];
-	        this.defaultPath = source["defaultPath"];
+	        this.id = source["id"];
+	        this.kind = source["kind"];
+	        this.description = source["description"];
+	        this.bucket = source["bucket"];
+	        this.key = source["key"];
+	        this.destBucket = source["destBucket"];
+	        this.destKey = source["destKey"];
+	        this.moves = this.convertValues(source["moves"], OperationMove);
+	        this.deleteMarkers = this.convertValues(source["deleteMarkers"], ObjectVersionRef);
+	        this.trashBucket = source["trashBucket"];
+	        this.trashKeys = source["trashKeys"];
+	        this.objectCount = source["objectCount"];
+	        this.undoable = source["undoable"];
+	        this.reason = source["reason"];
+	        this.createdAtMs = source["createdAtMs"];
+	        this.undoneAtMs = source["undoneAtMs"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
-	export class OSSProfile {
+	
+	export class PresignFolderEntry {
 	    name: string;
-	    config: OSSConfig;
-	    isDefault: boolean;
+	    key: string;
+	    size: number;
+	    url: string;
+	    expiresAtMs: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new OSSProfile(source);
+	        return new PresignFolderEntry(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.name = source["name"];
-	        this.config = this.convertValues(source["config"], OSSConfig);
-	        this.isDefault = source["isDefault"];
+	        this.key = source["key"];
+	        this.size = source["size"];
+	        this.url = source["url"];
+	        this.expiresAtMs = source["expiresAtMs"];
+	    }
+	}
+	export class PresignFolderOptions {
+	    format: string;
+	    localPath: string;
+	    uploadKey: string;
+	    customDomain: string;
+	    forceDownload: boolean;
+	    signatureV4: boolean;
+	    maxObjects: number;
+	    note: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PresignFolderOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.format = source["format"];
+	        this.localPath = source["localPath"];
+	        this.uploadKey = source["uploadKey"];
+	        this.customDomain = source["customDomain"];
+	        this.forceDownload = source["forceDownload"];
+	        this.signatureV4 = source["signatureV4"];
+	        this.maxObjects = source["maxObjects"];
+	        this.note = source["note"];
+	    }
+	}
+	export class PresignFolderResult {
+	    bucket: string;
+	    prefix: string;
+	    format: string;
+	    count: number;
+	    totalBytes: number;
+	    expiresAtMs: number;
+	    localPath?: string;
+	    uploadedKey?: string;
+	    entries: PresignFolderEntry[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PresignFolderResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.bucket = source["bucket"];
+	        this.prefix = source["prefix"];
+	        this.format = source["format"];
+	        this.count = source["count"];
+	        this.totalBytes = source["totalBytes"];
+	        this.expiresAtMs = source["expiresAtMs"];
+	        this.localPath = source["localPath"];
+	        this.uploadedKey = source["uploadedKey"];
+	        this.entries = this.convertValues(source["entries"], PresignFolderEntry);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -126,42 +1003,268 @@ export namespace main {
 		    return a;
 		}
 	}
-	export class ObjectInfo {
-	    name: string;
-	    path: string;
-	    size: number;
-	    type: string;
-	    lastModified: string;
-	    storageClass: string;
+	export class PresignOptions {
+	    method: string;
+	    expires: string;
+	    contentType: string;
+	    responseContentType: string;
+	    responseContentDisposition: string;
+	    downloadFilename: string;
+	    process: string;
+	    versionId: string;
+	    trafficLimitKBps: number;
+	    signatureV4: boolean;
 	
 	    static createFrom(source: any = {}) {
-	        return new ObjectInfo(source);
+	        return new PresignOptions(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.name = source["name"];
-	        this.path = source["path"];
-	        this.size = source["size"];
-	        this.type = source["type"];
-	        this.lastModified = source["lastModified"];
-	        this.storageClass = source["storageClass"];
+	        this.method = source["method"];
+	        this.expires = source["expires"];
+	        this.contentType = source["contentType"];
+	        this.responseContentType = source["responseContentType"];
+	        this.responseContentDisposition = source["responseContentDisposition"];
+	        this.downloadFilename = source["downloadFilename"];
+	        this.process = source["process"];
+	        this.versionId = source["versionId"];
+	        this.trafficLimitKBps = source["trafficLimitKBps"];
+	        this.signatureV4 = source["signatureV4"];
 	    }
 	}
-	export class ObjectListPageResult {
-	    items: ObjectInfo[];
-	    nextMarker: string;
-	    isTruncated: boolean;
+	export class PresignResult {
+	    url: string;
+	    method: string;
+	    expiresAtMs: number;
+	    headers?: Record<string, string>;
 	
 	    static createFrom(source: any = {}) {
-	        return new ObjectListPageResult(source);
+	        return new PresignResult(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.items = this.convertValues(source["items"], ObjectInfo);
-	        this.nextMarker = source["nextMarker"];
-	        this.isTruncated = source["isTruncated"];
+	        this.url = source["url"];
+	        this.method = source["method"];
+	        this.expiresAtMs = source["expiresAtMs"];
+	        this.headers = source["headers"];
+	    }
+	}
+	export class RecycleBinSettings {
+	    enabled: boolean;
+	    prefix: string;
+	    bucket: string;
+	    retentionDays: number;
+	    autoPurge: boolean;
+	    lastPurgedAtMs?: number;
+	    lastPurgeMessage?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RecycleBinSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.prefix = source["prefix"];
+	        this.bucket = source["bucket"];
+	        this.retentionDays = source["retentionDays"];
+	        this.autoPurge = source["autoPurge"];
+	        this.lastPurgedAtMs = source["lastPurgedAtMs"];
+	        this.lastPurgeMessage = source["lastPurgeMessage"];
+	    }
+	}
+	export class SaveTextOptions {
+	    expectedEtag: string;
+	    backup: boolean;
+	    backupKey: string;
+	    encoding: string;
+	    bom: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new SaveTextOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.expectedEtag = source["expectedEtag"];
+	        this.backup = source["backup"];
+	        this.backupKey = source["backupKey"];
+	        this.encoding = source["encoding"];
+	        this.bom = source["bom"];
+	    }
+	}
+	export class SaveTextResult {
+	    etag: string;
+	    versionId?: string;
+	    backupKey?: string;
+	    previousVersionId?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SaveTextResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.etag = source["etag"];
+	        this.versionId = source["versionId"];
+	        this.backupKey = source["backupKey"];
+	        this.previousVersionId = source["previousVersionId"];
+	    }
+	}
+	export class SelectInputFormat {
+	    format: string;
+	    fileHeader: string;
+	    fieldDelimiter: string;
+	    recordDelimiter: string;
+	    quoteCharacter: string;
+	    commentCharacter: string;
+	    jsonType: string;
+	    compression: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SelectInputFormat(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.format = source["format"];
+	        this.fileHeader = source["fileHeader"];
+	        this.fieldDelimiter = source["fieldDelimiter"];
+	        this.recordDelimiter = source["recordDelimiter"];
+	        this.quoteCharacter = source["quoteCharacter"];
+	        this.commentCharacter = source["commentCharacter"];
+	        this.jsonType = source["jsonType"];
+	        this.compression = source["compression"];
+	    }
+	}
+	export class SelectOutputOptions {
+	    limit: number;
+	    localPath: string;
+	    targetKey: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SelectOutputOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.limit = source["limit"];
+	        this.localPath = source["localPath"];
+	        this.targetKey = source["targetKey"];
+	    }
+	}
+	export class SelectResult {
+	    bucket: string;
+	    key: string;
+	    format: string;
+	    columns: string[];
+	    rows: string[][];
+	    rowCount: number;
+	    truncated: boolean;
+	    headerDetected: boolean;
+	    localPath?: string;
+	    targetKey?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SelectResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.bucket = source["bucket"];
+	        this.key = source["key"];
+	        this.format = source["format"];
+	        this.columns = source["columns"];
+	        this.rows = source["rows"];
+	        this.rowCount = source["rowCount"];
+	        this.truncated = source["truncated"];
+	        this.headerDetected = source["headerDetected"];
+	        this.localPath = source["localPath"];
+	        this.targetKey = source["targetKey"];
+	    }
+	}
+	export class ShareLink {
+	    id: string;
+	    bucket: string;
+	    key: string;
+	    versionId?: string;
+	    method: string;
+	    url: string;
+	    createdAtMs: number;
+	    expiresAtMs: number;
+	    createdBy: string;
+	    note?: string;
+	    source: string;
+	    expired: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ShareLink(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.bucket = source["bucket"];
+	        this.key = source["key"];
+	        this.versionId = source["versionId"];
+	        this.method = source["method"];
+	        this.url = source["url"];
+	        this.createdAtMs = source["createdAtMs"];
+	        this.expiresAtMs = source["expiresAtMs"];
+	        this.createdBy = source["createdBy"];
+	        this.note = source["note"];
+	        this.source = source["source"];
+	        this.expired = source["expired"];
+	    }
+	}
+	export class StorageClassChangeGroup {
+	    fromClass: string;
+	    toClass: string;
+	    objectCount: number;
+	    totalBytes: number;
+	    reason?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new StorageClassChangeGroup(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.fromClass = source["fromClass"];
+	        this.toClass = source["toClass"];
+	        this.objectCount = source["objectCount"];
+	        this.totalBytes = source["totalBytes"];
+	        this.reason = source["reason"];
+	    }
+	}
+	export class StorageClassChangeEstimate {
+	    targetClass: string;
+	    objectCount: number;
+	    totalBytes: number;
+	    unchangedCount: number;
+	    unchangedBytes: number;
+	    skippedCount: number;
+	    skippedBytes: number;
+	    changes: StorageClassChangeGroup[];
+	    skipped: StorageClassChangeGroup[];
+	
+	    static createFrom(source: any = {}) {
+	        return new StorageClassChangeEstimate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.targetClass = source["targetClass"];
+	        this.objectCount = source["objectCount"];
+	        this.totalBytes = source["totalBytes"];
+	        this.unchangedCount = source["unchangedCount"];
+	        this.unchangedBytes = source["unchangedBytes"];
+	        this.skippedCount = source["skippedCount"];
+	        this.skippedBytes = source["skippedBytes"];
+	        this.changes = this.convertValues(source["changes"], StorageClassChangeGroup);
+	        this.skipped = this.convertValues(source["skipped"], StorageClassChangeGroup);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -182,6 +1285,43 @@ export namespace main {
 		    return a;
 		}
 	}
+	
+	export class ThumbnailRequest {
+	    key: string;
+	    etag: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ThumbnailRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.etag = source["etag"];
+	    }
+	}
+	export class ThumbnailResult {
+	    key: string;
+	    dataUrl?: string;
+	    localPath?: string;
+	    contentType?: string;
+	    cached: boolean;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ThumbnailResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.dataUrl = source["dataUrl"];
+	        this.localPath = source["localPath"];
+	        this.contentType = source["contentType"];
+	        this.cached = source["cached"];
+	        this.error = source["error"];
+	    }
+	}
 	export class TransferUpdate {
 	    id: string;
 	    profileName?: string;
@@ -191,6 +1331,10 @@ export namespace main {
 	    bucket: string;
 	    key: string;
 	    localPath?: string;
+	    versionId?: string;
+	    sourceProfileName?: string;
+	    sourceBucket?: string;
+	    sourceKey?: string;
 	    parentId?: string;
 	    isGroup?: boolean;
 	    fileCount?: number;
@@ -220,6 +1364,10 @@ export namespace main {
 	        this.bucket = source["bucket"];
 	        this.key = source["key"];
 	        this.localPath = source["localPath"];
+	        this.versionId = source["versionId"];
+	        this.sourceProfileName = source["sourceProfileName"];
+	        this.sourceBucket = source["sourceBucket"];
+	        this.sourceKey = source["sourceKey"];
 	        this.parentId = source["parentId"];
 	        this.isGroup = source["isGroup"];
 	        this.fileCount = source["fileCount"];
@@ -236,6 +1384,44 @@ export namespace main {
 	        this.finishedAtMs = source["finishedAtMs"];
 	    }
 	}
+	export class TrashItem {
+	    trashBucket: string;
+	    trashKey: string;
+	    originalBucket: string;
+	    originalKey: string;
+	    originalPath: string;
+	    deletedAtMs: number;
+	    size: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TrashItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.trashBucket = source["trashBucket"];
+	        this.trashKey = source["trashKey"];
+	        this.originalBucket = source["originalBucket"];
+	        this.originalKey = source["originalKey"];
+	        this.originalPath = source["originalPath"];
+	        this.deletedAtMs = source["deletedAtMs"];
+	        this.size = source["size"];
+	    }
+	}
+	export class TrashRestoreResult {
+	    restored: string[];
+	    failed: Record<string, string>;
+	
+	    static createFrom(source: any = {}) {
+	        return new TrashRestoreResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.restored = source["restored"];
+	        this.failed = source["failed"];
+	    }
+	}
 	export class UploadNameCollision {
 	    name: string;
 	    fileExists: boolean;
@@ -266,6 +1452,56 @@ export namespace main {
 	        this.remoteName = source["remoteName"];
 	    }
 	}
+	export class WebDAVOptions {
+	    bucket: string;
+	    prefix: string;
+	    port: number;
+	    username: string;
+	    password: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new WebDAVOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.bucket = source["bucket"];
+	        this.prefix = source["prefix"];
+	        this.port = source["port"];
+	        this.username = source["username"];
+	        this.password = source["password"];
+	    }
+	}
+	export class WebDAVStatus {
+	    running: boolean;
+	    url?: string;
+	    port?: number;
+	    profileName?: string;
+	    bucket?: string;
+	    prefix?: string;
+	    username?: string;
+	    password?: string;
+	    startedAtMs?: number;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new WebDAVStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.running = source["running"];
+	        this.url = source["url"];
+	        this.port = source["port"];
+	        this.profileName = source["profileName"];
+	        this.bucket = source["bucket"];
+	        this.prefix = source["prefix"];
+	        this.username = source["username"];
+	        this.password = source["password"];
+	        this.startedAtMs = source["startedAtMs"];
+	        this.error = source["error"];
+	    }
+	}
 
 }
 
@@ -56,6 +56,7 @@ func main() {
 		},
 		BackgroundColour: &options.RGBA{R: 26, G: 26, B: 46, A: 255},
 		OnStartup:        app.startup,
+		ErrorFormatter:   formatBackendError,
 		DragAndDrop: &options.DragAndDrop{
 			EnableFileDrop: true,
 		},
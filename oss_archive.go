@@ -0,0 +1,418 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// Archives are browsed in place: zip listings come from the central directory at the end of
+// the object and tar listings from the headers between entries, both read with range
+// requests. Extracting an entry fetches only that entry's bytes.
+
+const (
+	archiveBlockSize      = 64 * 1024
+	archiveCachedBlocks   = 32
+	archiveMaxEntries     = 200000
+	archivePreviewMaxSize = 5 * 1024 * 1024
+)
+
+type ArchiveEntry struct {
+	Name           string `json:"name"`
+	Size           int64  `json:"size"`
+	CompressedSize int64  `json:"compressedSize"`
+	ModifiedMs     int64  `json:"modifiedMs"`
+	IsDir          bool   `json:"isDir"`
+	Method         string `json:"method,omitempty"` // "store", "deflate", ... for zip entries
+}
+
+type ArchiveListing struct {
+	Bucket     string         `json:"bucket"`
+	Key        string         `json:"key"`
+	Format     string         `json:"format"` // "zip" or "tar"
+	ObjectSize int64          `json:"objectSize"`
+	Entries    []ArchiveEntry `json:"entries"`
+	Truncated  bool           `json:"truncated"` // More than archiveMaxEntries entries
+}
+
+// ArchiveEntryContent is an extracted entry: written to LocalPath, or previewed as Text for
+// text entries and as DataURL otherwise.
+type ArchiveEntryContent struct {
+	Name        string `json:"name"`
+	Size        int64  `json:"size"`
+	LocalPath   string `json:"localPath,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	Text        string `json:"text,omitempty"`
+	DataURL     string `json:"dataUrl,omitempty"`
+	Truncated   bool   `json:"truncated,omitempty"`
+}
+
+// objectReaderAt reads an object through fixed-size ranged GETs and keeps the most recently
+// used blocks, which turns the many small reads of archive/zip and archive/tar into a few
+// requests.
+type objectReaderAt struct {
+	bkt  *oss.Bucket
+	key  string
+	size int64
+
+	mu     sync.Mutex
+	blocks map[int64][]byte
+	order  []int64
+}
+
+func newObjectReaderAt(bkt *oss.Bucket, key string, size int64) *objectReaderAt {
+	return &objectReaderAt{bkt: bkt, key: key, size: size, blocks: make(map[int64][]byte)}
+}
+
+func (r *objectReaderAt) block(index int64) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if data, ok := r.blocks[index]; ok {
+		return data, nil
+	}
+	start := index * archiveBlockSize
+	end := start + archiveBlockSize
+	if end > r.size {
+		end = r.size
+	}
+	data, err := readObjectRange(r.bkt, r.key, start, end)
+	if err != nil {
+		return nil, err
+	}
+	if len(r.order) >= archiveCachedBlocks {
+		delete(r.blocks, r.order[0])
+		r.order = r.order[1:]
+	}
+	r.blocks[index] = data
+	r.order = append(r.order, index)
+	return data, nil
+}
+
+func (r *objectReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+	n := 0
+	for n < len(p) {
+		pos := off + int64(n)
+		if pos >= r.size {
+			return n, io.EOF
+		}
+		data, err := r.block(pos / archiveBlockSize)
+		if err != nil {
+			return n, err
+		}
+		within := int(pos % archiveBlockSize)
+		if within >= len(data) {
+			return n, io.ErrUnexpectedEOF
+		}
+		n += copy(p[n:], data[within:])
+	}
+	return n, nil
+}
+
+func archiveFormatOf(head []byte, key string) string {
+	if len(head) >= 262 && bytes.Equal(head[257:262], []byte("ustar")) {
+		return "tar"
+	}
+	if bytes.HasPrefix(head, []byte("\x1f\x8b")) || bytes.HasPrefix(head, []byte("BZh")) ||
+		bytes.HasPrefix(head, []byte("\xfd7zXZ\x00")) || bytes.HasPrefix(head, []byte("\x28\xb5\x2f\xfd")) {
+		return "compressed"
+	}
+	if strings.HasSuffix(strings.ToLower(key), ".tar") {
+		return "tar"
+	}
+	return "zip"
+}
+
+func zipMethodName(method uint16) string {
+	switch method {
+	case zip.Store:
+		return "store"
+	case zip.Deflate:
+		return "deflate"
+	case 12:
+		return "bzip2"
+	case 14:
+		return "lzma"
+	case 93:
+		return "zstd"
+	case 99:
+		return "aes"
+	}
+	return fmt.Sprintf("method %d", method)
+}
+
+type openedArchive struct {
+	bkt    *oss.Bucket
+	key    string
+	size   int64
+	format string
+	reader *objectReaderAt
+}
+
+func (s *OSSService) openArchive(config OSSConfig, bucketName string, key string) (openedArchive, error) {
+	bkt, key, err := s.openObjectForRange(config, bucketName, key)
+	if err != nil {
+		return openedArchive{}, err
+	}
+	info, err := headObjectSize(bkt, key)
+	if err != nil {
+		return openedArchive{}, err
+	}
+	reader := newObjectReaderAt(bkt, key, info.size)
+	headLength := int64(512)
+	if headLength > info.size {
+		headLength = info.size
+	}
+	head := make([]byte, headLength)
+	if _, err := reader.ReadAt(head, 0); err != nil && !errors.Is(err, io.EOF) {
+		return openedArchive{}, err
+	}
+	format := archiveFormatOf(head, key)
+	if format == "compressed" {
+		return openedArchive{}, errors.New("compressed tar archives cannot be browsed without reading them whole")
+	}
+	return openedArchive{bkt: bkt, key: key, size: info.size, format: format, reader: reader}, nil
+}
+
+// walkTar calls fn with every header of a tar archive and the offset of its data.
+func (a openedArchive) walkTar(fn func(header *tar.Header, dataOffset int64) (bool, error)) error {
+	section := io.NewSectionReader(a.reader, 0, a.size)
+	tr := tar.NewReader(section)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar header: %w", err)
+		}
+		dataOffset, _ := section.Seek(0, io.SeekCurrent)
+		more, err := fn(header, dataOffset)
+		if err != nil || !more {
+			return err
+		}
+	}
+}
+
+// ListArchiveEntries lists the entries of a zip or uncompressed tar archive with range reads.
+func (s *OSSService) ListArchiveEntries(config OSSConfig, bucketName string, key string) (ArchiveListing, error) {
+	archive, err := s.openArchive(config, bucketName, key)
+	if err != nil {
+		return ArchiveListing{}, err
+	}
+	listing := ArchiveListing{
+		Bucket:     archive.bkt.BucketName,
+		Key:        objectKeyForClient(archive.key),
+		Format:     archive.format,
+		ObjectSize: archive.size,
+		Entries:    []ArchiveEntry{},
+	}
+
+	if archive.format == "tar" {
+		err = archive.walkTar(func(header *tar.Header, _ int64) (bool, error) {
+			if len(listing.Entries) >= archiveMaxEntries {
+				listing.Truncated = true
+				return false, nil
+			}
+			if header.Typeflag != tar.TypeReg && header.Typeflag != tar.TypeDir {
+				return true, nil
+			}
+			listing.Entries = append(listing.Entries, ArchiveEntry{
+				Name:           header.Name,
+				Size:           header.Size,
+				CompressedSize: header.Size,
+				ModifiedMs:     header.ModTime.UnixMilli(),
+				IsDir:          header.Typeflag == tar.TypeDir,
+			})
+			return true, nil
+		})
+		if err != nil {
+			return ArchiveListing{}, err
+		}
+		return listing, nil
+	}
+
+	zr, err := zip.NewReader(archive.reader, archive.size)
+	if err != nil {
+		return ArchiveListing{}, fmt.Errorf("failed to read zip directory: %w", err)
+	}
+	for _, file := range zr.File {
+		if len(listing.Entries) >= archiveMaxEntries {
+			listing.Truncated = true
+			break
+		}
+		listing.Entries = append(listing.Entries, ArchiveEntry{
+			Name:           file.Name,
+			Size:           int64(file.UncompressedSize64),
+			CompressedSize: int64(file.CompressedSize64),
+			ModifiedMs:     file.Modified.UnixMilli(),
+			IsDir:          file.FileInfo().IsDir(),
+			Method:         zipMethodName(file.Method),
+		})
+	}
+	return listing, nil
+}
+
+// openArchiveEntry returns a reader of the uncompressed data of entry, fetched with a single
+// ranged GET, and its size.
+func (a openedArchive) openArchiveEntry(entry string) (io.ReadCloser, int64, error) {
+	if a.format == "tar" {
+		var found *tar.Header
+		var offset int64
+		err := a.walkTar(func(header *tar.Header, dataOffset int64) (bool, error) {
+			if header.Name == entry && header.Typeflag == tar.TypeReg {
+				found, offset = header, dataOffset
+				return false, nil
+			}
+			return true, nil
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+		if found == nil {
+			return nil, 0, fmt.Errorf("entry not found: %s", entry)
+		}
+		body, err := a.openRange(offset, found.Size)
+		return body, found.Size, err
+	}
+
+	zr, err := zip.NewReader(a.reader, a.size)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read zip directory: %w", err)
+	}
+	for _, file := range zr.File {
+		if file.Name != entry || file.FileInfo().IsDir() {
+			continue
+		}
+		if file.Flags&0x1 != 0 {
+			return nil, 0, errors.New("encrypted zip entries are not supported")
+		}
+		offset, err := file.DataOffset()
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to locate entry: %w", err)
+		}
+		body, err := a.openRange(offset, int64(file.CompressedSize64))
+		if err != nil {
+			return nil, 0, err
+		}
+		var data io.ReadCloser
+		switch file.Method {
+		case zip.Store:
+			data = body
+		case zip.Deflate:
+			data = struct {
+				io.Reader
+				io.Closer
+			}{flate.NewReader(body), body}
+		default:
+			body.Close()
+			return nil, 0, fmt.Errorf("unsupported compression: %s", zipMethodName(file.Method))
+		}
+		return &crcCheckingReader{ReadCloser: data, want: file.CRC32, hash: crc32.NewIEEE()}, int64(file.UncompressedSize64), nil
+	}
+	return nil, 0, fmt.Errorf("entry not found: %s", entry)
+}
+
+func (a openedArchive) openRange(offset int64, length int64) (io.ReadCloser, error) {
+	if length == 0 {
+		return io.NopCloser(bytes.NewReader(nil)), nil
+	}
+	body, err := a.bkt.GetObject(a.key, oss.Range(offset, offset+length-1))
+	if err != nil {
+		return nil, fmt.Errorf("range read failed: %w", err)
+	}
+	return body, nil
+}
+
+// crcCheckingReader fails the final read of a zip entry whose data does not match its CRC-32.
+type crcCheckingReader struct {
+	io.ReadCloser
+	want uint32
+	hash interface {
+		io.Writer
+		Sum32() uint32
+	}
+}
+
+func (r *crcCheckingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.hash.Write(p[:n])
+	if errors.Is(err, io.EOF) && r.want != 0 && r.hash.Sum32() != r.want {
+		return n, errors.New("entry checksum mismatch; the archive is corrupted")
+	}
+	return n, err
+}
+
+// ExtractArchiveEntry fetches a single entry of a zip or uncompressed tar archive. With
+// localPath set the entry is written there; otherwise up to 5 MB of it is returned as a
+// preview.
+func (s *OSSService) ExtractArchiveEntry(config OSSConfig, bucketName string, key string, entry string, localPath string) (ArchiveEntryContent, error) {
+	if entry == "" {
+		return ArchiveEntryContent{}, errors.New("entry name is required")
+	}
+	archive, err := s.openArchive(config, bucketName, key)
+	if err != nil {
+		return ArchiveEntryContent{}, err
+	}
+	body, size, err := archive.openArchiveEntry(entry)
+	if err != nil {
+		return ArchiveEntryContent{}, err
+	}
+	defer body.Close()
+	content := ArchiveEntryContent{Name: entry, Size: size}
+
+	localPath = strings.TrimSpace(localPath)
+	if localPath != "" {
+		if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+			return ArchiveEntryContent{}, fmt.Errorf("create local directory failed: %w", err)
+		}
+		tmpPath := localPath + ".part"
+		file, err := os.Create(tmpPath)
+		if err != nil {
+			return ArchiveEntryContent{}, fmt.Errorf("extract failed: %w", err)
+		}
+		_, copyErr := io.Copy(file, body)
+		closeErr := file.Close()
+		if copyErr == nil {
+			copyErr = closeErr
+		}
+		if copyErr != nil {
+			_ = os.Remove(tmpPath)
+			return ArchiveEntryContent{}, fmt.Errorf("extract failed: %w", copyErr)
+		}
+		if err := os.Rename(tmpPath, localPath); err != nil {
+			_ = os.Remove(tmpPath)
+			return ArchiveEntryContent{}, fmt.Errorf("extract failed: %w", err)
+		}
+		content.LocalPath = localPath
+		return content, nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, archivePreviewMaxSize))
+	if err != nil {
+		return ArchiveEntryContent{}, fmt.Errorf("extract failed: %w", err)
+	}
+	content.Truncated = int64(len(data)) < size
+	content.ContentType = http.DetectContentType(data)
+	if strings.HasPrefix(content.ContentType, "text/") {
+		content.Text = strings.ToValidUTF8(string(data), "\uFFFD")
+	} else {
+		content.DataURL = "data:" + content.ContentType + ";base64," + base64.StdEncoding.EncodeToString(data)
+	}
+	return content, nil
+}
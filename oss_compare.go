@@ -0,0 +1,542 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc64"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// CompareLocalAndRemote diffs a local folder against an OSS prefix. Files are matched by
+// their path relative to both roots; the result streams as "compare:update" events and is
+// kept until the next comparisons so it can be exported or turned into transfers.
+
+const (
+	compareModeSizeMtime = "size-mtime"
+	compareModeCRC64     = "crc64"
+
+	compareStatusLocalOnly  = "local-only"
+	compareStatusRemoteOnly = "remote-only"
+	compareStatusIdentical  = "identical"
+	compareStatusDifferent  = "different"
+	// Unverified pairs have the same size but no checksum to compare their content with.
+	compareStatusUnverified = "unverified"
+
+	compareListWorkers     = 8
+	compareHashWorkers     = 4
+	comparePageSize        = 500
+	compareEmitInterval    = 300 * time.Millisecond
+	compareKeptResults     = 4
+	compareMtimeToleranceS = 2
+)
+
+// CompareEntry is one file found on either side. Reason says why a pair is different:
+// "size", "mtime" (the local file changed after the upload) or "crc64", or why it is
+// unverified: "no-crc64" (the object has no usable CRC-64).
+type CompareEntry struct {
+	Path             string `json:"path"`
+	Status           string `json:"status"`
+	Reason           string `json:"reason,omitempty"`
+	LocalSize        int64  `json:"localSize"`
+	RemoteSize       int64  `json:"remoteSize"`
+	LocalModifiedMs  int64  `json:"localModifiedMs,omitempty"`
+	RemoteModifiedMs int64  `json:"remoteModifiedMs,omitempty"`
+}
+
+type CompareSummary struct {
+	LocalOnly       int   `json:"localOnly"`
+	RemoteOnly      int   `json:"remoteOnly"`
+	Identical       int   `json:"identical"`
+	Different       int   `json:"different"`
+	Unverified      int   `json:"unverified"`
+	LocalOnlyBytes  int64 `json:"localOnlyBytes"`
+	RemoteOnlyBytes int64 `json:"remoteOnlyBytes"`
+	DifferentBytes  int64 `json:"differentBytes"`
+}
+
+// CompareUpdate is streamed while a comparison runs. Entries holds the entries classified
+// since the previous update; Summary always covers everything so far.
+type CompareUpdate struct {
+	TaskID        string         `json:"taskId"`
+	Entries       []CompareEntry `json:"entries"`
+	Summary       CompareSummary `json:"summary"`
+	ScannedLocal  int            `json:"scannedLocal"`
+	ScannedRemote int            `json:"scannedRemote"`
+	Done          bool           `json:"done"`
+	Cancelled     bool           `json:"cancelled"`
+	Error         string         `json:"error,omitempty"`
+}
+
+type folderComparison struct {
+	taskID      string
+	profileName string
+	bucket      string
+	prefix      string
+	localDir    string
+	done        bool
+	entries     []CompareEntry
+}
+
+type compareLocalFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+func normalizeCompareMode(mode string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", compareModeSizeMtime:
+		return compareModeSizeMtime, nil
+	case compareModeCRC64:
+		return compareModeCRC64, nil
+	}
+	return "", fmt.Errorf("unsupported compare mode: %s", mode)
+}
+
+// CompareLocalAndRemote starts comparing localDir with prefix in bucketName and returns the
+// task ID. mode is "size-mtime" (default), which is quick but trusts timestamps, or "crc64",
+// which hashes local files of matching size against the CRC-64 OSS stores for each object.
+func (s *OSSService) CompareLocalAndRemote(config OSSConfig, localDir string, bucketName string, prefix string, mode string) (string, error) {
+	mode, err := normalizeCompareMode(mode)
+	if err != nil {
+		return "", err
+	}
+	localDir = strings.TrimSpace(localDir)
+	if localDir == "" {
+		return "", errors.New("local folder is empty")
+	}
+	localDir, err = filepath.Abs(localDir)
+	if err != nil {
+		return "", fmt.Errorf("invalid local folder: %w", err)
+	}
+	info, err := os.Stat(localDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to open local folder: %w", err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a folder", localDir)
+	}
+	bkt, err := s.openBucket(config, bucketName)
+	if err != nil {
+		return "", err
+	}
+
+	taskID, ctx := s.startTask("compare")
+	comparison := &folderComparison{
+		taskID:      taskID,
+		profileName: s.resolveTransferProfileName(config),
+		bucket:      bkt.BucketName,
+		prefix:      normalizeObjectPrefix(prefix),
+		localDir:    localDir,
+	}
+	s.keepComparison(comparison)
+	go s.runComparison(ctx, bkt, comparison, mode)
+	return taskID, nil
+}
+
+func (s *OSSService) keepComparison(comparison *folderComparison) {
+	s.compareMu.Lock()
+	defer s.compareMu.Unlock()
+	s.comparisons = append(s.comparisons, comparison)
+	if len(s.comparisons) > compareKeptResults {
+		s.comparisons = s.comparisons[len(s.comparisons)-compareKeptResults:]
+	}
+}
+
+// finishedComparison returns a copy of a completed comparison.
+func (s *OSSService) finishedComparison(taskID string) (folderComparison, error) {
+	taskID = strings.TrimSpace(taskID)
+	s.compareMu.Lock()
+	defer s.compareMu.Unlock()
+	for _, comparison := range s.comparisons {
+		if comparison.taskID != taskID {
+			continue
+		}
+		if !comparison.done {
+			return folderComparison{}, errors.New("comparison is still running")
+		}
+		return *comparison, nil
+	}
+	return folderComparison{}, errors.New("comparison not found; run it again")
+}
+
+func scanCompareLocalDir(ctx context.Context, root string, fn func(compareLocalFile)) error {
+	return filepath.WalkDir(root, func(current string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		relative, err := filepath.Rel(root, current)
+		if err != nil {
+			return err
+		}
+		fn(compareLocalFile{path: filepath.ToSlash(relative), size: info.Size(), modTime: info.ModTime()})
+		return nil
+	})
+}
+
+func localFileCRC64(name string) (uint64, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	hash := crc64.New(crc64.MakeTable(crc64.ECMA))
+	if _, err := io.Copy(hash, file); err != nil {
+		return 0, err
+	}
+	return hash.Sum64(), nil
+}
+
+// compareByCRC64 returns the status of a local file whose size matches the object:
+// identical or different by CRC-64, or unverified when the object has no usable CRC-64.
+func compareByCRC64(bkt *oss.Bucket, key string, localPath string) (string, error) {
+	header, err := bkt.GetObjectDetailedMeta(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to read object metadata: %w", err)
+	}
+	remoteCRC, err := strconv.ParseUint(header.Get(oss.HTTPHeaderOssCRC64), 10, 64)
+	if err != nil {
+		return compareStatusUnverified, nil
+	}
+	localCRC, err := localFileCRC64(localPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", localPath, err)
+	}
+	if localCRC != remoteCRC {
+		return compareStatusDifferent, nil
+	}
+	return compareStatusIdentical, nil
+}
+
+func (s *OSSService) runComparison(ctx context.Context, bkt *oss.Bucket, comparison *folderComparison, mode string) {
+	defer s.finishTask(comparison.taskID)
+
+	update := CompareUpdate{TaskID: comparison.taskID, Entries: []CompareEntry{}}
+	runner := newCompareRunner(func(entry CompareEntry) {
+		switch entry.Status {
+		case compareStatusLocalOnly:
+			update.Summary.LocalOnly++
+			update.Summary.LocalOnlyBytes += entry.LocalSize
+		case compareStatusRemoteOnly:
+			update.Summary.RemoteOnly++
+			update.Summary.RemoteOnlyBytes += entry.RemoteSize
+		case compareStatusIdentical:
+			update.Summary.Identical++
+		case compareStatusUnverified:
+			update.Summary.Unverified++
+		case compareStatusDifferent:
+			update.Summary.Different++
+			update.Summary.DifferentBytes += entry.LocalSize
+		}
+	}, func(pending []CompareEntry) {
+		update.Entries = pending
+		s.emitEvent("compare:update", update)
+	}, func(entry CompareEntry) string { return entry.Path })
+
+	localFiles := make(map[string]compareLocalFile)
+	remoteObjects := make(map[string]oss.ObjectProperties)
+	var localErr, remoteErr error
+	var scan sync.WaitGroup
+	scan.Add(2)
+	go func() {
+		defer scan.Done()
+		localErr = scanCompareLocalDir(ctx, comparison.localDir, func(file compareLocalFile) {
+			runner.mu.Lock()
+			defer runner.mu.Unlock()
+			localFiles[file.path] = file
+			update.ScannedLocal++
+			runner.emitLocked(false)
+		})
+	}()
+	go func() {
+		defer scan.Done()
+		remoteErr = walkPrefixesParallel(ctx, bkt, comparison.prefix, compareListWorkers, func(object oss.ObjectProperties) {
+			relative := strings.TrimPrefix(object.Key, comparison.prefix)
+			if relative == "" || strings.HasSuffix(relative, "/") {
+				return
+			}
+			runner.mu.Lock()
+			defer runner.mu.Unlock()
+			remoteObjects[relative] = object
+			update.ScannedRemote++
+			runner.emitLocked(false)
+		})
+	}()
+	scan.Wait()
+
+	err := localErr
+	if err != nil {
+		err = fmt.Errorf("failed to scan local folder: %w", err)
+	} else if remoteErr != nil {
+		err = remoteErr
+	}
+
+	if err == nil {
+		// Pairs of equal size that need hashing are checked by a few workers; everything
+		// else is decided from the listings alone.
+		err = runner.classify(ctx, comparePaths(localFiles, remoteObjects), compareHashWorkers, func(relative string) (CompareEntry, bool) {
+			local, hasLocal := localFiles[relative]
+			remote, hasRemote := remoteObjects[relative]
+			entry := CompareEntry{Path: relative}
+			if hasLocal {
+				entry.LocalSize = local.size
+				entry.LocalModifiedMs = local.modTime.UnixMilli()
+			}
+			if hasRemote {
+				entry.RemoteSize = remote.Size
+				entry.RemoteModifiedMs = remote.LastModified.UnixMilli()
+			}
+			switch {
+			case !hasRemote:
+				entry.Status = compareStatusLocalOnly
+			case !hasLocal:
+				entry.Status = compareStatusRemoteOnly
+			case local.size != remote.Size:
+				entry.Status, entry.Reason = compareStatusDifferent, "size"
+			case mode == compareModeCRC64:
+				return entry, true
+			case local.modTime.Unix() > remote.LastModified.Unix()+compareMtimeToleranceS:
+				entry.Status, entry.Reason = compareStatusDifferent, "mtime"
+			default:
+				entry.Status = compareStatusIdentical
+			}
+			return entry, false
+		}, func(entry CompareEntry) (CompareEntry, error) {
+			status, err := compareByCRC64(bkt, comparison.prefix+entry.Path, filepath.Join(comparison.localDir, filepath.FromSlash(entry.Path)))
+			if err != nil {
+				return entry, err
+			}
+			entry.Status = status
+			switch status {
+			case compareStatusDifferent:
+				entry.Reason = "crc64"
+			case compareStatusUnverified:
+				entry.Reason = "no-crc64"
+			}
+			return entry, nil
+		})
+	}
+
+	entries := runner.sortedEntries()
+	s.compareMu.Lock()
+	comparison.entries = entries
+	comparison.done = true
+	s.compareMu.Unlock()
+
+	runner.finish(ctx, err, func(cancelled bool, message string) {
+		update.Done, update.Cancelled, update.Error = true, cancelled, message
+	})
+}
+
+// GetComparisonEntries returns the entries of a finished comparison, optionally only those
+// with the given statuses.
+func (s *OSSService) GetComparisonEntries(taskID string, statuses []string) ([]CompareEntry, error) {
+	comparison, err := s.finishedComparison(taskID)
+	if err != nil {
+		return nil, err
+	}
+	return filterCompareEntries(comparison.entries, statuses), nil
+}
+
+func filterCompareEntries(entries []CompareEntry, statuses []string) []CompareEntry {
+	out := make([]CompareEntry, 0, len(entries))
+	for _, entry := range entries {
+		if len(statuses) == 0 || containsString(statuses, entry.Status) {
+			out = append(out, entry)
+		}
+	}
+	return out
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(strings.TrimSpace(candidate), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExportComparison writes the entries of a finished comparison to localPath as "csv"
+// (default) or "json". Identical files are left out unless includeIdentical is set.
+func (s *OSSService) ExportComparison(taskID string, format string, localPath string, includeIdentical bool) error {
+	comparison, err := s.finishedComparison(taskID)
+	if err != nil {
+		return err
+	}
+	entries := comparison.entries
+	if !includeIdentical {
+		entries = filterCompareEntries(entries, []string{compareStatusLocalOnly, compareStatusRemoteOnly, compareStatusDifferent, compareStatusUnverified})
+	}
+
+	formatMs := func(ms int64) string {
+		if ms == 0 {
+			return ""
+		}
+		return time.UnixMilli(ms).UTC().Format(time.RFC3339)
+	}
+	rows := make([][]string, 0, len(entries))
+	for _, entry := range entries {
+		rows = append(rows, []string{
+			entry.Path,
+			entry.Status,
+			entry.Reason,
+			strconv.FormatInt(entry.LocalSize, 10),
+			strconv.FormatInt(entry.RemoteSize, 10),
+			formatMs(entry.LocalModifiedMs),
+			formatMs(entry.RemoteModifiedMs),
+		})
+	}
+	return writeCompareExport(localPath, format, map[string]any{
+		"localDir": comparison.localDir,
+		"remote":   buildOssPath(comparison.bucket, comparison.prefix),
+		"entries":  entries,
+	}, []string{"path", "status", "reason", "localSize", "remoteSize", "localModified", "remoteModified"}, rows)
+}
+
+// EnqueueComparisonUploads uploads the local-only files of a finished comparison, and the
+// different ones too when includeDifferent is set, as one transfer group.
+func (s *OSSService) EnqueueComparisonUploads(config OSSConfig, taskID string, includeDifferent bool) (string, error) {
+	comparison, err := s.finishedComparison(taskID)
+	if err != nil {
+		return "", err
+	}
+	statuses := []string{compareStatusLocalOnly}
+	if includeDifferent {
+		statuses = append(statuses, compareStatusDifferent)
+	}
+	rootName := filepath.Base(comparison.localDir)
+
+	children := make([]TransferUpdate, 0, 32)
+	totalBytes := int64(0)
+	for _, entry := range filterCompareEntries(comparison.entries, statuses) {
+		children = append(children, TransferUpdate{
+			ID:          s.newTransferID(),
+			Type:        TransferTypeUpload,
+			Status:      TransferStatusQueued,
+			Name:        path.Join(rootName, entry.Path),
+			Bucket:      comparison.bucket,
+			Key:         comparison.prefix + entry.Path,
+			LocalPath:   filepath.Join(comparison.localDir, filepath.FromSlash(entry.Path)),
+			TotalBytes:  entry.LocalSize,
+			UpdatedAtMs: time.Now().UnixMilli(),
+		})
+		totalBytes += entry.LocalSize
+	}
+	if len(children) == 0 {
+		return "", errors.New("nothing to upload")
+	}
+
+	group := TransferUpdate{
+		ID:          s.newTransferID(),
+		ProfileName: comparison.profileName,
+		Type:        TransferTypeUpload,
+		Status:      TransferStatusQueued,
+		Name:        rootName,
+		Bucket:      comparison.bucket,
+		Key:         comparison.prefix,
+		LocalPath:   comparison.localDir,
+		TotalBytes:  totalBytes,
+		FileCount:   len(children),
+		UpdatedAtMs: time.Now().UnixMilli(),
+		IsGroup:     true,
+	}
+	if err := s.enqueueTransferGroup(config, group, children); err != nil {
+		return "", err
+	}
+	return group.ID, nil
+}
+
+// EnqueueComparisonDownloads downloads the remote-only objects of a finished comparison,
+// and the different ones too when includeDifferent is set, as one transfer group.
+func (s *OSSService) EnqueueComparisonDownloads(config OSSConfig, taskID string, includeDifferent bool) (string, error) {
+	comparison, err := s.finishedComparison(taskID)
+	if err != nil {
+		return "", err
+	}
+	statuses := []string{compareStatusRemoteOnly}
+	if includeDifferent {
+		statuses = append(statuses, compareStatusDifferent)
+	}
+	rootName := filepath.Base(comparison.localDir)
+
+	// Files that exist locally keep their names; remote-only objects whose sanitized name
+	// clashes with one of them, or with each other, get a suffix.
+	localPaths := newDownloadPathAllocator()
+	for _, entry := range comparison.entries {
+		if entry.Status != compareStatusRemoteOnly {
+			localPaths.reserve(filepath.FromSlash(entry.Path))
+		}
+	}
+
+	children := make([]TransferUpdate, 0, 32)
+	totalBytes := int64(0)
+	for _, entry := range filterCompareEntries(comparison.entries, statuses) {
+		relativeLocal, err := safeRelativeDownloadPath(sanitizeObjectKey(entry.Path))
+		if err != nil {
+			return "", err
+		}
+		if entry.Status == compareStatusRemoteOnly {
+			relativeLocal = localPaths.allocate(relativeLocal)
+		}
+		localPath := filepath.Join(comparison.localDir, relativeLocal)
+		if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+			return "", fmt.Errorf("prepare local folder failed: %w", err)
+		}
+		children = append(children, TransferUpdate{
+			ID:          s.newTransferID(),
+			Type:        TransferTypeDownload,
+			Status:      TransferStatusQueued,
+			Name:        path.Join(rootName, filepath.ToSlash(relativeLocal)),
+			Bucket:      comparison.bucket,
+			Key:         comparison.prefix + entry.Path,
+			LocalPath:   localPath,
+			TotalBytes:  entry.RemoteSize,
+			UpdatedAtMs: time.Now().UnixMilli(),
+		})
+		totalBytes += entry.RemoteSize
+	}
+	if len(children) == 0 {
+		return "", errors.New("nothing to download")
+	}
+
+	group := TransferUpdate{
+		ID:          s.newTransferID(),
+		ProfileName: comparison.profileName,
+		Type:        TransferTypeDownload,
+		Status:      TransferStatusQueued,
+		Name:        rootName,
+		Bucket:      comparison.bucket,
+		Key:         comparison.prefix,
+		LocalPath:   comparison.localDir,
+		TotalBytes:  totalBytes,
+		FileCount:   len(children),
+		UpdatedAtMs: time.Now().UnixMilli(),
+		IsGroup:     true,
+	}
+	if err := s.enqueueTransferGroup(config, group, children); err != nil {
+		return "", err
+	}
+	return group.ID, nil
+}
@@ -0,0 +1,359 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// CompareLocations diffs two oss:// locations, each opened with its own config so they can
+// belong to different profiles, accounts or regions. Objects are matched by key relative to
+// each prefix; results stream as "compare-locations:update" events.
+
+const (
+	compareModeETag = "etag"
+
+	compareStatusSourceOnly = "source-only"
+	compareStatusTargetOnly = "target-only"
+
+	compareHeadWorkers = 8
+)
+
+// CompareLocationsOptions selects how matching keys are compared. Mode "etag" (default)
+// compares sizes and ETags and falls back to the stored CRC-64 when multipart uploads make
+// the ETags incomparable; "crc64" always compares CRC-64. Pairs left without a comparable
+// checksum are reported as unverified. CompareMetadata also compares
+// the standard headers and user metadata; storage class is not compared.
+type CompareLocationsOptions struct {
+	Mode            string `json:"mode"`
+	CompareMetadata bool   `json:"compareMetadata"`
+}
+
+// LocationCompareEntry is one key found in either location. Reason is "size", "etag",
+// "crc64" or "metadata" for different pairs and "no-crc64" for unverified ones;
+// MetadataDiff names the headers that differ.
+type LocationCompareEntry struct {
+	Path             string   `json:"path"`
+	Status           string   `json:"status"`
+	Reason           string   `json:"reason,omitempty"`
+	SourceSize       int64    `json:"sourceSize"`
+	TargetSize       int64    `json:"targetSize"`
+	SourceETag       string   `json:"sourceEtag,omitempty"`
+	TargetETag       string   `json:"targetEtag,omitempty"`
+	SourceModifiedMs int64    `json:"sourceModifiedMs,omitempty"`
+	TargetModifiedMs int64    `json:"targetModifiedMs,omitempty"`
+	MetadataDiff     []string `json:"metadataDiff,omitempty"`
+}
+
+type LocationCompareSummary struct {
+	SourceOnly      int   `json:"sourceOnly"`
+	TargetOnly      int   `json:"targetOnly"`
+	Identical       int   `json:"identical"`
+	Different       int   `json:"different"`
+	Unverified      int   `json:"unverified"`
+	SourceOnlyBytes int64 `json:"sourceOnlyBytes"`
+	TargetOnlyBytes int64 `json:"targetOnlyBytes"`
+	DifferentBytes  int64 `json:"differentBytes"`
+}
+
+// LocationCompareUpdate is streamed while a comparison runs. Entries holds the entries
+// classified since the previous update; Summary always covers everything so far.
+type LocationCompareUpdate struct {
+	TaskID        string                 `json:"taskId"`
+	Entries       []LocationCompareEntry `json:"entries"`
+	Summary       LocationCompareSummary `json:"summary"`
+	ScannedSource int                    `json:"scannedSource"`
+	ScannedTarget int                    `json:"scannedTarget"`
+	Done          bool                   `json:"done"`
+	Cancelled     bool                   `json:"cancelled"`
+	Error         string                 `json:"error,omitempty"`
+}
+
+type locationComparison struct {
+	taskID     string
+	sourcePath string
+	targetPath string
+	done       bool
+	entries    []LocationCompareEntry
+}
+
+// compareSide is one of the two locations being compared.
+type compareSide struct {
+	bkt     *oss.Bucket
+	prefix  string
+	objects map[string]oss.ObjectProperties
+}
+
+func (s *OSSService) openCompareSide(config OSSConfig, location string) (*compareSide, error) {
+	bucketName, prefix, ok := parseDefaultPathLocation(location)
+	if !ok {
+		return nil, fmt.Errorf("invalid location: %s", location)
+	}
+	bkt, err := s.openBucket(config, bucketName)
+	if err != nil {
+		return nil, err
+	}
+	return &compareSide{bkt: bkt, prefix: normalizeObjectPrefix(prefix), objects: make(map[string]oss.ObjectProperties)}, nil
+}
+
+// CompareLocations starts comparing sourcePath with targetPath, both "oss://bucket/prefix",
+// and returns the task ID.
+func (s *OSSService) CompareLocations(sourceConfig OSSConfig, sourcePath string, targetConfig OSSConfig, targetPath string, options CompareLocationsOptions) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(options.Mode)) {
+	case "", compareModeETag:
+		options.Mode = compareModeETag
+	case compareModeCRC64:
+		options.Mode = compareModeCRC64
+	default:
+		return "", fmt.Errorf("unsupported compare mode: %s", options.Mode)
+	}
+	source, err := s.openCompareSide(sourceConfig, sourcePath)
+	if err != nil {
+		return "", fmt.Errorf("source: %w", err)
+	}
+	target, err := s.openCompareSide(targetConfig, targetPath)
+	if err != nil {
+		return "", fmt.Errorf("target: %w", err)
+	}
+
+	taskID, ctx := s.startTask("compare-locations")
+	comparison := &locationComparison{
+		taskID:     taskID,
+		sourcePath: buildOssPath(source.bkt.BucketName, source.prefix),
+		targetPath: buildOssPath(target.bkt.BucketName, target.prefix),
+	}
+	s.compareMu.Lock()
+	s.locationComparisons = append(s.locationComparisons, comparison)
+	if len(s.locationComparisons) > compareKeptResults {
+		s.locationComparisons = s.locationComparisons[len(s.locationComparisons)-compareKeptResults:]
+	}
+	s.compareMu.Unlock()
+
+	go s.runLocationComparison(ctx, source, target, comparison, options)
+	return taskID, nil
+}
+
+// objectMetadataDiff returns the standard and user metadata headers that differ.
+func objectMetadataDiff(source http.Header, target http.Header) []string {
+	names := map[string]struct{}{
+		oss.HTTPHeaderContentType:        {},
+		oss.HTTPHeaderCacheControl:       {},
+		oss.HTTPHeaderContentDisposition: {},
+		oss.HTTPHeaderContentEncoding:    {},
+		oss.HTTPHeaderContentLanguage:    {},
+		oss.HTTPHeaderExpires:            {},
+	}
+	for _, header := range []http.Header{source, target} {
+		for name := range header {
+			if canonical := http.CanonicalHeaderKey(name); strings.HasPrefix(canonical, oss.HTTPHeaderOssMetaPrefix) {
+				names[canonical] = struct{}{}
+			}
+		}
+	}
+	diff := make([]string, 0)
+	for name := range names {
+		if source.Get(name) != target.Get(name) {
+			diff = append(diff, name)
+		}
+	}
+	sort.Strings(diff)
+	return diff
+}
+
+// compareObjectHeads settles a pair that the listings alone cannot: it compares the stored
+// CRC-64 when both objects have one and, in etag mode, plain MD5 ETags otherwise. A pair
+// with neither is unverified. Metadata is compared last, if asked.
+func compareObjectHeads(source *compareSide, target *compareSide, entry LocationCompareEntry, options CompareLocationsOptions) (LocationCompareEntry, error) {
+	sourceHeader, err := source.bkt.GetObjectDetailedMeta(source.prefix + entry.Path)
+	if err != nil {
+		return entry, fmt.Errorf("failed to read source metadata: %w", err)
+	}
+	targetHeader, err := target.bkt.GetObjectDetailedMeta(target.prefix + entry.Path)
+	if err != nil {
+		return entry, fmt.Errorf("failed to read target metadata: %w", err)
+	}
+	sourceCRC := sourceHeader.Get(oss.HTTPHeaderOssCRC64)
+	targetCRC := targetHeader.Get(oss.HTTPHeaderOssCRC64)
+	multipart := strings.Contains(entry.SourceETag, "-") || strings.Contains(entry.TargetETag, "-")
+	switch {
+	case sourceCRC != "" && targetCRC != "":
+		if sourceCRC != targetCRC {
+			entry.Status, entry.Reason = compareStatusDifferent, "crc64"
+			return entry, nil
+		}
+		entry.Status = compareStatusIdentical
+	case options.Mode == compareModeETag && entry.SourceETag == entry.TargetETag:
+		entry.Status = compareStatusIdentical
+	case options.Mode == compareModeETag && !multipart:
+		entry.Status, entry.Reason = compareStatusDifferent, "etag"
+		return entry, nil
+	default:
+		entry.Status, entry.Reason = compareStatusUnverified, "no-crc64"
+	}
+	if options.CompareMetadata {
+		if diff := objectMetadataDiff(sourceHeader, targetHeader); len(diff) > 0 {
+			entry.Status, entry.Reason, entry.MetadataDiff = compareStatusDifferent, "metadata", diff
+		}
+	}
+	return entry, nil
+}
+
+func (s *OSSService) runLocationComparison(ctx context.Context, source *compareSide, target *compareSide, comparison *locationComparison, options CompareLocationsOptions) {
+	defer s.finishTask(comparison.taskID)
+
+	update := LocationCompareUpdate{TaskID: comparison.taskID, Entries: []LocationCompareEntry{}}
+	runner := newCompareRunner(func(entry LocationCompareEntry) {
+		switch entry.Status {
+		case compareStatusSourceOnly:
+			update.Summary.SourceOnly++
+			update.Summary.SourceOnlyBytes += entry.SourceSize
+		case compareStatusTargetOnly:
+			update.Summary.TargetOnly++
+			update.Summary.TargetOnlyBytes += entry.TargetSize
+		case compareStatusIdentical:
+			update.Summary.Identical++
+		case compareStatusUnverified:
+			update.Summary.Unverified++
+		case compareStatusDifferent:
+			update.Summary.Different++
+			update.Summary.DifferentBytes += entry.SourceSize
+		}
+	}, func(pending []LocationCompareEntry) {
+		update.Entries = pending
+		s.emitEvent("compare-locations:update", update)
+	}, func(entry LocationCompareEntry) string { return entry.Path })
+
+	errs := make([]error, 2)
+	var scan sync.WaitGroup
+	for i, side := range []*compareSide{source, target} {
+		i, side := i, side
+		scan.Add(1)
+		go func() {
+			defer scan.Done()
+			errs[i] = walkPrefixesParallel(ctx, side.bkt, side.prefix, compareListWorkers, func(object oss.ObjectProperties) {
+				relative := strings.TrimPrefix(object.Key, side.prefix)
+				if relative == "" || strings.HasSuffix(relative, "/") {
+					return
+				}
+				runner.mu.Lock()
+				defer runner.mu.Unlock()
+				side.objects[relative] = object
+				if side == source {
+					update.ScannedSource++
+				} else {
+					update.ScannedTarget++
+				}
+				runner.emitLocked(false)
+			})
+		}()
+	}
+	scan.Wait()
+
+	var err error
+	switch {
+	case errs[0] != nil:
+		err = fmt.Errorf("source: %w", errs[0])
+	case errs[1] != nil:
+		err = fmt.Errorf("target: %w", errs[1])
+	}
+
+	if err == nil {
+		err = runner.classify(ctx, comparePaths(source.objects, target.objects), compareHeadWorkers, func(relative string) (LocationCompareEntry, bool) {
+			sourceObject, inSource := source.objects[relative]
+			targetObject, inTarget := target.objects[relative]
+			entry := LocationCompareEntry{Path: relative}
+			if inSource {
+				entry.SourceSize = sourceObject.Size
+				entry.SourceETag = strings.Trim(sourceObject.ETag, "\"")
+				entry.SourceModifiedMs = sourceObject.LastModified.UnixMilli()
+			}
+			if inTarget {
+				entry.TargetSize = targetObject.Size
+				entry.TargetETag = strings.Trim(targetObject.ETag, "\"")
+				entry.TargetModifiedMs = targetObject.LastModified.UnixMilli()
+			}
+			// A multipart ETag depends on the part size, so two copies of the same content
+			// can disagree; only plain MD5 ETags settle a pair without a HEAD request.
+			multipart := strings.Contains(entry.SourceETag, "-") || strings.Contains(entry.TargetETag, "-")
+			switch {
+			case !inTarget:
+				entry.Status = compareStatusSourceOnly
+			case !inSource:
+				entry.Status = compareStatusTargetOnly
+			case entry.SourceSize != entry.TargetSize:
+				entry.Status, entry.Reason = compareStatusDifferent, "size"
+			case options.Mode == compareModeETag && !options.CompareMetadata && entry.SourceETag == entry.TargetETag:
+				entry.Status = compareStatusIdentical
+			case options.Mode == compareModeETag && !options.CompareMetadata && !multipart:
+				entry.Status, entry.Reason = compareStatusDifferent, "etag"
+			default:
+				return entry, true
+			}
+			return entry, false
+		}, func(entry LocationCompareEntry) (LocationCompareEntry, error) {
+			return compareObjectHeads(source, target, entry, options)
+		})
+	}
+
+	entries := runner.sortedEntries()
+	s.compareMu.Lock()
+	comparison.entries = entries
+	comparison.done = true
+	s.compareMu.Unlock()
+
+	runner.finish(ctx, err, func(cancelled bool, message string) {
+		update.Done, update.Cancelled, update.Error = true, cancelled, message
+	})
+}
+
+// ExportLocationComparison writes the entries of a finished location comparison to
+// localPath as "csv" (default) or "json". Identical objects are left out unless
+// includeIdentical is set.
+func (s *OSSService) ExportLocationComparison(taskID string, format string, localPath string, includeIdentical bool) error {
+	taskID = strings.TrimSpace(taskID)
+	var comparison locationComparison
+	found := false
+	s.compareMu.Lock()
+	for _, candidate := range s.locationComparisons {
+		if candidate.taskID == taskID {
+			comparison, found = *candidate, true
+		}
+	}
+	s.compareMu.Unlock()
+	if !found {
+		return errors.New("comparison not found; run it again")
+	}
+	if !comparison.done {
+		return errors.New("comparison is still running")
+	}
+	entries := make([]LocationCompareEntry, 0, len(comparison.entries))
+	rows := make([][]string, 0, len(comparison.entries))
+	for _, entry := range comparison.entries {
+		if !includeIdentical && entry.Status == compareStatusIdentical {
+			continue
+		}
+		entries = append(entries, entry)
+		rows = append(rows, []string{
+			entry.Path,
+			entry.Status,
+			entry.Reason,
+			strconv.FormatInt(entry.SourceSize, 10),
+			strconv.FormatInt(entry.TargetSize, 10),
+			entry.SourceETag,
+			entry.TargetETag,
+			strings.Join(entry.MetadataDiff, " "),
+		})
+	}
+	return writeCompareExport(localPath, format, map[string]any{
+		"source":  comparison.sourcePath,
+		"target":  comparison.targetPath,
+		"entries": entries,
+	}, []string{"path", "status", "reason", "sourceSize", "targetSize", "sourceEtag", "targetEtag", "metadataDiff"}, rows)
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// compareRunner is what the folder and location comparisons share: entries are classified
+// in path order, pairs that need a request are checked by a few workers, and classified
+// entries stream out in batches until the final update.
+type compareRunner[E any] struct {
+	mu       sync.Mutex
+	pending  []E
+	entries  []E
+	lastEmit time.Time
+	// count adds an entry to the summary and emit sends an update carrying pending; both
+	// run with mu held.
+	count func(entry E)
+	emit  func(pending []E)
+	path  func(entry E) string
+}
+
+func newCompareRunner[E any](count func(E), emit func([]E), path func(E) string) *compareRunner[E] {
+	return &compareRunner[E]{
+		pending:  []E{},
+		entries:  make([]E, 0, 256),
+		lastEmit: time.Now(),
+		count:    count,
+		emit:     emit,
+		path:     path,
+	}
+}
+
+func (r *compareRunner[E]) emitLocked(force bool) {
+	if !force && len(r.pending) < comparePageSize && time.Since(r.lastEmit) < compareEmitInterval {
+		return
+	}
+	r.emit(r.pending)
+	r.pending = []E{}
+	r.lastEmit = time.Now()
+}
+
+func (r *compareRunner[E]) add(entry E) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count(entry)
+	r.pending = append(r.pending, entry)
+	r.entries = append(r.entries, entry)
+	r.emitLocked(false)
+}
+
+// classify decides every path in order. decide returns the entry and whether it still
+// needs check, which runs on workers goroutines; the first check error is returned.
+func (r *compareRunner[E]) classify(ctx context.Context, paths []string, workers int, decide func(path string) (E, bool), check func(E) (E, error)) error {
+	jobs := make(chan E)
+	var checking sync.WaitGroup
+	var checkErr error
+	for i := 0; i < workers; i++ {
+		checking.Add(1)
+		go func() {
+			defer checking.Done()
+			for entry := range jobs {
+				entry, err := check(entry)
+				if err != nil {
+					r.mu.Lock()
+					if checkErr == nil {
+						checkErr = err
+					}
+					r.mu.Unlock()
+					continue
+				}
+				r.add(entry)
+			}
+		}()
+	}
+
+	for _, relative := range paths {
+		if ctx.Err() != nil {
+			break
+		}
+		entry, needsCheck := decide(relative)
+		if !needsCheck {
+			r.add(entry)
+			continue
+		}
+		select {
+		case jobs <- entry:
+		case <-ctx.Done():
+		}
+	}
+	close(jobs)
+	checking.Wait()
+	return checkErr
+}
+
+// sortedEntries returns every entry classified so far in path order.
+func (r *compareRunner[E]) sortedEntries() []E {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sort.Slice(r.entries, func(i, j int) bool { return r.path(r.entries[i]) < r.path(r.entries[j]) })
+	return r.entries
+}
+
+// finish sends the final update after done has marked it finished, cancelled or failed.
+func (r *compareRunner[E]) finish(ctx context.Context, err error, done func(cancelled bool, message string)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch {
+	case ctx.Err() != nil:
+		done(true, "")
+	case err != nil:
+		done(false, err.Error())
+	default:
+		done(false, "")
+	}
+	r.emitLocked(true)
+}
+
+// comparePaths returns the relative paths found on either side, sorted.
+func comparePaths[A any, B any](left map[string]A, right map[string]B) []string {
+	paths := make([]string, 0, len(left)+len(right))
+	for relative := range left {
+		paths = append(paths, relative)
+	}
+	for relative := range right {
+		if _, ok := left[relative]; !ok {
+			paths = append(paths, relative)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// writeCompareExport writes a comparison to localPath as "csv" (default), a header and a
+// row per entry, or "json", document indented.
+func writeCompareExport(localPath string, format string, document map[string]any, csvHeader []string, csvRows [][]string) error {
+	localPath = strings.TrimSpace(localPath)
+	if localPath == "" {
+		return errors.New("local path is empty")
+	}
+
+	var buf bytes.Buffer
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "json":
+		encoder := json.NewEncoder(&buf)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(document); err != nil {
+			return fmt.Errorf("export failed: %w", err)
+		}
+	case "", "csv":
+		writer := csv.NewWriter(&buf)
+		_ = writer.Write(csvHeader)
+		for _, row := range csvRows {
+			_ = writer.Write(row)
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return fmt.Errorf("export failed: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+		return fmt.Errorf("create local directory failed: %w", err)
+	}
+	if err := os.WriteFile(localPath, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("export failed: %w", err)
+	}
+	return nil
+}
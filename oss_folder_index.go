@@ -0,0 +1,536 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+const (
+	FolderIndexStateBuilding = "building"
+	FolderIndexStateReady    = "ready"
+	FolderIndexStateError    = "error"
+
+	maxFolderIndexes            = 16
+	folderIndexProgressInterval = 250 * time.Millisecond
+	// Indexes unused for this long are dropped.
+	folderIndexIdleExpiry = 30 * time.Minute
+	// More changed children than this are picked up by a full rebuild instead.
+	maxFolderIndexPatchKeys = 100
+)
+
+// FolderIndexStatus describes a whole-folder index. While a refresh runs, the previous
+// entries keep being served and Refreshing is set.
+type FolderIndexStatus struct {
+	Bucket       string `json:"bucket"`
+	Prefix       string `json:"prefix"`
+	State        string `json:"state"`
+	Refreshing   bool   `json:"refreshing"`
+	IndexedCount int    `json:"indexedCount"` // Entries found by the scan in progress
+	TotalCount   int    `json:"totalCount"`   // Entries available for paging
+	BuiltAtMs    int64  `json:"builtAtMs,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// FolderIndexPageOptions selects, filters and sorts a page of an indexed folder.
+type FolderIndexPageOptions struct {
+	SortBy       string `json:"sortBy"` // "name" | "size" | "modified" | "storageClass"
+	Descending   bool   `json:"descending"`
+	Filter       string `json:"filter"` // Case-insensitive name substring
+	Type         string `json:"type"`   // "", "File" or "Folder"
+	StorageClass string `json:"storageClass"`
+	Offset       int    `json:"offset"`
+	Limit        int    `json:"limit"`
+}
+
+type IndexedObjectPageResult struct {
+	Items  []ObjectInfo      `json:"items"`
+	Total  int               `json:"total"` // Entries matching the filter
+	Offset int               `json:"offset"`
+	Status FolderIndexStatus `json:"status"`
+}
+
+type folderIndexEntry struct {
+	Key      string
+	Info     ObjectInfo
+	Modified time.Time
+}
+
+// folderIndex is kept up to date with the app's own changes: the direct children they touch
+// are collected in dirty and re-read one by one, and rebuild asks for a full scan instead.
+type folderIndex struct {
+	mu          sync.Mutex
+	profileName string
+	bkt         *oss.Bucket
+	bucket      string
+	prefix      string
+	entries     []folderIndexEntry
+	ready       bool
+	building    bool
+	patching    bool
+	rebuild     bool
+	dirty       map[string]struct{}
+	scanned     int
+	builtAt     time.Time
+	lastUsedAt  time.Time
+	err         string
+}
+
+func folderIndexID(config OSSConfig, bucket string, prefix string) string {
+	return transferConfigSignature(config) + "\x1e" + bucket + "\x1e" + prefix
+}
+
+func (idx *folderIndex) statusLocked() FolderIndexStatus {
+	status := FolderIndexStatus{
+		Bucket:       idx.bucket,
+		Prefix:       idx.prefix,
+		Refreshing:   idx.building && idx.ready,
+		IndexedCount: idx.scanned,
+		TotalCount:   len(idx.entries),
+		Error:        idx.err,
+	}
+	switch {
+	case idx.ready:
+		status.State = FolderIndexStateReady
+	case idx.building:
+		status.State = FolderIndexStateBuilding
+	default:
+		status.State = FolderIndexStateError
+	}
+	if !idx.builtAt.IsZero() {
+		status.BuiltAtMs = idx.builtAt.UnixMilli()
+	}
+	return status
+}
+
+// folderIndexFor returns the index for a folder, creating it (and evicting the least
+// recently used index) when needed.
+func (s *OSSService) folderIndexFor(config OSSConfig, bucket string, prefix string, create bool) *folderIndex {
+	id := folderIndexID(config, bucket, prefix)
+
+	s.folderIndexMu.Lock()
+	defer s.folderIndexMu.Unlock()
+	if s.folderIndexes == nil {
+		s.folderIndexes = make(map[string]*folderIndex)
+	}
+	if idx, ok := s.folderIndexes[id]; ok {
+		idx.mu.Lock()
+		idx.lastUsedAt = time.Now()
+		idx.mu.Unlock()
+		return idx
+	}
+	if !create {
+		return nil
+	}
+
+	for candidateID, candidate := range s.folderIndexes {
+		candidate.mu.Lock()
+		expired := !candidate.building && !candidate.patching && time.Since(candidate.lastUsedAt) > folderIndexIdleExpiry
+		candidate.mu.Unlock()
+		if expired {
+			delete(s.folderIndexes, candidateID)
+		}
+	}
+	if len(s.folderIndexes) >= maxFolderIndexes {
+		oldestID := ""
+		var oldest time.Time
+		for candidateID, candidate := range s.folderIndexes {
+			candidate.mu.Lock()
+			usedAt, building := candidate.lastUsedAt, candidate.building
+			candidate.mu.Unlock()
+			if building {
+				continue
+			}
+			if oldestID == "" || usedAt.Before(oldest) {
+				oldestID, oldest = candidateID, usedAt
+			}
+		}
+		if oldestID != "" {
+			delete(s.folderIndexes, oldestID)
+		}
+	}
+
+	idx := &folderIndex{bucket: bucket, prefix: prefix, dirty: make(map[string]struct{}), lastUsedAt: time.Now()}
+	s.folderIndexes[id] = idx
+	return idx
+}
+
+// BuildFolderIndex starts indexing every direct child of prefix in the background, or
+// refreshes an existing index. Progress is emitted as "folder-index:update" events.
+func (s *OSSService) BuildFolderIndex(config OSSConfig, bucketName string, prefix string) (FolderIndexStatus, error) {
+	bkt, err := s.openBucket(config, bucketName)
+	if err != nil {
+		return FolderIndexStatus{}, err
+	}
+	prefix = normalizeObjectPrefix(prefix)
+
+	idx := s.folderIndexFor(config, bkt.BucketName, prefix, true)
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.profileName = s.resolveTransferProfileName(config)
+	idx.bkt = bkt
+	if !idx.building {
+		idx.building = true
+		idx.scanned = 0
+		idx.err = ""
+		go s.runFolderIndex(idx, bkt)
+	}
+	return idx.statusLocked(), nil
+}
+
+// GetFolderIndexStatus reports the state of a folder index without starting one.
+func (s *OSSService) GetFolderIndexStatus(config OSSConfig, bucketName string, prefix string) (FolderIndexStatus, error) {
+	bucketName = normalizeTransferBucket(bucketName)
+	prefix = normalizeObjectPrefix(prefix)
+	idx := s.folderIndexFor(config, bucketName, prefix, false)
+	if idx == nil {
+		return FolderIndexStatus{}, errors.New("folder is not indexed")
+	}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	return idx.statusLocked(), nil
+}
+
+func (s *OSSService) runFolderIndex(idx *folderIndex, bkt *oss.Bucket) {
+	prefix := idx.prefix
+	entries := make([]folderIndexEntry, 0, 1024)
+	var lastEmit time.Time
+
+	emitProgress := func() {
+		if time.Since(lastEmit) < folderIndexProgressInterval {
+			return
+		}
+		lastEmit = time.Now()
+		idx.mu.Lock()
+		idx.scanned = len(entries)
+		if !idx.ready {
+			// The first scan is served as it grows; refreshes swap in when complete.
+			idx.entries = entries
+		}
+		status := idx.statusLocked()
+		idx.mu.Unlock()
+		s.emitEvent("folder-index:update", status)
+	}
+
+	marker := ""
+	var listErr error
+	for {
+		lor, err := bkt.ListObjects(
+			oss.Prefix(prefix),
+			oss.Delimiter("/"),
+			oss.Marker(marker),
+			oss.MaxKeys(1000),
+		)
+		if err != nil {
+			listErr = fmt.Errorf("failed to list objects: %w", err)
+			break
+		}
+
+		for _, commonPrefix := range lor.CommonPrefixes {
+			relative := strings.TrimSuffix(strings.TrimPrefix(commonPrefix, prefix), "/")
+			if relative == "" || strings.Contains(relative, "/") {
+				continue
+			}
+			entries = append(entries, folderIndexEntry{Key: commonPrefix, Info: ObjectInfo{
+				Name:   relative,
+				Path:   buildOssPath(bkt.BucketName, commonPrefix),
+				RawKey: rawObjectKey(commonPrefix),
+				Type:   "Folder",
+			}})
+		}
+		for _, object := range lor.Objects {
+			relative := strings.TrimPrefix(object.Key, prefix)
+			if relative == "" || strings.Contains(relative, "/") {
+				continue
+			}
+			restoreState, restoreExpiry := objectRestoreState(object.StorageClass, object.RestoreInfo)
+			entries = append(entries, folderIndexEntry{
+				Key: object.Key,
+				Info: ObjectInfo{
+					Name:          relative,
+					Path:          buildOssPath(bkt.BucketName, object.Key),
+					RawKey:        rawObjectKey(object.Key),
+					Size:          object.Size,
+					Type:          "File",
+					LastModified:  formatObjectLastModified(object.LastModified),
+					StorageClass:  object.StorageClass,
+					ETag:          strings.Trim(object.ETag, "\""),
+					RestoreState:  restoreState,
+					RestoreExpiry: restoreExpiry,
+					IsSymlink:     object.Type == ossObjectTypeSymlink,
+				},
+				Modified: object.LastModified,
+			})
+		}
+		emitProgress()
+
+		if !lor.IsTruncated || lor.NextMarker == "" {
+			break
+		}
+		marker = lor.NextMarker
+	}
+
+	idx.mu.Lock()
+	idx.building = false
+	if listErr != nil {
+		// Keep serving the previous index if there is one.
+		idx.err = listErr.Error()
+		if !idx.ready {
+			idx.entries = nil
+		}
+	} else {
+		idx.entries = entries
+		idx.ready = true
+		idx.builtAt = time.Now()
+		idx.err = ""
+	}
+	idx.scanned = len(entries)
+	status := idx.statusLocked()
+	// Changes made while scanning may be missing from the listing.
+	update := !idx.patching && (idx.rebuild || len(idx.dirty) > 0)
+	if update {
+		idx.patching = true
+	}
+	idx.mu.Unlock()
+	s.emitEvent("folder-index:update", status)
+	if update {
+		s.updateFolderIndex(idx)
+	}
+}
+
+// folderIndexChildKey returns the direct child of prefix that key is or lies under.
+func folderIndexChildKey(prefix string, key string) (string, bool) {
+	if key == prefix || !strings.HasPrefix(key, prefix) {
+		return "", false
+	}
+	rest := key[len(prefix):]
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		return prefix + rest[:i+1], true
+	}
+	return key, true
+}
+
+// markFolderIndexesChanged brings the indexes of profileName in bucketName up to date with
+// keys the app changed. Indexes of a folder that was itself changed, or with too many
+// changed children at once, are rebuilt.
+func (s *OSSService) markFolderIndexesChanged(profileName string, bucketName string, keys ...string) {
+	s.folderIndexMu.Lock()
+	indexes := make([]*folderIndex, 0, len(s.folderIndexes))
+	for _, idx := range s.folderIndexes {
+		indexes = append(indexes, idx)
+	}
+	s.folderIndexMu.Unlock()
+
+	for _, idx := range indexes {
+		idx.mu.Lock()
+		if idx.profileName != profileName || idx.bucket != bucketName || idx.bkt == nil {
+			idx.mu.Unlock()
+			continue
+		}
+		for _, key := range keys {
+			key = normalizeObjectKey(key)
+			if strings.HasSuffix(key, "/") && strings.HasPrefix(idx.prefix, key) {
+				idx.rebuild = true
+			} else if child, ok := folderIndexChildKey(idx.prefix, key); ok {
+				idx.dirty[child] = struct{}{}
+			}
+		}
+		if len(idx.dirty) > maxFolderIndexPatchKeys {
+			idx.rebuild = true
+		}
+		update := !idx.building && !idx.patching && (idx.rebuild || len(idx.dirty) > 0)
+		if update {
+			idx.patching = true
+		}
+		idx.mu.Unlock()
+		if update {
+			go s.updateFolderIndex(idx)
+		}
+	}
+}
+
+// updateFolderIndex re-reads the changed children of idx until none are left, falling back
+// to a full rebuild when asked to or when reading one fails.
+func (s *OSSService) updateFolderIndex(idx *folderIndex) {
+	for {
+		idx.mu.Lock()
+		bkt := idx.bkt
+		if idx.rebuild {
+			idx.rebuild = false
+			idx.dirty = make(map[string]struct{})
+			idx.patching = false
+			idx.building = true
+			idx.scanned = 0
+			idx.err = ""
+			idx.mu.Unlock()
+			s.runFolderIndex(idx, bkt)
+			return
+		}
+		if len(idx.dirty) == 0 {
+			idx.patching = false
+			idx.mu.Unlock()
+			return
+		}
+		dirty := idx.dirty
+		idx.dirty = make(map[string]struct{})
+		idx.mu.Unlock()
+
+		found := make([]folderIndexEntry, 0, len(dirty))
+		var readErr error
+		for key := range dirty {
+			entry, exists, err := readFolderIndexEntry(bkt, key)
+			if err != nil {
+				readErr = err
+				break
+			}
+			if exists {
+				found = append(found, entry)
+			}
+		}
+
+		idx.mu.Lock()
+		if readErr != nil {
+			idx.rebuild = true
+			idx.mu.Unlock()
+			continue
+		}
+		// Entries are replaced, not edited, since pages read them without the lock.
+		entries := make([]folderIndexEntry, 0, len(idx.entries)+len(found))
+		for _, entry := range idx.entries {
+			if _, changed := dirty[entry.Key]; !changed {
+				entries = append(entries, entry)
+			}
+		}
+		idx.entries = append(entries, found...)
+		idx.scanned = len(idx.entries)
+		status := idx.statusLocked()
+		idx.mu.Unlock()
+		s.emitEvent("folder-index:update", status)
+	}
+}
+
+// readFolderIndexEntry reads the index entry of one direct child, which may no longer exist.
+func readFolderIndexEntry(bkt *oss.Bucket, key string) (folderIndexEntry, bool, error) {
+	if strings.HasSuffix(key, "/") {
+		lor, err := bkt.ListObjects(oss.Prefix(key), oss.MaxKeys(1))
+		if err != nil {
+			return folderIndexEntry{}, false, fmt.Errorf("failed to list objects: %w", err)
+		}
+		if len(lor.Objects) == 0 && len(lor.CommonPrefixes) == 0 {
+			return folderIndexEntry{}, false, nil
+		}
+		return folderIndexEntry{Key: key, Info: ObjectInfo{
+			Name:   path.Base(key),
+			Path:   buildOssPath(bkt.BucketName, key),
+			RawKey: rawObjectKey(key),
+			Type:   "Folder",
+		}}, true, nil
+	}
+	header, err := bkt.GetObjectDetailedMeta(key)
+	if isObjectNotFound(err) {
+		return folderIndexEntry{}, false, nil
+	}
+	if err != nil {
+		return folderIndexEntry{}, false, fmt.Errorf("failed to read object metadata: %w", err)
+	}
+	return folderIndexEntry{
+		Key:      key,
+		Info:     objectInfoFromHeader(bkt.BucketName, key, header),
+		Modified: objectTargetFromHeader(key, header).LastModified,
+	}, true, nil
+}
+
+func folderIndexLess(sortBy string, a, b folderIndexEntry) bool {
+	switch sortBy {
+	case "size":
+		if a.Info.Size != b.Info.Size {
+			return a.Info.Size < b.Info.Size
+		}
+	case "modified":
+		if !a.Modified.Equal(b.Modified) {
+			return a.Modified.Before(b.Modified)
+		}
+	case "storageClass":
+		if a.Info.StorageClass != b.Info.StorageClass {
+			return a.Info.StorageClass < b.Info.StorageClass
+		}
+	}
+	return a.Info.Name < b.Info.Name
+}
+
+// ListIndexedObjectsPage returns a sorted and filtered page of an indexed folder. Folders are
+// always listed before files, as in ListObjectsPage. During the first scan the page covers
+// the entries indexed so far.
+func (s *OSSService) ListIndexedObjectsPage(config OSSConfig, bucketName string, prefix string, options FolderIndexPageOptions) (IndexedObjectPageResult, error) {
+	bucketName = normalizeTransferBucket(bucketName)
+	prefix = normalizeObjectPrefix(prefix)
+	idx := s.folderIndexFor(config, bucketName, prefix, false)
+	if idx == nil {
+		return IndexedObjectPageResult{}, errors.New("folder is not indexed")
+	}
+
+	idx.mu.Lock()
+	status := idx.statusLocked()
+	entries := idx.entries
+	idx.mu.Unlock()
+
+	filter := strings.ToLower(strings.TrimSpace(options.Filter))
+	matched := make([]folderIndexEntry, 0, len(entries))
+	for _, entry := range entries {
+		if filter != "" && !strings.Contains(strings.ToLower(entry.Info.Name), filter) {
+			continue
+		}
+		if options.Type != "" && entry.Info.Type != options.Type {
+			continue
+		}
+		if options.StorageClass != "" && !strings.EqualFold(entry.Info.StorageClass, options.StorageClass) {
+			continue
+		}
+		matched = append(matched, entry)
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		if a.Info.Type != b.Info.Type {
+			return a.Info.Type == "Folder"
+		}
+		if options.Descending {
+			return folderIndexLess(options.SortBy, b, a)
+		}
+		return folderIndexLess(options.SortBy, a, b)
+	})
+
+	limit := options.Limit
+	if limit <= 0 {
+		limit = 200
+	}
+	offset := options.Offset
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(matched) {
+		offset = len(matched)
+	}
+	end := offset + limit
+	if end > len(matched) {
+		end = len(matched)
+	}
+
+	items := make([]ObjectInfo, 0, end-offset)
+	for _, entry := range matched[offset:end] {
+		items = append(items, entry.Info)
+	}
+	s.fillCachedFolderSizes(config, bucketName, prefix, items)
+	return IndexedObjectPageResult{
+		Items:  items,
+		Total:  len(matched),
+		Offset: offset,
+		Status: status,
+	}, nil
+}
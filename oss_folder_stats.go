@@ -0,0 +1,346 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+const (
+	folderStatsFileName         = "folder-stats.json"
+	folderStatsCacheTTL         = 24 * time.Hour
+	folderStatsProgressInterval = 500 * time.Millisecond
+	folderStatsListWorkers      = 8
+	maxFolderStatsCacheEntries  = 2000
+)
+
+// FolderStats sums everything stored below a prefix, like ossutil du. While a calculation
+// runs the same structure is emitted as "folder-stats:update" with Done unset.
+type FolderStats struct {
+	TaskID               string           `json:"taskId,omitempty"`
+	Bucket               string           `json:"bucket"`
+	Prefix               string           `json:"prefix"`
+	TotalBytes           int64            `json:"totalBytes"`
+	ObjectCount          int64            `json:"objectCount"`
+	StorageClassBytes    map[string]int64 `json:"storageClassBytes"`
+	StorageClassCounts   map[string]int64 `json:"storageClassCounts"`
+	MultipartUploadCount int64            `json:"multipartUploadCount"`
+	MultipartPartCount   int64            `json:"multipartPartCount"`
+	MultipartBytes       int64            `json:"multipartBytes"`
+	ComputedAtMs         int64            `json:"computedAtMs,omitempty"`
+	Cached               bool             `json:"cached,omitempty"`
+	Done                 bool             `json:"done"`
+	Cancelled            bool             `json:"cancelled,omitempty"`
+	Error                string           `json:"error,omitempty"`
+}
+
+type folderStatsStore struct {
+	SchemaVersion int                    `json:"schemaVersion"`
+	Entries       map[string]FolderStats `json:"entries"`
+}
+
+func folderStatsCacheKey(profileName string, bucket string, prefix string) string {
+	return normalizeTransferProfileName(profileName) + "\x1f" + bucket + "\x1f" + prefix
+}
+
+func (s *OSSService) folderStatsPath() string {
+	return filepath.Join(normalizeWorkDirPath(s.configDir, s.defaultConfigDir), folderStatsFileName)
+}
+
+// folderStatsCacheLocked returns the cache for the current work dir, loading it on first use.
+func (s *OSSService) folderStatsCacheLocked() map[string]FolderStats {
+	path := s.folderStatsPath()
+	if s.folderStatsCache != nil && s.folderStatsLoadedPath == path {
+		return s.folderStatsCache
+	}
+
+	s.folderStatsCache = make(map[string]FolderStats)
+	s.folderStatsLoadedPath = path
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return s.folderStatsCache
+	}
+	var store folderStatsStore
+	if err := json.Unmarshal(data, &store); err != nil {
+		return s.folderStatsCache
+	}
+	for key, stats := range store.Entries {
+		s.folderStatsCache[key] = stats
+	}
+	return s.folderStatsCache
+}
+
+func (s *OSSService) persistFolderStatsLocked() error {
+	entries := s.folderStatsCacheLocked()
+	now := time.Now()
+	for key, stats := range entries {
+		if now.Sub(time.UnixMilli(stats.ComputedAtMs)) > folderStatsCacheTTL {
+			delete(entries, key)
+		}
+	}
+	for len(entries) > maxFolderStatsCacheEntries {
+		oldestKey := ""
+		var oldest int64
+		for key, stats := range entries {
+			if oldestKey == "" || stats.ComputedAtMs < oldest {
+				oldestKey, oldest = key, stats.ComputedAtMs
+			}
+		}
+		delete(entries, oldestKey)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.folderStatsLoadedPath), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(folderStatsStore{SchemaVersion: 1, Entries: entries}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.folderStatsLoadedPath, data, 0o600)
+}
+
+// cachedFolderStats returns the cached stats for a prefix if they are younger than the TTL.
+func (s *OSSService) cachedFolderStats(profileName string, bucket string, prefix string) (FolderStats, bool) {
+	if profileName == transferProfileAnonymous {
+		return FolderStats{}, false
+	}
+	s.folderStatsMu.Lock()
+	defer s.folderStatsMu.Unlock()
+	stats, ok := s.folderStatsCacheLocked()[folderStatsCacheKey(profileName, bucket, prefix)]
+	if !ok || time.Since(time.UnixMilli(stats.ComputedAtMs)) > folderStatsCacheTTL {
+		return FolderStats{}, false
+	}
+	stats.TaskID = ""
+	stats.Cached = true
+	return stats, true
+}
+
+func (s *OSSService) storeFolderStats(profileName string, stats FolderStats) {
+	if profileName == transferProfileAnonymous {
+		// Unsaved connections share one name; their stats could not be told apart.
+		return
+	}
+	stats.TaskID = ""
+	stats.Cached = false
+	s.folderStatsMu.Lock()
+	defer s.folderStatsMu.Unlock()
+	s.folderStatsCacheLocked()[folderStatsCacheKey(profileName, stats.Bucket, stats.Prefix)] = stats
+	_ = s.persistFolderStatsLocked()
+}
+
+// fillCachedFolderSizes sets Size and ObjectCount on the folders of a listing of prefix whose
+// stats are cached.
+func (s *OSSService) fillCachedFolderSizes(config OSSConfig, bucket string, prefix string, items []ObjectInfo) {
+	profileName := s.resolveTransferProfileName(config)
+	for i := range items {
+		if items[i].Type != "Folder" {
+			continue
+		}
+		if stats, ok := s.cachedFolderStats(profileName, bucket, prefix+items[i].Name+"/"); ok {
+			items[i].Size = stats.TotalBytes
+			items[i].ObjectCount = stats.ObjectCount
+			items[i].SizeComputedAtMs = stats.ComputedAtMs
+		}
+	}
+}
+
+// GetFolderStats returns the size of everything below prefix. Fresh cached stats are returned
+// immediately with Done set; otherwise a calculation starts in the background and the result
+// carries its TaskID. Progress streams as "folder-stats:update"; CancelTask stops it.
+func (s *OSSService) GetFolderStats(config OSSConfig, bucketName string, prefix string) (FolderStats, error) {
+	return s.folderStats(config, bucketName, prefix, false)
+}
+
+// RefreshFolderStats recalculates the stats of prefix, ignoring the cache.
+func (s *OSSService) RefreshFolderStats(config OSSConfig, bucketName string, prefix string) (FolderStats, error) {
+	return s.folderStats(config, bucketName, prefix, true)
+}
+
+func (s *OSSService) folderStats(config OSSConfig, bucketName string, prefix string, refresh bool) (FolderStats, error) {
+	bkt, err := s.openBucket(config, bucketName)
+	if err != nil {
+		return FolderStats{}, err
+	}
+	prefix = normalizeObjectPrefix(prefix)
+	profileName := s.resolveTransferProfileName(config)
+
+	if !refresh {
+		if stats, ok := s.cachedFolderStats(profileName, bkt.BucketName, prefix); ok {
+			stats.Done = true
+			return stats, nil
+		}
+	}
+
+	taskID, ctx := s.startTask("folder-stats")
+	stats := FolderStats{
+		TaskID:             taskID,
+		Bucket:             bkt.BucketName,
+		Prefix:             prefix,
+		StorageClassBytes:  map[string]int64{},
+		StorageClassCounts: map[string]int64{},
+	}
+	go s.runFolderStats(ctx, profileName, bkt, stats)
+	return stats, nil
+}
+
+func (s *OSSService) runFolderStats(ctx context.Context, profileName string, bkt *oss.Bucket, stats FolderStats) {
+	defer s.finishTask(stats.TaskID)
+
+	var mu sync.Mutex
+	lastEmit := time.Now()
+	snapshotLocked := func() FolderStats {
+		out := stats
+		out.StorageClassBytes = make(map[string]int64, len(stats.StorageClassBytes))
+		for class, bytes := range stats.StorageClassBytes {
+			out.StorageClassBytes[class] = bytes
+		}
+		out.StorageClassCounts = make(map[string]int64, len(stats.StorageClassCounts))
+		for class, count := range stats.StorageClassCounts {
+			out.StorageClassCounts[class] = count
+		}
+		return out
+	}
+	emitProgressLocked := func() {
+		if time.Since(lastEmit) < folderStatsProgressInterval {
+			return
+		}
+		lastEmit = time.Now()
+		s.emitEvent("folder-stats:update", snapshotLocked())
+	}
+
+	err := walkPrefixesParallel(ctx, bkt, stats.Prefix, folderStatsListWorkers, func(object oss.ObjectProperties) {
+		class := objectStorageClassOrDefault(object.StorageClass)
+		mu.Lock()
+		defer mu.Unlock()
+		stats.TotalBytes += object.Size
+		stats.ObjectCount++
+		stats.StorageClassBytes[class] += object.Size
+		stats.StorageClassCounts[class]++
+		emitProgressLocked()
+	})
+	if err == nil {
+		err = sumMultipartParts(ctx, bkt, stats.Prefix, func(uploads int64, parts int64, bytes int64) {
+			mu.Lock()
+			defer mu.Unlock()
+			stats.MultipartUploadCount += uploads
+			stats.MultipartPartCount += parts
+			stats.MultipartBytes += bytes
+			emitProgressLocked()
+		})
+	}
+
+	mu.Lock()
+	stats.Done = true
+	switch {
+	case ctx.Err() != nil:
+		stats.Cancelled = true
+	case err != nil:
+		stats.Error = err.Error()
+	default:
+		stats.ComputedAtMs = time.Now().UnixMilli()
+	}
+	final := snapshotLocked()
+	mu.Unlock()
+
+	if final.ComputedAtMs > 0 {
+		s.storeFolderStats(profileName, final)
+	}
+	s.emitEvent("folder-stats:update", final)
+}
+
+// sumMultipartParts adds up the parts of every unfinished multipart upload below prefix, the
+// storage ossutil du reports as part size. add is called concurrently.
+func sumMultipartParts(ctx context.Context, bkt *oss.Bucket, prefix string, add func(uploads int64, parts int64, bytes int64)) error {
+	uploads := make(chan oss.InitiateMultipartUploadResult)
+	var (
+		wg       sync.WaitGroup
+		errMu    sync.Mutex
+		firstErr error
+	)
+	setErr := func(err error) {
+		errMu.Lock()
+		if firstErr == nil {
+			firstErr = err
+		}
+		errMu.Unlock()
+	}
+
+	for i := 0; i < folderStatsListWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for imur := range uploads {
+				if err := sumUploadedParts(ctx, bkt, imur, add); err != nil {
+					setErr(err)
+				}
+			}
+		}()
+	}
+
+	keyMarker, uploadIDMarker := "", ""
+	for ctx.Err() == nil {
+		lmur, err := bkt.ListMultipartUploads(
+			oss.Prefix(prefix),
+			oss.KeyMarker(keyMarker),
+			oss.UploadIDMarker(uploadIDMarker),
+			oss.MaxUploads(1000),
+		)
+		if err != nil {
+			setErr(fmt.Errorf("failed to list multipart uploads: %w", err))
+			break
+		}
+		for _, upload := range lmur.Uploads {
+			uploads <- oss.InitiateMultipartUploadResult{Bucket: bkt.BucketName, Key: upload.Key, UploadID: upload.UploadID}
+		}
+		if !lmur.IsTruncated {
+			break
+		}
+		keyMarker, uploadIDMarker = lmur.NextKeyMarker, lmur.NextUploadIDMarker
+	}
+	close(uploads)
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return firstErr
+}
+
+func sumUploadedParts(ctx context.Context, bkt *oss.Bucket, imur oss.InitiateMultipartUploadResult, add func(uploads int64, parts int64, bytes int64)) error {
+	partMarker := 0
+	uploadCount := int64(1)
+	for ctx.Err() == nil {
+		lpr, err := bkt.ListUploadedParts(imur, oss.MaxParts(1000), oss.PartNumberMarker(partMarker))
+		if err != nil {
+			var serviceErr oss.ServiceError
+			if errors.As(err, &serviceErr) && serviceErr.StatusCode == 404 {
+				// The upload was completed or aborted since it was listed.
+				return nil
+			}
+			return fmt.Errorf("failed to list parts of %s: %w", imur.Key, err)
+		}
+		var bytes int64
+		for _, part := range lpr.UploadedParts {
+			bytes += int64(part.Size)
+		}
+		add(uploadCount, int64(len(lpr.UploadedParts)), bytes)
+		uploadCount = 0
+
+		if !lpr.IsTruncated {
+			return nil
+		}
+		if partMarker, err = strconv.Atoi(strings.TrimSpace(lpr.NextPartNumberMarker)); err != nil {
+			return nil
+		}
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,467 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// The operation journal records the app's mutations per profile in operation-journal.json
+// in the work dir, with what it takes to reverse them: the keys a move or rename touched,
+// the delete markers a delete left on a versioned bucket, or the recycle bin items it
+// created. Deletes that removed data for good are journaled as not undoable.
+
+const (
+	operationJournalFileName      = "operation-journal.json"
+	operationJournalSchemaVersion = 1
+	maxOperationJournalEntries    = 200
+	// Moves of larger folders are journaled without their keys and cannot be undone.
+	maxOperationJournalMoves = 10000
+
+	OperationKindMove         = "move"
+	OperationKindRename       = "rename"
+	OperationKindCreateFolder = "create-folder"
+	OperationKindCreateFile   = "create-file"
+	OperationKindDelete       = "delete"
+)
+
+// OperationMove is one object moved from From to To.
+type OperationMove struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// OperationEntry is one journaled mutation. Undoable is false with Reason explaining why
+// when the operation cannot be reversed.
+type OperationEntry struct {
+	ID            string             `json:"id"`
+	Kind          string             `json:"kind"`
+	Description   string             `json:"description"`
+	Bucket        string             `json:"bucket"`
+	Key           string             `json:"key"`
+	DestBucket    string             `json:"destBucket,omitempty"`
+	DestKey       string             `json:"destKey,omitempty"`
+	Moves         []OperationMove    `json:"moves,omitempty"`
+	DeleteMarkers []ObjectVersionRef `json:"deleteMarkers,omitempty"`
+	TrashBucket   string             `json:"trashBucket,omitempty"`
+	TrashKeys     []string           `json:"trashKeys,omitempty"`
+	ObjectCount   int                `json:"objectCount"`
+	Undoable      bool               `json:"undoable"`
+	Reason        string             `json:"reason,omitempty"`
+	CreatedAtMs   int64              `json:"createdAtMs"`
+	UndoneAtMs    int64              `json:"undoneAtMs,omitempty"`
+}
+
+type operationJournalStore struct {
+	SchemaVersion int                         `json:"schemaVersion"`
+	Profiles      map[string][]OperationEntry `json:"profiles"`
+}
+
+func (s *OSSService) operationJournalPath() string {
+	return filepath.Join(normalizeWorkDirPath(s.configDir, s.defaultConfigDir), operationJournalFileName)
+}
+
+func (s *OSSService) loadOperationJournalLocked() operationJournalStore {
+	store := operationJournalStore{SchemaVersion: operationJournalSchemaVersion, Profiles: map[string][]OperationEntry{}}
+	data, err := os.ReadFile(s.operationJournalPath())
+	if err != nil {
+		return store
+	}
+	if err := json.Unmarshal(data, &store); err != nil || store.Profiles == nil {
+		store.Profiles = map[string][]OperationEntry{}
+	}
+	store.SchemaVersion = operationJournalSchemaVersion
+	return store
+}
+
+func (s *OSSService) saveOperationJournalLocked(store operationJournalStore) error {
+	path := s.operationJournalPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// recordOperation appends entry to the journal of the profile config belongs to. Only
+// saved profiles have a journal. It is best effort; failing to write it does not fail the
+// operation.
+func (s *OSSService) recordOperation(config OSSConfig, entry OperationEntry) {
+	profileName := s.resolveTransferProfileName(config)
+	if profileName == transferProfileAnonymous {
+		// Unsaved connections share one name, so their operations could not be told apart.
+		return
+	}
+	entry.ID = s.newTransferID()
+	entry.CreatedAtMs = time.Now().UnixMilli()
+	if len(entry.Moves) > maxOperationJournalMoves {
+		entry.Moves = nil
+		entry.Undoable = false
+		entry.Reason = fmt.Sprintf("more than %d objects were moved", maxOperationJournalMoves)
+	}
+
+	mapOperationKeys(&entry, objectKeyForClient)
+
+	s.journalMu.Lock()
+	defer s.journalMu.Unlock()
+	store := s.loadOperationJournalLocked()
+	entries := append(store.Profiles[profileName], entry)
+	if len(entries) > maxOperationJournalEntries {
+		entries = entries[len(entries)-maxOperationJournalEntries:]
+	}
+	store.Profiles[profileName] = entries
+	_ = s.saveOperationJournalLocked(store)
+}
+
+// mapOperationKeys applies fn to every object key of entry. Keys are journaled in their
+// client form so keys that are not valid UTF-8 survive JSON.
+func mapOperationKeys(entry *OperationEntry, fn func(string) string) {
+	entry.Key = fn(entry.Key)
+	entry.DestKey = fn(entry.DestKey)
+	moves := make([]OperationMove, len(entry.Moves))
+	for i, move := range entry.Moves {
+		moves[i] = OperationMove{From: fn(move.From), To: fn(move.To)}
+	}
+	entry.Moves = moves
+	markers := make([]ObjectVersionRef, len(entry.DeleteMarkers))
+	for i, marker := range entry.DeleteMarkers {
+		markers[i] = ObjectVersionRef{Key: fn(marker.Key), VersionID: marker.VersionID}
+	}
+	entry.DeleteMarkers = markers
+	trashKeys := make([]string, len(entry.TrashKeys))
+	for i, key := range entry.TrashKeys {
+		trashKeys[i] = fn(key)
+	}
+	entry.TrashKeys = trashKeys
+}
+
+func newMoveOperation(srcBucket string, srcKey string, destBucket string, destKey string, moves []OperationMove, err error) OperationEntry {
+	srcBucket, destBucket = normalizeTransferBucket(srcBucket), normalizeTransferBucket(destBucket)
+	srcKey, destKey = normalizeObjectKey(srcKey), normalizeObjectKey(destKey)
+	kind := OperationKindMove
+	if srcBucket == destBucket && path.Dir(strings.TrimSuffix(srcKey, "/")) == path.Dir(strings.TrimSuffix(destKey, "/")) {
+		kind = OperationKindRename
+	}
+	entry := OperationEntry{
+		Kind:        kind,
+		Description: fmt.Sprintf("%s %s to %s", kind, buildOssPath(srcBucket, srcKey), buildOssPath(destBucket, destKey)),
+		Bucket:      srcBucket,
+		Key:         srcKey,
+		DestBucket:  destBucket,
+		DestKey:     destKey,
+		Moves:       moves,
+		ObjectCount: len(moves),
+		Undoable:    true,
+	}
+	if err != nil {
+		entry.Description += fmt.Sprintf(" (stopped after %d objects: %v)", len(moves), err)
+	}
+	return entry
+}
+
+// GetOperationJournal returns the journal of the profile config belongs to, newest first.
+// It is empty for connections that are not saved profiles.
+func (s *OSSService) GetOperationJournal(config OSSConfig) ([]OperationEntry, error) {
+	profileName := s.resolveTransferProfileName(config)
+	if profileName == transferProfileAnonymous {
+		return []OperationEntry{}, nil
+	}
+	s.journalMu.Lock()
+	entries := s.loadOperationJournalLocked().Profiles[profileName]
+	s.journalMu.Unlock()
+
+	out := make([]OperationEntry, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		entry.Moves = nil
+		entry.TrashKeys = nil
+		entry.DeleteMarkers = nil
+		out = append(out, entry)
+	}
+	return out, nil
+}
+
+// UndoLastOperation reverses the newest operation of the journal that was not undone yet.
+// It refuses, without changing anything, when the operation cannot be undone, when the
+// objects it touched have changed since, or when operationID is set and names another
+// operation than the one the user confirmed.
+func (s *OSSService) UndoLastOperation(config OSSConfig, operationID string) (OperationEntry, error) {
+	profileName := s.resolveTransferProfileName(config)
+	if profileName == transferProfileAnonymous {
+		return OperationEntry{}, errors.New("undo is only available for saved profiles")
+	}
+	s.journalMu.Lock()
+	entries := s.loadOperationJournalLocked().Profiles[profileName]
+	s.journalMu.Unlock()
+
+	index := -1
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].UndoneAtMs == 0 {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return OperationEntry{}, errors.New("there is nothing to undo")
+	}
+	entry := entries[index]
+	if operationID = strings.TrimSpace(operationID); operationID != "" && entry.ID != operationID {
+		return OperationEntry{}, errors.New("the last operation changed; try again")
+	}
+	if !entry.Undoable {
+		return entry, fmt.Errorf("cannot undo %s: %s", entry.Description, entry.Reason)
+	}
+	if err := s.undoOperation(config, entry); err != nil {
+		return entry, fmt.Errorf("cannot undo %s: %w", entry.Description, err)
+	}
+
+	entry.UndoneAtMs = time.Now().UnixMilli()
+	s.journalMu.Lock()
+	store := s.loadOperationJournalLocked()
+	for i := range store.Profiles[profileName] {
+		if store.Profiles[profileName][i].ID == entry.ID {
+			store.Profiles[profileName][i].UndoneAtMs = entry.UndoneAtMs
+		}
+	}
+	err := s.saveOperationJournalLocked(store)
+	s.journalMu.Unlock()
+	if err != nil {
+		return entry, fmt.Errorf("undone, but failed to update the journal: %w", err)
+	}
+	entry.Moves = nil
+	entry.TrashKeys = nil
+	entry.DeleteMarkers = nil
+	return entry, nil
+}
+
+// undoOperation reverses entry. Its keys are still in client form, which the trash and
+// version APIs expect.
+func (s *OSSService) undoOperation(config OSSConfig, entry OperationEntry) error {
+	switch entry.Kind {
+	case OperationKindMove, OperationKindRename:
+		return s.undoMoves(config, entry)
+	case OperationKindCreateFolder:
+		entry.Key = normalizeObjectKey(entry.Key)
+		bkt, err := s.openBucket(config, entry.Bucket)
+		if err != nil {
+			return err
+		}
+		empty := true
+		err = walkObjectsUnderPrefix(bkt, entry.Key, func(object oss.ObjectProperties) error {
+			if object.Key != entry.Key {
+				empty = false
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list folder objects: %w", err)
+		}
+		if !empty {
+			return errors.New("the folder is no longer empty")
+		}
+		if err := bkt.DeleteObject(entry.Key); err != nil {
+			return fmt.Errorf("delete failed: %w", err)
+		}
+		s.invalidateListingCacheForConfig(config, entry.Bucket, entry.Key)
+		return nil
+	case OperationKindCreateFile:
+		entry.Key = normalizeObjectKey(entry.Key)
+		bkt, err := s.openBucket(config, entry.Bucket)
+		if err != nil {
+			return err
+		}
+		header, err := bkt.GetObjectDetailedMeta(entry.Key)
+		if isObjectNotFound(err) {
+			return errors.New("the file no longer exists")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read object metadata: %w", err)
+		}
+		if size, _ := strconv.ParseInt(header.Get(oss.HTTPHeaderContentLength), 10, 64); size != 0 {
+			return errors.New("the file has content now")
+		}
+		if err := bkt.DeleteObject(entry.Key); err != nil {
+			return fmt.Errorf("delete failed: %w", err)
+		}
+		s.invalidateListingCacheForConfig(config, entry.Bucket, entry.Key)
+		return nil
+	case OperationKindDelete:
+		if len(entry.TrashKeys) > 0 {
+			result, err := s.RestoreTrashItems(config, entry.TrashBucket, entry.TrashKeys, false)
+			if err != nil {
+				return err
+			}
+			if len(result.Failed) > 0 {
+				// Keep the entry undoable for the objects still in the recycle bin only.
+				s.dropRestoredTrashKeys(config, entry.ID, result.Restored)
+				failedKeys := make([]string, 0, len(result.Failed))
+				for key := range result.Failed {
+					failedKeys = append(failedKeys, key)
+				}
+				sort.Strings(failedKeys)
+				return fmt.Errorf("%d of %d objects could not be restored (first error: %s: %s)", len(failedKeys), len(entry.TrashKeys), failedKeys[0], result.Failed[failedKeys[0]])
+			}
+			return nil
+		}
+		if len(entry.DeleteMarkers) > 0 {
+			return s.DeleteObjectVersions(config, entry.Bucket, entry.DeleteMarkers)
+		}
+	}
+	return fmt.Errorf("unsupported operation: %s", entry.Kind)
+}
+
+// dropRestoredTrashKeys removes the keys a partial undo restored from the journal entry, so
+// undoing it again only restores what is left in the recycle bin.
+func (s *OSSService) dropRestoredTrashKeys(config OSSConfig, entryID string, restored []string) {
+	if len(restored) == 0 {
+		return
+	}
+	done := make(map[string]bool, len(restored))
+	for _, key := range restored {
+		done[normalizeObjectKey(key)] = true
+	}
+	profileName := s.resolveTransferProfileName(config)
+	s.journalMu.Lock()
+	defer s.journalMu.Unlock()
+	store := s.loadOperationJournalLocked()
+	entries := store.Profiles[profileName]
+	for i := range entries {
+		if entries[i].ID != entryID {
+			continue
+		}
+		remaining := make([]string, 0, len(entries[i].TrashKeys))
+		for _, key := range entries[i].TrashKeys {
+			if !done[normalizeObjectKey(key)] {
+				remaining = append(remaining, key)
+			}
+		}
+		entries[i].TrashKeys = remaining
+		entries[i].ObjectCount = len(remaining)
+	}
+	_ = s.saveOperationJournalLocked(store)
+}
+
+// undoMoves moves every recorded object back, after checking that all of them are still at
+// their destination and none of their original keys was taken in the meantime.
+func (s *OSSService) undoMoves(config OSSConfig, entry OperationEntry) error {
+	clientMoves := entry.Moves
+	mapOperationKeys(&entry, normalizeObjectKey)
+	srcBkt, err := s.openBucket(config, entry.Bucket)
+	if err != nil {
+		return err
+	}
+	destBkt, err := s.openBucket(config, entry.DestBucket)
+	if err != nil {
+		return err
+	}
+	for _, move := range entry.Moves {
+		exists, err := destBkt.IsObjectExist(move.To)
+		if err != nil {
+			return fmt.Errorf("failed to check %s: %w", move.To, err)
+		}
+		if !exists {
+			return fmt.Errorf("%s no longer exists", buildOssPath(entry.DestBucket, move.To))
+		}
+		taken, err := srcBkt.IsObjectExist(move.From)
+		if err != nil {
+			return fmt.Errorf("failed to check %s: %w", move.From, err)
+		}
+		if taken {
+			return fmt.Errorf("%s exists again", buildOssPath(entry.Bucket, move.From))
+		}
+	}
+	for i, move := range clientMoves {
+		if _, err := s.moveObject(config, entry.DestBucket, move.To, entry.Bucket, move.From); err != nil {
+			return fmt.Errorf("moved back %d of %d objects: %w", i, len(entry.Moves), err)
+		}
+	}
+	return nil
+}
+
+func newTrashDeleteOperation(bucket string, object string, trashBucket string, trashKeys []string) OperationEntry {
+	bucket, object = normalizeTransferBucket(bucket), normalizeObjectKey(object)
+	return OperationEntry{
+		Kind:        OperationKindDelete,
+		Description: "delete " + buildOssPath(bucket, object),
+		Bucket:      bucket,
+		Key:         object,
+		TrashBucket: trashBucket,
+		TrashKeys:   trashKeys,
+		ObjectCount: len(trashKeys),
+		Undoable:    true,
+	}
+}
+
+// deleteFromVersionedBucket deletes object, recursively for folders, when the bucket has
+// versioning enabled, and journals the delete markers it leaves so the delete can be undone.
+// It reports false without deleting anything when the bucket is not versioned or its
+// versioning cannot be read.
+func (s *OSSService) deleteFromVersionedBucket(config OSSConfig, bucketName string, object string) (bool, error) {
+	bkt, err := s.openBucket(config, bucketName)
+	if err != nil {
+		return false, nil
+	}
+	versioning, err := bkt.Client.GetBucketVersioning(bkt.BucketName)
+	if err != nil || versioning.Status != string(oss.VersionEnabled) {
+		return false, nil
+	}
+
+	object = normalizeObjectKey(object)
+	var objects []oss.DeleteObject
+	if strings.HasSuffix(object, "/") {
+		err = walkObjectsUnderPrefix(bkt, object, func(properties oss.ObjectProperties) error {
+			objects = append(objects, oss.DeleteObject{Key: properties.Key})
+			return nil
+		})
+		if err != nil {
+			return true, fmt.Errorf("delete failed: failed to list folder objects: %w", err)
+		}
+	} else {
+		objects = append(objects, oss.DeleteObject{Key: object})
+	}
+
+	var markers []ObjectVersionRef
+	for start := 0; start < len(objects) && err == nil; start += 1000 {
+		end := min(start+1000, len(objects))
+		var result oss.DeleteObjectVersionsResult
+		result, err = bkt.DeleteObjectVersions(objects[start:end])
+		for _, deleted := range result.DeletedObjectsDetail {
+			if deleted.DeleteMarker && deleted.DeleteMarkerVersionId != "" {
+				markers = append(markers, ObjectVersionRef{Key: deleted.Key, VersionID: deleted.DeleteMarkerVersionId})
+			}
+		}
+	}
+	keys := make([]string, 0, len(objects)+1)
+	for _, deleted := range objects {
+		keys = append(keys, deleted.Key)
+	}
+	s.invalidateListingCacheForConfig(config, bkt.BucketName, append(keys, object)...)
+
+	if len(markers) > 0 {
+		s.recordOperation(config, OperationEntry{
+			Kind:          OperationKindDelete,
+			Description:   "delete " + buildOssPath(bkt.BucketName, object),
+			Bucket:        bkt.BucketName,
+			Key:           object,
+			DeleteMarkers: markers,
+			ObjectCount:   len(markers),
+			Undoable:      true,
+		})
+	}
+	if err != nil {
+		return true, fmt.Errorf("delete failed: %w", err)
+	}
+	return true, nil
+}
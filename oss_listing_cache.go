@@ -0,0 +1,415 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// The listing cache keeps every listing page fetched from OSS on disk, one file per folder:
+// <work dir>/listing-cache/<profile>/<bucket>/<prefix>.json (names hashed). Pages are served
+// instantly and revalidated in the background; mutations made by the app drop the folders
+// they touch. Connections that are not saved as a profile all share one anonymous name, so
+// their listings are never cached.
+
+const (
+	listingCacheDirName       = "listing-cache"
+	listingCacheSchemaVersion = 1
+)
+
+// CachedObjectListPage is a listing page served from the cache or from OSS. Cached pages are
+// revalidated in the background and a changed page is emitted as "listing:update".
+type CachedObjectListPage struct {
+	Bucket      string               `json:"bucket"`
+	Prefix      string               `json:"prefix"`
+	Marker      string               `json:"marker"`
+	Page        ObjectListPageResult `json:"page"`
+	Cached      bool                 `json:"cached"`
+	Offline     bool                 `json:"offline,omitempty"`
+	FetchedAtMs int64                `json:"fetchedAtMs"`
+}
+
+type listingCachePage struct {
+	Marker      string               `json:"marker"`
+	MaxKeys     int                  `json:"maxKeys"`
+	Result      ObjectListPageResult `json:"result"`
+	FetchedAtMs int64                `json:"fetchedAtMs"`
+}
+
+type listingCacheFile struct {
+	SchemaVersion int                         `json:"schemaVersion"`
+	Bucket        string                      `json:"bucket"`
+	Prefix        string                      `json:"prefix"`
+	Pages         map[string]listingCachePage `json:"pages"`
+}
+
+func listingCacheHash(value string) string {
+	sum := sha1.Sum([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+func normalizeListingMaxKeys(maxKeys int) int {
+	if maxKeys <= 0 {
+		return 200
+	}
+	if maxKeys > 1000 {
+		return 1000
+	}
+	return maxKeys
+}
+
+func listingCachePageKey(marker string, maxKeys int) string {
+	return marker + "\x1f" + strconv.Itoa(maxKeys)
+}
+
+func (s *OSSService) listingCacheRoot() string {
+	return filepath.Join(normalizeWorkDirPath(s.configDir, s.defaultConfigDir), listingCacheDirName)
+}
+
+func (s *OSSService) listingCacheProfileDir(profileName string) string {
+	return filepath.Join(s.listingCacheRoot(), listingCacheHash(normalizeTransferProfileName(profileName))[:16])
+}
+
+func (s *OSSService) listingCacheBucketDir(profileName string, bucket string) string {
+	return filepath.Join(s.listingCacheProfileDir(profileName), listingCacheHash(bucket)[:16])
+}
+
+func (s *OSSService) listingCachePath(profileName string, bucket string, prefix string) string {
+	return filepath.Join(s.listingCacheBucketDir(profileName, bucket), listingCacheHash(prefix)+".json")
+}
+
+func readListingCacheFile(path string) (listingCacheFile, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return listingCacheFile{}, false
+	}
+	var file listingCacheFile
+	if err := json.Unmarshal(data, &file); err != nil || file.SchemaVersion != listingCacheSchemaVersion {
+		return listingCacheFile{}, false
+	}
+	if file.Pages == nil {
+		file.Pages = make(map[string]listingCachePage)
+	}
+	return file, true
+}
+
+func writeListingCacheFile(path string, file listingCacheFile) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+func (s *OSSService) cachedListingPage(profileName string, bucket string, prefix string, marker string, maxKeys int) (listingCachePage, bool) {
+	if profileName == transferProfileAnonymous {
+		return listingCachePage{}, false
+	}
+	s.listingCacheMu.Lock()
+	defer s.listingCacheMu.Unlock()
+	file, ok := readListingCacheFile(s.listingCachePath(profileName, bucket, prefix))
+	if !ok {
+		return listingCachePage{}, false
+	}
+	page, ok := file.Pages[listingCachePageKey(marker, maxKeys)]
+	return page, ok
+}
+
+// storeListingPage records a page fetched from OSS and reports whether it differs from the
+// cached copy. Cache failures are ignored; the cache is only an accelerator.
+func (s *OSSService) storeListingPage(config OSSConfig, bucketName string, prefix string, marker string, maxKeys int, result ObjectListPageResult) bool {
+	bucketName = normalizeTransferBucket(bucketName)
+	prefix = normalizeObjectPrefix(prefix)
+	marker = strings.TrimSpace(marker)
+	maxKeys = normalizeListingMaxKeys(maxKeys)
+	profileName := s.resolveTransferProfileName(config)
+	if profileName == transferProfileAnonymous {
+		return false
+	}
+
+	s.listingCacheMu.Lock()
+	defer s.listingCacheMu.Unlock()
+	path := s.listingCachePath(profileName, bucketName, prefix)
+	file, ok := readListingCacheFile(path)
+	if !ok {
+		file = listingCacheFile{
+			SchemaVersion: listingCacheSchemaVersion,
+			Bucket:        bucketName,
+			Prefix:        prefix,
+			Pages:         make(map[string]listingCachePage),
+		}
+	}
+	pageKey := listingCachePageKey(marker, maxKeys)
+	previous, existed := file.Pages[pageKey]
+	file.Pages[pageKey] = listingCachePage{
+		Marker:      marker,
+		MaxKeys:     maxKeys,
+		Result:      result,
+		FetchedAtMs: time.Now().UnixMilli(),
+	}
+	_ = writeListingCacheFile(path, file)
+	return !existed || !reflect.DeepEqual(previous.Result, result)
+}
+
+// ListObjectsPageCached returns a listing page from the local cache when there is one and
+// revalidates it in the background. Without a cached copy the page is fetched from OSS. In
+// offline mode only cached pages are served.
+func (s *OSSService) ListObjectsPageCached(config OSSConfig, bucketName string, prefix string, marker string, maxKeys int) (CachedObjectListPage, error) {
+	bucketName = normalizeTransferBucket(bucketName)
+	if bucketName == "" {
+		return CachedObjectListPage{}, errors.New("bucket name is required")
+	}
+	prefix = normalizeObjectPrefix(prefix)
+	marker = strings.TrimSpace(marker)
+	maxKeys = normalizeListingMaxKeys(maxKeys)
+	offline := s.IsOfflineMode()
+	profileName := s.resolveTransferProfileName(config)
+
+	out := CachedObjectListPage{Bucket: bucketName, Prefix: prefix, Marker: marker, Offline: offline}
+	if cached, ok := s.cachedListingPage(profileName, bucketName, prefix, marker, maxKeys); ok {
+		out.Page = cached.Result
+		out.Cached = true
+		out.FetchedAtMs = cached.FetchedAtMs
+		s.fillCachedFolderSizes(config, bucketName, prefix, out.Page.Items)
+		if !offline {
+			go s.revalidateListingPage(config, out, maxKeys)
+		}
+		return out, nil
+	}
+	if offline {
+		return CachedObjectListPage{}, errors.New("this folder is not available offline")
+	}
+
+	result, err := s.ListObjectsPage(config, bucketName, prefix, marker, maxKeys)
+	if err != nil {
+		return CachedObjectListPage{}, err
+	}
+	out.Page = result
+	out.FetchedAtMs = time.Now().UnixMilli()
+	return out, nil
+}
+
+func (s *OSSService) revalidateListingPage(config OSSConfig, cached CachedObjectListPage, maxKeys int) {
+	id := transferConfigSignature(config) + "\x1e" + cached.Bucket + "\x1e" + cached.Prefix + "\x1e" + listingCachePageKey(cached.Marker, maxKeys)
+	s.listingCacheMu.Lock()
+	if s.listingRevalidating == nil {
+		s.listingRevalidating = make(map[string]struct{})
+	}
+	if _, running := s.listingRevalidating[id]; running {
+		s.listingCacheMu.Unlock()
+		return
+	}
+	s.listingRevalidating[id] = struct{}{}
+	s.listingCacheMu.Unlock()
+	defer func() {
+		s.listingCacheMu.Lock()
+		delete(s.listingRevalidating, id)
+		s.listingCacheMu.Unlock()
+	}()
+
+	result, err := s.listObjectsPage(config, cached.Bucket, cached.Prefix, cached.Marker, maxKeys)
+	if err != nil {
+		return
+	}
+	if !s.storeListingPage(config, cached.Bucket, cached.Prefix, cached.Marker, maxKeys, result) {
+		return
+	}
+	s.emitEvent("listing:update", CachedObjectListPage{
+		Bucket:      cached.Bucket,
+		Prefix:      cached.Prefix,
+		Marker:      cached.Marker,
+		Page:        result,
+		FetchedAtMs: time.Now().UnixMilli(),
+	})
+}
+
+// listingParentPrefix returns the folder that lists key.
+func listingParentPrefix(key string) string {
+	trimmed := strings.TrimSuffix(key, "/")
+	idx := strings.LastIndex(trimmed, "/")
+	if idx < 0 {
+		return ""
+	}
+	return trimmed[:idx+1]
+}
+
+// invalidateListingCache drops the cached listings, thumbnails and folder stats affected by a
+// change to keys: the folder each key is listed in, every folder stat above it and, for folder
+// keys, everything below.
+func (s *OSSService) invalidateListingCache(profileName string, bucketName string, keys ...string) {
+	bucketName = normalizeTransferBucket(bucketName)
+	if bucketName == "" || len(keys) == 0 {
+		return
+	}
+
+	prefixes := make(map[string]struct{})
+	folders := make(map[string]struct{})
+	for _, key := range keys {
+		key = normalizeObjectKey(key)
+		prefixes[listingParentPrefix(key)] = struct{}{}
+		if strings.HasSuffix(key, "/") {
+			folders[key] = struct{}{}
+		}
+	}
+
+	s.listingCacheMu.Lock()
+	for prefix := range prefixes {
+		_ = os.Remove(s.listingCachePath(profileName, bucketName, prefix))
+	}
+	if len(folders) > 0 {
+		bucketDir := s.listingCacheBucketDir(profileName, bucketName)
+		entries, _ := os.ReadDir(bucketDir)
+		for _, entry := range entries {
+			path := filepath.Join(bucketDir, entry.Name())
+			file, ok := readListingCacheFile(path)
+			if !ok {
+				continue
+			}
+			for folder := range folders {
+				if strings.HasPrefix(file.Prefix, folder) {
+					_ = os.Remove(path)
+					break
+				}
+			}
+		}
+	}
+	s.listingCacheMu.Unlock()
+
+	s.dropThumbnails(profileName, bucketName, keys...)
+	s.markFolderIndexesChanged(profileName, bucketName, keys...)
+
+	s.folderStatsMu.Lock()
+	defer s.folderStatsMu.Unlock()
+	cache := s.folderStatsCacheLocked()
+	changed := false
+	for cacheKey, stats := range cache {
+		if stats.Bucket != bucketName || !strings.HasPrefix(cacheKey, normalizeTransferProfileName(profileName)+"\x1f") {
+			continue
+		}
+		for _, key := range keys {
+			key = normalizeObjectKey(key)
+			if strings.HasPrefix(key, stats.Prefix) || (strings.HasSuffix(key, "/") && strings.HasPrefix(stats.Prefix, key)) {
+				delete(cache, cacheKey)
+				changed = true
+				break
+			}
+		}
+	}
+	if changed {
+		_ = s.persistFolderStatsLocked()
+	}
+}
+
+func (s *OSSService) invalidateListingCacheForConfig(config OSSConfig, bucketName string, keys ...string) {
+	s.invalidateListingCache(s.resolveTransferProfileName(config), bucketName, keys...)
+}
+
+// ClearListingCache removes every cached listing of the profile that config belongs to.
+func (s *OSSService) ClearListingCache(config OSSConfig) error {
+	s.listingCacheMu.Lock()
+	defer s.listingCacheMu.Unlock()
+	if err := os.RemoveAll(s.listingCacheProfileDir(s.resolveTransferProfileName(config))); err != nil {
+		return fmt.Errorf("failed to clear listing cache: %w", err)
+	}
+	return nil
+}
+
+// SetOfflineMode switches browsing to the local listing cache only. Nothing is fetched from
+// OSS while offline mode is on.
+func (s *OSSService) SetOfflineMode(enabled bool) {
+	s.listingCacheMu.Lock()
+	s.offlineMode = enabled
+	s.listingCacheMu.Unlock()
+}
+
+func (s *OSSService) IsOfflineMode() bool {
+	s.listingCacheMu.Lock()
+	defer s.listingCacheMu.Unlock()
+	return s.offlineMode
+}
+
+// SearchCachedObjects searches the cached listings below prefix with the same filters as
+// SearchObjects. It works offline but only sees folders that were browsed before.
+func (s *OSSService) SearchCachedObjects(config OSSConfig, bucketName string, prefix string, query ObjectSearchQuery) ([]ObjectInfo, error) {
+	matcher, err := newObjectMatcher(query)
+	if err != nil {
+		return nil, err
+	}
+	bucketName = normalizeTransferBucket(bucketName)
+	if bucketName == "" {
+		return nil, errors.New("bucket name is required")
+	}
+	prefix = normalizeObjectPrefix(prefix)
+	limit := query.MaxResults
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+
+	profileName := s.resolveTransferProfileName(config)
+	if profileName == transferProfileAnonymous {
+		return []ObjectInfo{}, nil
+	}
+
+	s.listingCacheMu.Lock()
+	bucketDir := s.listingCacheBucketDir(profileName, bucketName)
+	entries, _ := os.ReadDir(bucketDir)
+	files := make([]listingCacheFile, 0, len(entries))
+	for _, entry := range entries {
+		if file, ok := readListingCacheFile(filepath.Join(bucketDir, entry.Name())); ok && strings.HasPrefix(file.Prefix, prefix) {
+			files = append(files, file)
+		}
+	}
+	s.listingCacheMu.Unlock()
+
+	results := make([]ObjectInfo, 0, 64)
+	seen := make(map[string]struct{})
+	for _, file := range files {
+		for _, page := range file.Pages {
+			for _, item := range page.Result.Items {
+				if item.Type != "File" {
+					continue
+				}
+				key := file.Prefix + item.Name
+				if _, ok := seen[key]; ok {
+					continue
+				}
+				seen[key] = struct{}{}
+
+				modified, _ := time.ParseInLocation("2006-01-02 15:04:05", item.LastModified, time.Local)
+				object := oss.ObjectProperties{
+					Key:          key,
+					Size:         item.Size,
+					StorageClass: item.StorageClass,
+					LastModified: modified,
+				}
+				relative := strings.TrimPrefix(key, prefix)
+				if !matcher.Match(object, relative) {
+					continue
+				}
+				item.Name = relative
+				results = append(results, item)
+				if len(results) >= limit {
+					return results, nil
+				}
+			}
+		}
+	}
+	return results, nil
+}
@@ -9,6 +9,9 @@ import (
 )
 
 func normalizeObjectKey(key string) string {
+	if raw, ok := decodeObjectKeyParam(key); ok {
+		return raw
+	}
 	key = strings.TrimSpace(key)
 	key = strings.TrimLeft(key, "/")
 	return key
@@ -43,6 +46,15 @@ func (s *OSSService) CreateFolder(config OSSConfig, bucketName string, prefix st
 	if err := bucket.PutObject(key, bytes.NewReader(nil)); err != nil {
 		return fmt.Errorf("failed to create folder: %w", err)
 	}
+	s.invalidateListingCacheForConfig(config, bucketName, key)
+	s.recordOperation(config, OperationEntry{
+		Kind:        OperationKindCreateFolder,
+		Description: "create folder " + buildOssPath(bucketName, key),
+		Bucket:      bucketName,
+		Key:         key,
+		ObjectCount: 1,
+		Undoable:    true,
+	})
 
 	return nil
 }
@@ -90,25 +102,44 @@ func (s *OSSService) CreateFile(config OSSConfig, bucketName string, prefix stri
 	if err := bucket.PutObject(key, bytes.NewReader(nil)); err != nil {
 		return fmt.Errorf("failed to create file: %w", err)
 	}
+	s.invalidateListingCacheForConfig(config, bucketName, key)
+	s.recordOperation(config, OperationEntry{
+		Kind:        OperationKindCreateFile,
+		Description: "create file " + buildOssPath(bucketName, key),
+		Bucket:      bucketName,
+		Key:         key,
+		ObjectCount: 1,
+		Undoable:    true,
+	})
 
 	return nil
 }
 
 func (s *OSSService) MoveObject(config OSSConfig, srcBucketName string, srcKey string, destBucketName string, destKey string) error {
+	moves, err := s.moveObject(config, srcBucketName, srcKey, destBucketName, destKey)
+	if len(moves) > 0 {
+		s.recordOperation(config, newMoveOperation(srcBucketName, srcKey, destBucketName, destKey, moves, err))
+	}
+	return err
+}
+
+// moveObject moves srcKey, or every object under it when it is a folder, and returns the
+// keys it moved, including those moved before an error.
+func (s *OSSService) moveObject(config OSSConfig, srcBucketName string, srcKey string, destBucketName string, destKey string) ([]OperationMove, error) {
 	srcBucketName = strings.TrimSpace(srcBucketName)
 	destBucketName = strings.TrimSpace(destBucketName)
 	if srcBucketName == "" || destBucketName == "" {
-		return fmt.Errorf("source and destination bucket are required")
+		return nil, fmt.Errorf("source and destination bucket are required")
 	}
 
 	srcKey = normalizeObjectKey(srcKey)
 	destKey = normalizeObjectKey(destKey)
 	if srcKey == "" || destKey == "" {
-		return fmt.Errorf("source and destination key are required")
+		return nil, fmt.Errorf("source and destination key are required")
 	}
 
 	if srcBucketName == destBucketName && srcKey == destKey {
-		return nil
+		return nil, nil
 	}
 
 	isFolder := strings.HasSuffix(srcKey, "/")
@@ -118,42 +149,47 @@ func (s *OSSService) MoveObject(config OSSConfig, srcBucketName string, srcKey s
 
 	// Prevent moving a folder into itself (same bucket).
 	if isFolder && srcBucketName == destBucketName && strings.HasPrefix(destKey, srcKey) {
-		return fmt.Errorf("destination is inside the source folder")
+		return nil, fmt.Errorf("destination is inside the source folder")
 	}
 
 	client, err := sdkClientFromConfig(config)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	srcBucket, err := client.Bucket(srcBucketName)
 	if err != nil {
-		return fmt.Errorf("failed to open source bucket: %w", err)
+		return nil, fmt.Errorf("failed to open source bucket: %w", err)
 	}
 
 	destBucket, err := client.Bucket(destBucketName)
 	if err != nil {
-		return fmt.Errorf("failed to open destination bucket: %w", err)
+		return nil, fmt.Errorf("failed to open destination bucket: %w", err)
 	}
+	defer func() {
+		s.invalidateListingCacheForConfig(config, srcBucketName, srcKey)
+		s.invalidateListingCacheForConfig(config, destBucketName, destKey)
+	}()
 
 	if !isFolder {
 		if srcBucketName == destBucketName {
 			if _, err := destBucket.CopyObject(srcKey, destKey); err != nil {
-				return fmt.Errorf("copy failed: %w", err)
+				return nil, fmt.Errorf("copy failed: %w", err)
 			}
 		} else {
 			if _, err := destBucket.CopyObjectFrom(srcBucketName, srcKey, destKey); err != nil {
-				return fmt.Errorf("copy failed: %w", err)
+				return nil, fmt.Errorf("copy failed: %w", err)
 			}
 		}
 
 		if err := srcBucket.DeleteObject(srcKey); err != nil {
-			return fmt.Errorf("delete source failed: %w", err)
+			return nil, fmt.Errorf("delete source failed: %w", err)
 		}
-		return nil
+		return []OperationMove{{From: srcKey, To: destKey}}, nil
 	}
 
 	// Folder move: list recursively and move each object.
+	moves := make([]OperationMove, 0, 16)
 	marker := ""
 	for {
 		lor, err := srcBucket.ListObjects(
@@ -162,7 +198,7 @@ func (s *OSSService) MoveObject(config OSSConfig, srcBucketName string, srcKey s
 			oss.MaxKeys(1000),
 		)
 		if err != nil {
-			return fmt.Errorf("failed to list folder objects: %w", err)
+			return moves, fmt.Errorf("failed to list folder objects: %w", err)
 		}
 
 		for _, object := range lor.Objects {
@@ -178,17 +214,18 @@ func (s *OSSService) MoveObject(config OSSConfig, srcBucketName string, srcKey s
 					continue
 				}
 				if _, err := destBucket.CopyObject(key, targetKey); err != nil {
-					return fmt.Errorf("copy failed: %w", err)
+					return moves, fmt.Errorf("copy failed: %w", err)
 				}
 			} else {
 				if _, err := destBucket.CopyObjectFrom(srcBucketName, key, targetKey); err != nil {
-					return fmt.Errorf("copy failed: %w", err)
+					return moves, fmt.Errorf("copy failed: %w", err)
 				}
 			}
 
 			if err := srcBucket.DeleteObject(key); err != nil {
-				return fmt.Errorf("delete source failed: %w", err)
+				return moves, fmt.Errorf("delete source failed: %w", err)
 			}
+			moves = append(moves, OperationMove{From: key, To: targetKey})
 		}
 
 		if !lor.IsTruncated {
@@ -197,5 +234,5 @@ func (s *OSSService) MoveObject(config OSSConfig, srcBucketName string, srcKey s
 		marker = lor.NextMarker
 	}
 
-	return nil
+	return moves, nil
 }
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+const (
+	// Objects above this size are copied with UploadPartCopy; CopyObject is limited to 5 GB
+	// and gets slow well before that.
+	maxSingleCopyObjectSize = 1024 * 1024 * 1024
+	multipartCopyPartSize   = 128 * 1024 * 1024
+	multipartCopyRoutines   = 4
+)
+
+// objectHeaderOptions turns the standard and user metadata headers of an object into
+// options, so a rewrite through multipart upload keeps what CopyObject would have kept.
+func objectHeaderOptions(header http.Header) []oss.Option {
+	options := make([]oss.Option, 0, 8)
+	for _, name := range []string{
+		oss.HTTPHeaderContentType,
+		oss.HTTPHeaderCacheControl,
+		oss.HTTPHeaderContentDisposition,
+		oss.HTTPHeaderContentEncoding,
+		oss.HTTPHeaderContentLanguage,
+		oss.HTTPHeaderExpires,
+	} {
+		if value := header.Get(name); value != "" {
+			options = append(options, oss.SetHeader(name, value))
+		}
+	}
+	for name, values := range header {
+		canonical := http.CanonicalHeaderKey(name)
+		if !strings.HasPrefix(canonical, oss.HTTPHeaderOssMetaPrefix) || len(values) == 0 {
+			continue
+		}
+		options = append(options, oss.SetHeader(canonical, values[0]))
+	}
+	return options
+}
+
+// objectTaggingOption reads the tags of an object and returns them as a SetTagging option.
+func objectTaggingOption(bkt *oss.Bucket, key string, options ...oss.Option) (oss.Option, bool, error) {
+	result, err := bkt.GetObjectTagging(key, options...)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(result.Tags) == 0 {
+		return nil, false, nil
+	}
+	return oss.SetTagging(oss.Tagging{Tags: result.Tags}), true, nil
+}
+
+// objectCopySource identifies the object (and optionally the version) a copy reads from.
+type objectCopySource struct {
+	Bucket    string
+	Key       string
+	VersionID string
+	Size      int64
+}
+
+func (src objectCopySource) options() []oss.Option {
+	if src.VersionID == "" {
+		return nil
+	}
+	return []oss.Option{oss.VersionId(src.VersionID)}
+}
+
+// copyObjectPreservingMetadata copies src to destKey in bkt, keeping headers, user metadata
+// and tags. extra options (for example a new storage class) are applied on top.
+func copyObjectPreservingMetadata(bkt *oss.Bucket, src objectCopySource, destKey string, extra ...oss.Option) error {
+	if src.Size <= maxSingleCopyObjectSize {
+		options := append([]oss.Option{oss.MetadataDirective(oss.MetaCopy)}, extra...)
+		options = append(options, src.options()...)
+		var err error
+		if src.Bucket == bkt.BucketName {
+			_, err = bkt.CopyObject(src.Key, destKey, options...)
+		} else {
+			_, err = bkt.CopyObjectFrom(src.Bucket, src.Key, destKey, options...)
+		}
+		if err != nil {
+			return fmt.Errorf("copy failed: %w", err)
+		}
+		return nil
+	}
+
+	srcBucket := bkt
+	if src.Bucket != bkt.BucketName {
+		var err error
+		srcBucket, err = bkt.Client.Bucket(src.Bucket)
+		if err != nil {
+			return fmt.Errorf("failed to open source bucket: %w", err)
+		}
+	}
+
+	header, err := srcBucket.GetObjectDetailedMeta(src.Key, src.options()...)
+	if err != nil {
+		return fmt.Errorf("failed to read object metadata: %w", err)
+	}
+	options := objectHeaderOptions(header)
+	if storageClass := header.Get(oss.HTTPHeaderOssStorageClass); storageClass != "" {
+		options = append(options, oss.ObjectStorageClass(oss.StorageClassType(storageClass)))
+	}
+	tagging, hasTags, err := objectTaggingOption(srcBucket, src.Key, src.options()...)
+	if err != nil {
+		return fmt.Errorf("failed to read object tags: %w", err)
+	}
+	if hasTags {
+		options = append(options, tagging)
+	}
+	options = append(options, extra...)
+
+	if err := multipartCopyObject(bkt, src, destKey, options); err != nil {
+		return fmt.Errorf("multipart copy failed: %w", err)
+	}
+	return nil
+}
+
+// multipartCopyObject copies src with UploadPartCopy. Unlike Bucket.CopyFile it keeps the
+// source version ID out of the InitiateMultipartUpload request.
+func multipartCopyObject(bkt *oss.Bucket, src objectCopySource, destKey string, options []oss.Option) error {
+	imur, err := bkt.InitiateMultipartUpload(destKey, options...)
+	if err != nil {
+		return err
+	}
+
+	type partRange struct {
+		number int
+		start  int64
+		size   int64
+	}
+	ranges := make([]partRange, 0, src.Size/multipartCopyPartSize+1)
+	for start := int64(0); start < src.Size; start += multipartCopyPartSize {
+		size := int64(multipartCopyPartSize)
+		if start+size > src.Size {
+			size = src.Size - start
+		}
+		ranges = append(ranges, partRange{number: len(ranges) + 1, start: start, size: size})
+	}
+
+	parts := make([]oss.UploadPart, len(ranges))
+	jobs := make(chan partRange)
+	var mu sync.Mutex
+	var firstErr error
+	var wg sync.WaitGroup
+	for i := 0; i < multipartCopyRoutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range jobs {
+				part, err := bkt.UploadPartCopy(imur, src.Bucket, src.Key, r.start, r.size, r.number, src.options()...)
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				parts[r.number-1] = part
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, r := range ranges {
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+		if failed {
+			break
+		}
+		jobs <- r
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		_ = bkt.AbortMultipartUpload(imur)
+		return firstErr
+	}
+	if _, err := bkt.CompleteMultipartUpload(imur, parts); err != nil {
+		_ = bkt.AbortMultipartUpload(imur)
+		return err
+	}
+	return nil
+}
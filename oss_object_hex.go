@@ -0,0 +1,467 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The hex viewer reads objects in ranges, so checking the header or the end of a multi-GB
+// file for truncation costs a few KB of traffic instead of a download.
+
+const (
+	hexDumpBytesPerLine  = 16
+	hexDumpDefaultLength = 4096
+	hexDumpMaxLength     = 64 * 1024
+	formatHeadLength     = 512
+	// A zip end of central directory record is 22 bytes plus a comment of at most 64 KB.
+	zipEOCDSearchLength = 22 + 65535
+)
+
+type HexDumpLine struct {
+	Offset int64  `json:"offset"`
+	Hex    string `json:"hex"`   // Space-separated byte pairs, grouped by eight
+	ASCII  string `json:"ascii"` // Printable ASCII, other bytes as "."
+}
+
+type HexDumpPage struct {
+	Bucket     string        `json:"bucket"`
+	Key        string        `json:"key"`
+	Offset     int64         `json:"offset"`
+	Length     int64         `json:"length"`
+	ObjectSize int64         `json:"objectSize"`
+	Lines      []HexDumpLine `json:"lines"`
+	HasMore    bool          `json:"hasMore"`
+}
+
+type ObjectFormatField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// ObjectFormatInfo describes the file type detected from an object's magic number. Warnings
+// list inconsistencies such as a missing trailer that suggest truncation or corruption.
+type ObjectFormatInfo struct {
+	Bucket      string              `json:"bucket"`
+	Key         string              `json:"key"`
+	ObjectSize  int64               `json:"objectSize"`
+	Type        string              `json:"type"` // "zip", "gzip", "parquet", "elf", "sqlite", ... or "unknown"
+	Description string              `json:"description"`
+	Fields      []ObjectFormatField `json:"fields"`
+	Warnings    []string            `json:"warnings"`
+	HeadHex     string              `json:"headHex"`
+}
+
+func hexDumpLines(data []byte, offset int64) []HexDumpLine {
+	lines := make([]HexDumpLine, 0, (len(data)+hexDumpBytesPerLine-1)/hexDumpBytesPerLine)
+	for start := 0; start < len(data); start += hexDumpBytesPerLine {
+		end := start + hexDumpBytesPerLine
+		if end > len(data) {
+			end = len(data)
+		}
+		row := data[start:end]
+		var hexPart strings.Builder
+		ascii := make([]byte, len(row))
+		for i, b := range row {
+			if i > 0 {
+				hexPart.WriteByte(' ')
+				if i == 8 {
+					hexPart.WriteByte(' ')
+				}
+			}
+			hexPart.WriteString(hex.EncodeToString([]byte{b}))
+			if b >= 0x20 && b < 0x7f {
+				ascii[i] = b
+			} else {
+				ascii[i] = '.'
+			}
+		}
+		lines = append(lines, HexDumpLine{Offset: offset + int64(start), Hex: hexPart.String(), ASCII: string(ascii)})
+	}
+	return lines
+}
+
+// GetObjectHexDump returns a hex dump of length bytes of key from offset, which is rounded
+// down to a multiple of 16. A negative offset counts back from the end of the object.
+func (s *OSSService) GetObjectHexDump(config OSSConfig, bucketName string, key string, offset int64, length int64) (HexDumpPage, error) {
+	bkt, key, err := s.openObjectForRange(config, bucketName, key)
+	if err != nil {
+		return HexDumpPage{}, err
+	}
+	if length <= 0 {
+		length = hexDumpDefaultLength
+	}
+	if length > hexDumpMaxLength {
+		length = hexDumpMaxLength
+	}
+	info, err := headObjectSize(bkt, key)
+	if err != nil {
+		return HexDumpPage{}, err
+	}
+	if offset < 0 {
+		offset += info.size
+		if offset < 0 {
+			offset = 0
+		}
+	}
+	if offset > info.size {
+		offset = info.size
+	}
+	offset -= offset % hexDumpBytesPerLine
+	end := offset + length
+	if end > info.size {
+		end = info.size
+	}
+	data, err := readObjectRange(bkt, key, offset, end)
+	if err != nil {
+		return HexDumpPage{}, err
+	}
+	return HexDumpPage{
+		Bucket:     bkt.BucketName,
+		Key:        objectKeyForClient(key),
+		Offset:     offset,
+		Length:     int64(len(data)),
+		ObjectSize: info.size,
+		Lines:      hexDumpLines(data, offset),
+		HasMore:    offset+int64(len(data)) < info.size,
+	}, nil
+}
+
+// objectFormatReader reads ranges of the object being inspected.
+type objectFormatReader struct {
+	size int64
+	head []byte
+	read func(start int64, end int64) ([]byte, error)
+}
+
+// tail returns the last n bytes of the object.
+func (r objectFormatReader) tail(n int64) ([]byte, error) {
+	start := r.size - n
+	if start < 0 {
+		start = 0
+	}
+	if start == 0 && int64(len(r.head)) == r.size {
+		return r.head, nil
+	}
+	return r.read(start, r.size)
+}
+
+type objectFormat struct {
+	kind        string
+	description string
+	match       func(head []byte) bool
+	inspect     func(r objectFormatReader, info *ObjectFormatInfo) error
+}
+
+func hasMagic(magic string) func([]byte) bool {
+	return func(head []byte) bool { return bytes.HasPrefix(head, []byte(magic)) }
+}
+
+var objectFormats = []objectFormat{
+	{"zip", "ZIP archive", func(head []byte) bool {
+		return bytes.HasPrefix(head, []byte("PK\x03\x04")) || bytes.HasPrefix(head, []byte("PK\x05\x06"))
+	}, inspectZip},
+	{"gzip", "gzip compressed data", hasMagic("\x1f\x8b"), inspectGzip},
+	{"parquet", "Apache Parquet", hasMagic("PAR1"), inspectParquet},
+	{"elf", "ELF executable", hasMagic("\x7fELF"), inspectELF},
+	{"sqlite", "SQLite 3 database", hasMagic("SQLite format 3\x00"), inspectSQLite},
+	{"pdf", "PDF document", hasMagic("%PDF-"), inspectPDF},
+	{"png", "PNG image", hasMagic("\x89PNG\r\n\x1a\n"), nil},
+	{"jpeg", "JPEG image", hasMagic("\xff\xd8\xff"), nil},
+	{"bzip2", "bzip2 compressed data", hasMagic("BZh"), nil},
+	{"xz", "xz compressed data", hasMagic("\xfd7zXZ\x00"), nil},
+	{"zstd", "Zstandard compressed data", hasMagic("\x28\xb5\x2f\xfd"), nil},
+	{"7z", "7-Zip archive", hasMagic("7z\xbc\xaf\x27\x1c"), nil},
+	{"tar", "tar archive", func(head []byte) bool {
+		return len(head) >= 262 && bytes.Equal(head[257:262], []byte("ustar"))
+	}, nil},
+}
+
+// DetectObjectFormat identifies key by its magic number and reports basic header fields,
+// reading only the head of the object and, for formats with a trailer, its end.
+func (s *OSSService) DetectObjectFormat(config OSSConfig, bucketName string, key string) (ObjectFormatInfo, error) {
+	bkt, key, err := s.openObjectForRange(config, bucketName, key)
+	if err != nil {
+		return ObjectFormatInfo{}, err
+	}
+	info, err := headObjectSize(bkt, key)
+	if err != nil {
+		return ObjectFormatInfo{}, err
+	}
+	headEnd := int64(formatHeadLength)
+	if headEnd > info.size {
+		headEnd = info.size
+	}
+	head, err := readObjectRange(bkt, key, 0, headEnd)
+	if err != nil {
+		return ObjectFormatInfo{}, err
+	}
+
+	result := ObjectFormatInfo{
+		Bucket:      bkt.BucketName,
+		Key:         objectKeyForClient(key),
+		ObjectSize:  info.size,
+		Type:        "unknown",
+		Description: "Unknown binary data",
+		Fields:      []ObjectFormatField{},
+		Warnings:    []string{},
+	}
+	if len(head) > 0 {
+		preview := head
+		if len(preview) > 64 {
+			preview = preview[:64]
+		}
+		result.HeadHex = hex.EncodeToString(preview)
+	}
+	if info.size == 0 {
+		result.Type, result.Description = "empty", "Empty object"
+		return result, nil
+	}
+
+	reader := objectFormatReader{
+		size: info.size,
+		head: head,
+		read: func(start int64, end int64) ([]byte, error) { return readObjectRange(bkt, key, start, end) },
+	}
+	for _, format := range objectFormats {
+		if !format.match(head) {
+			continue
+		}
+		result.Type, result.Description = format.kind, format.description
+		if format.inspect != nil {
+			if err := format.inspect(reader, &result); err != nil {
+				return ObjectFormatInfo{}, err
+			}
+		}
+		break
+	}
+	return result, nil
+}
+
+func (info *ObjectFormatInfo) addField(name string, value string) {
+	info.Fields = append(info.Fields, ObjectFormatField{Name: name, Value: value})
+}
+
+func (info *ObjectFormatInfo) warn(format string, args ...interface{}) {
+	info.Warnings = append(info.Warnings, fmt.Sprintf(format, args...))
+}
+
+func inspectZip(r objectFormatReader, info *ObjectFormatInfo) error {
+	tail, err := r.tail(zipEOCDSearchLength)
+	if err != nil {
+		return err
+	}
+	idx := bytes.LastIndex(tail, []byte("PK\x05\x06"))
+	if idx < 0 || len(tail)-idx < 22 {
+		info.warn("end of central directory not found; the archive is probably truncated")
+		return nil
+	}
+	eocd := tail[idx:]
+	entries := binary.LittleEndian.Uint16(eocd[10:12])
+	dirSize := int64(binary.LittleEndian.Uint32(eocd[12:16]))
+	dirOffset := int64(binary.LittleEndian.Uint32(eocd[16:20]))
+	commentLength := int(binary.LittleEndian.Uint16(eocd[20:22]))
+	info.addField("Entries", strconv.Itoa(int(entries)))
+	info.addField("Central directory size", strconv.FormatInt(dirSize, 10))
+	info.addField("Central directory offset", strconv.FormatInt(dirOffset, 10))
+	if commentLength > 0 {
+		info.addField("Comment length", strconv.Itoa(commentLength))
+	}
+	if entries == 0xffff || dirOffset == 0xffffffff {
+		info.addField("ZIP64", "yes")
+		return nil
+	}
+	eocdOffset := r.size - int64(len(tail)-idx)
+	if dirOffset+dirSize > eocdOffset {
+		info.warn("central directory ends at %d, past the end record at %d", dirOffset+dirSize, eocdOffset)
+	}
+	if idx+22+commentLength > len(tail) {
+		info.warn("archive comment is cut off")
+	}
+	return nil
+}
+
+var gzipOperatingSystems = map[byte]string{0: "FAT", 3: "Unix", 7: "Macintosh", 10: "TOPS-20", 11: "NTFS", 255: "unknown"}
+
+func inspectGzip(r objectFormatReader, info *ObjectFormatInfo) error {
+	head := r.head
+	if len(head) < 10 {
+		info.warn("gzip header is incomplete")
+		return nil
+	}
+	if head[2] == 8 {
+		info.addField("Compression", "deflate")
+	} else {
+		info.addField("Compression", fmt.Sprintf("unknown (%d)", head[2]))
+	}
+	flags := head[3]
+	if mtime := binary.LittleEndian.Uint32(head[4:8]); mtime != 0 {
+		info.addField("Modified", time.Unix(int64(mtime), 0).UTC().Format(time.RFC3339))
+	}
+	if name, ok := gzipOperatingSystems[head[9]]; ok {
+		info.addField("OS", name)
+	} else {
+		info.addField("OS", strconv.Itoa(int(head[9])))
+	}
+	if flags&0x08 != 0 {
+		pos := 10
+		if flags&0x04 != 0 && len(head) >= 12 {
+			pos += 2 + int(binary.LittleEndian.Uint16(head[10:12]))
+		}
+		if pos < len(head) {
+			if end := bytes.IndexByte(head[pos:], 0); end >= 0 {
+				info.addField("Original name", strings.ToValidUTF8(string(head[pos:pos+end]), "\uFFFD"))
+			}
+		}
+	}
+	if r.size < 18 {
+		info.warn("gzip data is shorter than its header and trailer")
+		return nil
+	}
+	tail, err := r.tail(8)
+	if err != nil {
+		return err
+	}
+	info.addField("CRC32", fmt.Sprintf("%08x", binary.LittleEndian.Uint32(tail[0:4])))
+	info.addField("Uncompressed size (mod 4 GiB)", strconv.FormatUint(uint64(binary.LittleEndian.Uint32(tail[4:8])), 10))
+	return nil
+}
+
+func inspectParquet(r objectFormatReader, info *ObjectFormatInfo) error {
+	if r.size < 12 {
+		info.warn("file is too small to hold a parquet footer")
+		return nil
+	}
+	tail, err := r.tail(8)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(tail[4:8], []byte("PAR1")) {
+		info.warn("trailing PAR1 magic is missing; the file is probably truncated")
+		return nil
+	}
+	footerLength := int64(binary.LittleEndian.Uint32(tail[0:4]))
+	info.addField("Footer length", strconv.FormatInt(footerLength, 10))
+	if footerLength+12 > r.size {
+		info.warn("footer length %d exceeds the file size", footerLength)
+	}
+	return nil
+}
+
+var elfMachines = map[uint16]string{
+	0x03: "x86", 0x08: "MIPS", 0x14: "PowerPC", 0x15: "PowerPC64", 0x28: "ARM",
+	0x2a: "SuperH", 0x32: "IA-64", 0x3e: "x86-64", 0xb7: "AArch64", 0xf3: "RISC-V", 0x102: "LoongArch",
+}
+
+var elfTypes = map[uint16]string{1: "relocatable", 2: "executable", 3: "shared object", 4: "core dump"}
+
+func inspectELF(r objectFormatReader, info *ObjectFormatInfo) error {
+	head := r.head
+	if len(head) < 52 {
+		info.warn("ELF header is incomplete")
+		return nil
+	}
+	is64 := head[4] == 2
+	var order binary.ByteOrder = binary.LittleEndian
+	if head[5] == 2 {
+		order = binary.BigEndian
+	}
+	if is64 {
+		info.addField("Class", "64-bit")
+	} else {
+		info.addField("Class", "32-bit")
+	}
+	if head[5] == 2 {
+		info.addField("Byte order", "big endian")
+	} else {
+		info.addField("Byte order", "little endian")
+	}
+	elfType := order.Uint16(head[16:18])
+	if name, ok := elfTypes[elfType]; ok {
+		info.addField("Type", name)
+	} else {
+		info.addField("Type", fmt.Sprintf("0x%x", elfType))
+	}
+	machine := order.Uint16(head[18:20])
+	if name, ok := elfMachines[machine]; ok {
+		info.addField("Machine", name)
+	} else {
+		info.addField("Machine", fmt.Sprintf("0x%x", machine))
+	}
+
+	var shoff int64
+	var shentsize, shnum uint16
+	if is64 {
+		if len(head) < 64 {
+			info.warn("ELF header is incomplete")
+			return nil
+		}
+		info.addField("Entry point", fmt.Sprintf("0x%x", order.Uint64(head[24:32])))
+		shoff = int64(order.Uint64(head[40:48]))
+		shentsize, shnum = order.Uint16(head[58:60]), order.Uint16(head[60:62])
+	} else {
+		info.addField("Entry point", fmt.Sprintf("0x%x", order.Uint32(head[24:28])))
+		shoff = int64(order.Uint32(head[32:36]))
+		shentsize, shnum = order.Uint16(head[46:48]), order.Uint16(head[48:50])
+	}
+	info.addField("Section headers", strconv.Itoa(int(shnum)))
+	if end := shoff + int64(shentsize)*int64(shnum); shoff > 0 && end > r.size {
+		info.warn("section headers end at %d, past the end of the file; it is probably truncated", end)
+	}
+	return nil
+}
+
+var sqliteEncodings = map[uint32]string{1: "UTF-8", 2: "UTF-16le", 3: "UTF-16be"}
+
+func inspectSQLite(r objectFormatReader, info *ObjectFormatInfo) error {
+	head := r.head
+	if len(head) < 100 {
+		info.warn("database header is incomplete")
+		return nil
+	}
+	pageSize := int64(binary.BigEndian.Uint16(head[16:18]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	pageCount := int64(binary.BigEndian.Uint32(head[28:32]))
+	info.addField("Page size", strconv.FormatInt(pageSize, 10))
+	info.addField("Page count", strconv.FormatInt(pageCount, 10))
+	if head[18] == 2 && head[19] == 2 {
+		info.addField("Journal mode", "WAL")
+	} else {
+		info.addField("Journal mode", "rollback")
+	}
+	if encoding, ok := sqliteEncodings[binary.BigEndian.Uint32(head[56:60])]; ok {
+		info.addField("Text encoding", encoding)
+	}
+	info.addField("SQLite version", strconv.FormatUint(uint64(binary.BigEndian.Uint32(head[96:100])), 10))
+	if pageSize < 512 {
+		info.warn("invalid page size %d", pageSize)
+		return nil
+	}
+	if expected := pageSize * pageCount; pageCount > 0 && r.size < expected {
+		info.warn("header declares %d bytes but the object has %d; it is probably truncated", expected, r.size)
+	}
+	if r.size%pageSize != 0 {
+		info.warn("size is not a multiple of the page size")
+	}
+	return nil
+}
+
+func inspectPDF(r objectFormatReader, info *ObjectFormatInfo) error {
+	if end := bytes.IndexAny(r.head, "\r\n"); end > 5 {
+		info.addField("Version", string(r.head[5:end]))
+	}
+	tail, err := r.tail(1024)
+	if err != nil {
+		return err
+	}
+	if !bytes.Contains(tail, []byte("%%EOF")) {
+		info.warn("%%%%EOF marker is missing at the end; the document is probably truncated")
+	}
+	return nil
+}
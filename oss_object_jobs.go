@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// objectJobWorkers is the number of concurrent server-side requests of one object job.
+// A job occupies a single transfer slot no matter how many objects it touches.
+const objectJobWorkers = 4
+
+// objectJobTask performs the job's operation on one object.
+type objectJobTask func(bkt *oss.Bucket, target objectTarget) error
+
+// enqueueObjectJob records a queued job in the transfer list and runs it in the background.
+// Progress is reported through TransferUpdate like uploads and downloads, with FileCount
+// and DoneCount counting objects and TotalBytes/DoneBytes summing their sizes.
+func (s *OSSService) enqueueObjectJob(config OSSConfig, update TransferUpdate, bkt *oss.Bucket, targets []objectTarget, task objectJobTask, onUpdate func(TransferUpdate)) string {
+	if update.ID == "" {
+		update.ID = s.newTransferID()
+	}
+	if strings.TrimSpace(update.ProfileName) == "" {
+		update.ProfileName = s.resolveTransferProfileName(config)
+	}
+	update.ProfileName = normalizeTransferProfileName(update.ProfileName)
+	update.Status = TransferStatusQueued
+	update.FileCount = len(targets)
+	update.TotalBytes = 0
+	for _, target := range targets {
+		if target.Size > 0 {
+			update.TotalBytes += target.Size
+		}
+	}
+	update.UpdatedAtMs = time.Now().UnixMilli()
+	s.emitTransfer(update, onUpdate)
+
+	go s.runObjectJob(update, bkt, targets, task, onUpdate)
+	return update.ID
+}
+
+func (s *OSSService) runObjectJob(update TransferUpdate, bkt *oss.Bucket, targets []objectTarget, task objectJobTask, onUpdate func(TransferUpdate)) {
+	limiter := s.currentTransferLimiter()
+	limiter.Acquire()
+	defer limiter.Release()
+
+	update.Status = TransferStatusInProgress
+	update.StartedAtMs = time.Now().UnixMilli()
+	update.UpdatedAtMs = update.StartedAtMs
+	s.emitTransfer(update, onUpdate)
+
+	var mu sync.Mutex
+	emitInterval := 250 * time.Millisecond
+	var lastEmit time.Time
+	firstError := ""
+
+	emitLocked := func(force bool) {
+		now := time.Now()
+		if !force && !lastEmit.IsZero() && now.Sub(lastEmit) < emitInterval {
+			return
+		}
+		lastEmit = now
+
+		elapsed := now.Sub(time.UnixMilli(update.StartedAtMs)).Seconds()
+		if elapsed > 0 && update.DoneBytes > 0 {
+			update.SpeedBytesPerSec = float64(update.DoneBytes) / elapsed
+		}
+		if update.SpeedBytesPerSec > 0 && update.TotalBytes > update.DoneBytes {
+			update.EtaSeconds = int64(float64(update.TotalBytes-update.DoneBytes) / update.SpeedBytesPerSec)
+		} else {
+			update.EtaSeconds = 0
+		}
+		update.UpdatedAtMs = now.UnixMilli()
+		s.emitTransfer(update, onUpdate)
+	}
+
+	jobs := make(chan objectTarget)
+	var wg sync.WaitGroup
+	for i := 0; i < objectJobWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for target := range jobs {
+				err := task(bkt, target)
+
+				mu.Lock()
+				update.DoneCount++
+				if err != nil {
+					update.ErrorCount++
+					if firstError == "" {
+						firstError = fmt.Sprintf("%s: %v", target.Key, err)
+					}
+				} else {
+					update.SuccessCount++
+					if target.Size > 0 {
+						update.DoneBytes += target.Size
+					}
+				}
+				emitLocked(false)
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, target := range targets {
+		jobs <- target
+	}
+	close(jobs)
+	wg.Wait()
+
+	keys := make([]string, 0, len(targets))
+	for _, target := range targets {
+		keys = append(keys, target.Key)
+	}
+	s.invalidateListingCache(update.ProfileName, bkt.BucketName, keys...)
+
+	mu.Lock()
+	defer mu.Unlock()
+	update.FinishedAtMs = time.Now().UnixMilli()
+	update.SpeedBytesPerSec = 0
+	update.EtaSeconds = 0
+	if update.ErrorCount > 0 {
+		update.Status = TransferStatusError
+		update.Message = fmt.Sprintf("%d succeeded, %d failed (first error: %s)", update.SuccessCount, update.ErrorCount, firstError)
+	} else {
+		update.Status = TransferStatusSuccess
+		update.Message = ""
+		update.DoneBytes = update.TotalBytes
+	}
+	emitLocked(true)
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// Object keys may contain anything OSS accepts: trailing spaces, "\r", other control
+// characters or bytes that are not valid UTF-8. Such keys do not survive JSON, trimming or
+// an ossutil command line, so listings add RawKey, an URL-encoded form that every API taking
+// a key accepts in place of the key. encodedObjectKeyPrefix marks that form; it starts with a
+// NUL byte so it can never collide with a typed key.
+const encodedObjectKeyPrefix = "\x00url:"
+
+// objectKeyNeedsEncoding reports whether key has to travel in its URL-encoded form.
+func objectKeyNeedsEncoding(key string) bool {
+	if !utf8.ValidString(key) {
+		return true
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if strings.TrimSpace(segment) != segment {
+			return true
+		}
+	}
+	for _, r := range key {
+		if unicode.IsControl(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// rawObjectKey returns the encoded form of key for ObjectInfo.RawKey, or "" when the key is
+// safe to pass as is.
+func rawObjectKey(key string) string {
+	if !objectKeyNeedsEncoding(key) {
+		return ""
+	}
+	return encodedObjectKeyPrefix + url.QueryEscape(key)
+}
+
+// objectKeyForClient returns key itself when it is plain text and its encoded form otherwise.
+// It is used for values such as listing markers that the frontend hands back verbatim.
+func objectKeyForClient(key string) string {
+	if raw := rawObjectKey(key); raw != "" {
+		return raw
+	}
+	return key
+}
+
+// normalizeMarkerParam decodes an encoded listing marker and trims plain ones.
+func normalizeMarkerParam(marker string) string {
+	if raw, ok := decodeObjectKeyParam(marker); ok {
+		return raw
+	}
+	return strings.TrimSpace(marker)
+}
+
+// decodeObjectKeyParam turns an encoded key back into the raw key. ok is false for plain keys.
+func decodeObjectKeyParam(value string) (string, bool) {
+	if !strings.HasPrefix(value, encodedObjectKeyPrefix) {
+		return value, false
+	}
+	key, err := url.QueryUnescape(strings.TrimPrefix(value, encodedObjectKeyPrefix))
+	if err != nil {
+		return value, false
+	}
+	return key, true
+}
+
+// objectKeyParam returns the raw key for an API argument without otherwise changing it.
+func objectKeyParam(value string) string {
+	key, _ := decodeObjectKeyParam(value)
+	return key
+}
+
+// ossutilObjectURL builds the cloud URL ossutil needs for key. Keys that do not survive the
+// command line are passed with --encoding-type url; ossutil then also decodes local paths, so
+// localPath is returned encoded to match.
+func ossutilObjectURL(bucket string, key string, localPath string) (string, string, []string) {
+	if !objectKeyNeedsEncoding(key) {
+		return fmt.Sprintf("oss://%s/%s", bucket, key), localPath, nil
+	}
+	if localPath != "" {
+		localPath = url.QueryEscape(localPath)
+	}
+	return fmt.Sprintf("oss://%s/%s", bucket, url.QueryEscape(key)), localPath, []string{"--encoding-type", "url"}
+}
+
+// sanitizeObjectKey rewrites each path segment of key so it no longer needs encoding:
+// invalid UTF-8 and control characters become "_" and edge whitespace is trimmed.
+func sanitizeObjectKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segment = strings.ToValidUTF8(segment, "_")
+		segment = strings.Map(func(r rune) rune {
+			if unicode.IsControl(r) {
+				return '_'
+			}
+			return r
+		}, segment)
+		segment = strings.TrimSpace(segment)
+		if segment == "" && segments[i] != "" {
+			segment = "_"
+		}
+		segments[i] = segment
+	}
+	return strings.Join(segments, "/")
+}
+
+// SuggestSafeObjectKey returns the key SanitizeObjectKeys would rename key to, so the UI can
+// offer it as the default of a rename.
+func (s *OSSService) SuggestSafeObjectKey(key string) string {
+	return sanitizeObjectKey(objectKeyParam(key))
+}
+
+// SanitizeObjectKeys renames the selected objects whose keys need encoding to their
+// sanitized form, keeping metadata and tags. Keys ending with "/" are handled recursively.
+// Objects whose sanitized key is already taken are reported as failures and left alone.
+func (s *OSSService) SanitizeObjectKeys(config OSSConfig, bucketName string, keys []string) (string, error) {
+	bkt, err := s.openBucket(config, bucketName)
+	if err != nil {
+		return "", err
+	}
+	targets, err := collectObjectTargets(bkt, keys)
+	if err != nil {
+		return "", err
+	}
+
+	problems := make([]objectTarget, 0, len(targets))
+	for _, target := range targets {
+		if objectKeyNeedsEncoding(target.Key) {
+			problems = append(problems, target)
+		}
+	}
+	if len(problems) == 0 {
+		return "", errors.New("no keys in the selection need renaming")
+	}
+
+	profileName := s.resolveTransferProfileName(config)
+	name, key := describeObjectSelection(keys)
+	update := TransferUpdate{
+		Type:   TransferTypeRename,
+		Name:   name,
+		Bucket: bkt.BucketName,
+		Key:    key,
+	}
+	var renamedMu sync.Mutex
+	var renamed []OperationMove
+	recordRenames := func(update TransferUpdate) {
+		if update.Status != TransferStatusSuccess && update.Status != TransferStatusError {
+			return
+		}
+		renamedMu.Lock()
+		defer renamedMu.Unlock()
+		if len(renamed) == 0 {
+			return
+		}
+		sort.Slice(renamed, func(i, j int) bool { return renamed[i].From < renamed[j].From })
+		s.recordOperation(config, OperationEntry{
+			Kind:        OperationKindRename,
+			Description: fmt.Sprintf("rename %d objects with unsafe keys in %s", len(renamed), buildOssPath(bkt.BucketName, key)),
+			Bucket:      bkt.BucketName,
+			Key:         key,
+			DestBucket:  bkt.BucketName,
+			Moves:       renamed,
+			ObjectCount: len(renamed),
+			Undoable:    true,
+		})
+		renamed = nil
+	}
+	return s.enqueueObjectJob(config, update, bkt, problems, func(bkt *oss.Bucket, target objectTarget) error {
+		destKey := sanitizeObjectKey(target.Key)
+		exists, err := bkt.IsObjectExist(destKey)
+		if err != nil {
+			return fmt.Errorf("failed to check %s: %w", destKey, err)
+		}
+		if exists {
+			return fmt.Errorf("%s already exists", destKey)
+		}
+		src := objectCopySource{Bucket: bkt.BucketName, Key: target.Key, Size: target.Size}
+		if err := copyObjectPreservingMetadata(bkt, src, destKey); err != nil {
+			return err
+		}
+		s.invalidateListingCache(profileName, bkt.BucketName, destKey)
+		if err := bkt.DeleteObject(target.Key); err != nil {
+			return err
+		}
+		renamedMu.Lock()
+		renamed = append(renamed, OperationMove{From: target.Key, To: destKey})
+		renamedMu.Unlock()
+		return nil
+	}, recordRenames), nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import "testing"
+
+func TestObjectKeyEncoding(t *testing.T) {
+	tests := []struct {
+		name      string
+		key       string
+		raw       string
+		sanitized string
+	}{
+		{"plain", "photos/2024/a.txt", "", "photos/2024/a.txt"},
+		{"unicode", "照片/文件.txt", "", "照片/文件.txt"},
+		{"folder", "photos/", "", "photos/"},
+		{"trailing space", "a.txt ", encodedObjectKeyPrefix + "a.txt+", "a.txt"},
+		{"leading space in a folder", "photos/ 2024/a.txt", encodedObjectKeyPrefix + "photos%2F+2024%2Fa.txt", "photos/2024/a.txt"},
+		{"blank segment", "a/ /b", encodedObjectKeyPrefix + "a%2F+%2Fb", "a/_/b"},
+		{"carriage return", "a\r.txt", encodedObjectKeyPrefix + "a%0D.txt", "a_.txt"},
+		{"invalid utf-8", "\xff.txt", encodedObjectKeyPrefix + "%FF.txt", "_.txt"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := objectKeyNeedsEncoding(test.key); got != (test.raw != "") {
+				t.Errorf("objectKeyNeedsEncoding() = %v, want %v", got, test.raw != "")
+			}
+			raw := rawObjectKey(test.key)
+			if raw != test.raw {
+				t.Errorf("rawObjectKey() = %q, want %q", raw, test.raw)
+			}
+			if raw != "" {
+				if key, ok := decodeObjectKeyParam(raw); !ok || key != test.key {
+					t.Errorf("decodeObjectKeyParam(%q) = %q, %v, want %q, true", raw, key, ok, test.key)
+				}
+				if key := normalizeObjectKey(raw); key != test.key {
+					t.Errorf("normalizeObjectKey(%q) = %q, want %q", raw, key, test.key)
+				}
+			}
+			sanitized := sanitizeObjectKey(test.key)
+			if sanitized != test.sanitized {
+				t.Errorf("sanitizeObjectKey() = %q, want %q", sanitized, test.sanitized)
+			}
+			if objectKeyNeedsEncoding(sanitized) {
+				t.Errorf("sanitizeObjectKey() = %q still needs encoding", sanitized)
+			}
+		})
+	}
+}
+
+func TestDecodeObjectKeyParamPlain(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"plain key", "a.txt"},
+		{"escaped but not marked", "a%20b.txt"},
+		{"bad escape", encodedObjectKeyPrefix + "%zz"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if key, ok := decodeObjectKeyParam(test.value); ok || key != test.value {
+				t.Errorf("decodeObjectKeyParam() = %q, %v, want %q, false", key, ok, test.value)
+			}
+		})
+	}
+}
+
+func TestDownloadPathAllocator(t *testing.T) {
+	allocator := newDownloadPathAllocator()
+	allocator.reserve("existing.txt")
+	tests := []struct {
+		name     string
+		relative string
+		want     string
+	}{
+		{"free", "a.txt", "a.txt"},
+		{"same name", "a.txt", "a (2).txt"},
+		{"differs only in case", "A.TXT", "A (3).TXT"},
+		{"exists locally", "existing.txt", "existing (2).txt"},
+		{"no extension", "notes", "notes"},
+		{"no extension again", "notes", "notes (2)"},
+		{"in a folder", "dir/a.txt", "dir/a.txt"},
+		{"in a folder again", "dir/a.txt", "dir/a (2).txt"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := allocator.allocate(test.relative); got != test.want {
+				t.Errorf("allocate(%q) = %q, want %q", test.relative, got, test.want)
+			}
+		})
+	}
+}
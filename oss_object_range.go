@@ -0,0 +1,494 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// Ranged reads let the viewer open multi-GB text and log objects anywhere, not just at the
+// start: GetObjectRange reads raw bytes, ReadObjectLines pages whole lines forward or backward
+// from an offset and TailObject follows an object as it grows.
+
+const (
+	objectRangeMaxLength   = 5 * 1024 * 1024
+	objectLinesChunkSize   = 64 * 1024
+	objectLinesMaxLines    = 5000
+	objectLinesDefaultSize = 200
+	// A line longer than this is cut so a file without newlines cannot be read whole.
+	objectLineMaxBytes = 1024 * 1024
+	// A backward page stops reading once this much is buffered, however few lines it holds.
+	objectLinesBackwardMaxBytes = 4 * objectLineMaxBytes
+
+	objectTailDefaultInterval = 2 * time.Second
+	objectTailMinInterval     = 500 * time.Millisecond
+	objectTailMaxRead         = 1024 * 1024
+	objectTailMaxFailures     = 5
+)
+
+type ObjectRangeResult struct {
+	Bucket     string `json:"bucket"`
+	Key        string `json:"key"`
+	Offset     int64  `json:"offset"`
+	Length     int64  `json:"length"`
+	ObjectSize int64  `json:"objectSize"`
+	Text       string `json:"text"`
+	EOF        bool   `json:"eof"`
+}
+
+// ObjectLinesPage is a page of whole lines between StartOffset and EndOffset. The next page
+// forward starts at EndOffset and the previous page ends at StartOffset.
+type ObjectLinesPage struct {
+	Bucket        string   `json:"bucket"`
+	Key           string   `json:"key"`
+	Lines         []string `json:"lines"`
+	StartOffset   int64    `json:"startOffset"`
+	EndOffset     int64    `json:"endOffset"`
+	ObjectSize    int64    `json:"objectSize"`
+	HasMoreBefore bool     `json:"hasMoreBefore"`
+	HasMoreAfter  bool     `json:"hasMoreAfter"`
+}
+
+// ObjectTailEvent is emitted as "object-tail:update" with the lines appended to a followed
+// object. Reset is set when the object shrank or was replaced and Lines restart from its end;
+// Skipped is set when more was appended between two polls than is read at once.
+type ObjectTailEvent struct {
+	TaskID      string   `json:"taskId"`
+	Bucket      string   `json:"bucket"`
+	Key         string   `json:"key"`
+	Lines       []string `json:"lines"`
+	StartOffset int64    `json:"startOffset"`
+	EndOffset   int64    `json:"endOffset"`
+	ObjectSize  int64    `json:"objectSize"`
+	Reset       bool     `json:"reset,omitempty"`
+	Skipped     bool     `json:"skipped,omitempty"`
+	Done        bool     `json:"done,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+type objectSizeInfo struct {
+	size       int64
+	etag       string
+	appendable bool
+}
+
+func headObjectSize(bkt *oss.Bucket, key string) (objectSizeInfo, error) {
+	header, err := bkt.GetObjectDetailedMeta(key)
+	if err != nil {
+		return objectSizeInfo{}, fmt.Errorf("failed to read object size: %w", err)
+	}
+	size, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+	if err != nil {
+		return objectSizeInfo{}, fmt.Errorf("failed to read object size: %w", err)
+	}
+	return objectSizeInfo{
+		size:       size,
+		etag:       strings.Trim(header.Get("ETag"), "\""),
+		appendable: strings.EqualFold(header.Get("X-Oss-Object-Type"), "Appendable"),
+	}, nil
+}
+
+// readObjectRange reads the bytes in [start, end) of key.
+func readObjectRange(bkt *oss.Bucket, key string, start int64, end int64) ([]byte, error) {
+	if end <= start {
+		return nil, nil
+	}
+	body, err := bkt.GetObject(key, oss.Range(start, end-1))
+	if err != nil {
+		return nil, fmt.Errorf("range read failed: %w", err)
+	}
+	defer body.Close()
+	data, err := io.ReadAll(io.LimitReader(body, end-start))
+	if err != nil {
+		return nil, fmt.Errorf("range read failed: %w", err)
+	}
+	return data, nil
+}
+
+func objectLineText(line []byte) string {
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	return strings.ToValidUTF8(string(line), "\uFFFD")
+}
+
+func (s *OSSService) openObjectForRange(config OSSConfig, bucketName string, key string) (*oss.Bucket, string, error) {
+	bkt, err := s.openBucket(config, bucketName)
+	if err != nil {
+		return nil, "", err
+	}
+	key = normalizeObjectKey(key)
+	if key == "" || strings.HasSuffix(key, "/") {
+		return nil, "", errors.New("object key is required")
+	}
+	return bkt, key, nil
+}
+
+// GetObjectRange reads length bytes of key starting at offset with a ranged GET. A negative
+// offset counts back from the end of the object; length is capped at 5 MB.
+func (s *OSSService) GetObjectRange(config OSSConfig, bucketName string, key string, offset int64, length int64) (ObjectRangeResult, error) {
+	bkt, key, err := s.openObjectForRange(config, bucketName, key)
+	if err != nil {
+		return ObjectRangeResult{}, err
+	}
+	if length <= 0 || length > objectRangeMaxLength {
+		length = objectRangeMaxLength
+	}
+	info, err := headObjectSize(bkt, key)
+	if err != nil {
+		return ObjectRangeResult{}, err
+	}
+	if offset < 0 {
+		offset = info.size + offset
+		if offset < 0 {
+			offset = 0
+		}
+	}
+	if offset > info.size {
+		offset = info.size
+	}
+	end := offset + length
+	if end > info.size {
+		end = info.size
+	}
+	data, err := readObjectRange(bkt, key, offset, end)
+	if err != nil {
+		return ObjectRangeResult{}, err
+	}
+	return ObjectRangeResult{
+		Bucket:     bkt.BucketName,
+		Key:        objectKeyForClient(key),
+		Offset:     offset,
+		Length:     int64(len(data)),
+		ObjectSize: info.size,
+		Text:       strings.ToValidUTF8(string(data), "\uFFFD"),
+		EOF:        offset+int64(len(data)) >= info.size,
+	}, nil
+}
+
+// ReadObjectLines returns up to maxLines whole lines of key. Forward pages start at the first
+// line beginning at or after offset; backward pages end at offset, and a negative offset means
+// the end of the object, which is how a log is opened at its tail.
+func (s *OSSService) ReadObjectLines(config OSSConfig, bucketName string, key string, offset int64, backward bool, maxLines int) (ObjectLinesPage, error) {
+	bkt, key, err := s.openObjectForRange(config, bucketName, key)
+	if err != nil {
+		return ObjectLinesPage{}, err
+	}
+	if maxLines <= 0 {
+		maxLines = objectLinesDefaultSize
+	}
+	if maxLines > objectLinesMaxLines {
+		maxLines = objectLinesMaxLines
+	}
+	info, err := headObjectSize(bkt, key)
+	if err != nil {
+		return ObjectLinesPage{}, err
+	}
+	if offset < 0 || offset > info.size {
+		offset = info.size
+	}
+
+	var page ObjectLinesPage
+	if backward {
+		page, _, err = readLinesBackward(bkt, key, offset, info.size, maxLines)
+	} else {
+		page, err = readLinesForward(bkt, key, offset, info.size, maxLines)
+	}
+	if err != nil {
+		return ObjectLinesPage{}, err
+	}
+	page.Bucket = bkt.BucketName
+	page.Key = objectKeyForClient(key)
+	page.ObjectSize = info.size
+	page.HasMoreBefore = page.StartOffset > 0
+	page.HasMoreAfter = page.EndOffset < info.size
+	return page, nil
+}
+
+func readLinesForward(bkt *oss.Bucket, key string, offset int64, size int64, maxLines int) (ObjectLinesPage, error) {
+	// An offset inside a line skips to the start of the next one.
+	skipPartial := false
+	if offset > 0 && offset < size {
+		prev, err := readObjectRange(bkt, key, offset-1, offset)
+		if err != nil {
+			return ObjectLinesPage{}, err
+		}
+		skipPartial = len(prev) == 1 && prev[0] != '\n'
+	}
+
+	page := ObjectLinesPage{Lines: []string{}, StartOffset: offset}
+	lineStart := offset
+	pos := offset
+	var pending []byte
+	for len(page.Lines) < maxLines && pos < size {
+		end := pos + objectLinesChunkSize
+		if end > size {
+			end = size
+		}
+		chunk, err := readObjectRange(bkt, key, pos, end)
+		if err != nil {
+			return ObjectLinesPage{}, err
+		}
+		if len(chunk) == 0 {
+			break
+		}
+		pending = append(pending, chunk...)
+		pos += int64(len(chunk))
+
+		for len(page.Lines) < maxLines {
+			i := bytes.IndexByte(pending, '\n')
+			if i < 0 {
+				if len(pending) < objectLineMaxBytes {
+					break
+				}
+				i = objectLineMaxBytes - 1
+			}
+			line := pending[:i]
+			consumed := int64(i + 1)
+			if pending[i] != '\n' {
+				line = pending[:i+1]
+			}
+			pending = pending[consumed:]
+			lineStart += consumed
+			if skipPartial {
+				skipPartial = false
+				page.StartOffset = lineStart
+				continue
+			}
+			page.Lines = append(page.Lines, objectLineText(line))
+		}
+	}
+	if len(page.Lines) < maxLines && pos >= size && len(pending) > 0 {
+		if skipPartial {
+			page.StartOffset = size
+		} else {
+			page.Lines = append(page.Lines, objectLineText(pending))
+		}
+		lineStart = size
+	}
+	page.EndOffset = lineStart
+	return page, nil
+}
+
+// readLinesBackward also returns the length of the last line when it does not end in a
+// newline, which is 0 when the page ends at a line break.
+func readLinesBackward(bkt *oss.Bucket, key string, offset int64, size int64, maxLines int) (ObjectLinesPage, int64, error) {
+	page := ObjectLinesPage{Lines: []string{}, StartOffset: offset, EndOffset: offset}
+	if offset == 0 {
+		return page, 0, nil
+	}
+
+	// Chunks are collected last to first and joined once.
+	var chunks [][]byte
+	buffered := 0
+	newlines := 0
+	pos := offset
+	trailingNewline := false
+	for pos > 0 {
+		start := pos - objectLinesChunkSize
+		if start < 0 {
+			start = 0
+		}
+		chunk, err := readObjectRange(bkt, key, start, pos)
+		if err != nil {
+			return ObjectLinesPage{}, 0, err
+		}
+		if pos == offset && len(chunk) > 0 && chunk[len(chunk)-1] == '\n' {
+			trailingNewline = true
+			newlines--
+		}
+		chunks = append(chunks, chunk)
+		buffered += len(chunk)
+		newlines += bytes.Count(chunk, []byte("\n"))
+		pos = start
+
+		if newlines >= maxLines || buffered >= objectLinesBackwardMaxBytes {
+			break
+		}
+	}
+
+	for i, j := 0, len(chunks)-1; i < j; i, j = i+1, j-1 {
+		chunks[i], chunks[j] = chunks[j], chunks[i]
+	}
+	text := bytes.Join(chunks, nil)
+	if trailingNewline {
+		text = text[:len(text)-1]
+	}
+	segments := bytes.Split(text, []byte("\n"))
+	if pos > 0 && len(segments) > 1 {
+		// The first segment starts before the bytes read and is incomplete.
+		segments = segments[1:]
+	}
+	if len(segments) > maxLines {
+		segments = segments[len(segments)-maxLines:]
+	}
+
+	partialBytes := int64(0)
+	if !trailingNewline {
+		partialBytes = int64(len(segments[len(segments)-1]))
+	}
+	consumed := int64(len(segments) - 1)
+	for _, segment := range segments {
+		consumed += int64(len(segment))
+		if len(segment) > objectLineMaxBytes {
+			segment = segment[:objectLineMaxBytes]
+		}
+		page.Lines = append(page.Lines, objectLineText(segment))
+	}
+	if trailingNewline {
+		consumed++
+	}
+	page.StartOffset = offset - consumed
+	return page, partialBytes, nil
+}
+
+// readTailLines returns the last whole lines of an object of the given size and the
+// offset just after its last newline, so a partial last line is read again once complete.
+func readTailLines(bkt *oss.Bucket, key string, size int64, lines int) (ObjectLinesPage, int64, error) {
+	page, partialBytes, err := readLinesBackward(bkt, key, size, size, lines)
+	if err != nil {
+		return ObjectLinesPage{}, 0, err
+	}
+	if partialBytes > 0 && len(page.Lines) > 0 {
+		page.Lines = page.Lines[:len(page.Lines)-1]
+	}
+	return page, size - partialBytes, nil
+}
+
+// TailObject follows key like tail -f: it returns the last lines of the object and then
+// polls its size every interval ("2s" by default), emitting appended lines as
+// "object-tail:update" until CancelTask is called with the returned TaskID.
+func (s *OSSService) TailObject(config OSSConfig, bucketName string, key string, lines int, interval string) (ObjectTailEvent, error) {
+	bkt, key, err := s.openObjectForRange(config, bucketName, key)
+	if err != nil {
+		return ObjectTailEvent{}, err
+	}
+	every := objectTailDefaultInterval
+	if interval = strings.TrimSpace(interval); interval != "" {
+		if every, err = time.ParseDuration(interval); err != nil {
+			return ObjectTailEvent{}, fmt.Errorf("invalid interval: %w", err)
+		}
+		if every < objectTailMinInterval {
+			every = objectTailMinInterval
+		}
+	}
+	if lines <= 0 {
+		lines = objectLinesDefaultSize
+	}
+	if lines > objectLinesMaxLines {
+		lines = objectLinesMaxLines
+	}
+
+	info, err := headObjectSize(bkt, key)
+	if err != nil {
+		return ObjectTailEvent{}, err
+	}
+	page, endOffset, err := readTailLines(bkt, key, info.size, lines)
+	if err != nil {
+		return ObjectTailEvent{}, err
+	}
+
+	taskID, ctx := s.startTask("object-tail")
+	event := ObjectTailEvent{
+		TaskID:      taskID,
+		Bucket:      bkt.BucketName,
+		Key:         objectKeyForClient(key),
+		Lines:       page.Lines,
+		StartOffset: page.StartOffset,
+		EndOffset:   endOffset,
+		ObjectSize:  info.size,
+	}
+	go s.runObjectTail(ctx, bkt, key, event, info, lines, every)
+	return event, nil
+}
+
+func (s *OSSService) runObjectTail(ctx context.Context, bkt *oss.Bucket, key string, event ObjectTailEvent, last objectSizeInfo, lines int, every time.Duration) {
+	defer s.finishTask(event.TaskID)
+
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	// offset is where the next unread line starts; a partial last line is read again.
+	offset := event.EndOffset
+	failures := 0
+	for {
+		select {
+		case <-ctx.Done():
+			s.emitEvent("object-tail:update", ObjectTailEvent{TaskID: event.TaskID, Bucket: event.Bucket, Key: event.Key, Lines: []string{}, StartOffset: offset, EndOffset: offset, ObjectSize: last.size, Done: true})
+			return
+		case <-ticker.C:
+		}
+
+		info, err := headObjectSize(bkt, key)
+		if err == nil {
+			var update ObjectTailEvent
+			update, err = readTailUpdate(bkt, key, offset, last, info, lines)
+			if err == nil {
+				last = info
+				if update.Reset || len(update.Lines) > 0 {
+					offset = update.EndOffset
+					update.TaskID, update.Bucket, update.Key = event.TaskID, event.Bucket, event.Key
+					s.emitEvent("object-tail:update", update)
+				}
+			}
+		}
+		if err == nil {
+			failures = 0
+			continue
+		}
+		failures++
+		if failures >= objectTailMaxFailures {
+			s.emitEvent("object-tail:update", ObjectTailEvent{TaskID: event.TaskID, Bucket: event.Bucket, Key: event.Key, Lines: []string{}, StartOffset: offset, EndOffset: offset, ObjectSize: last.size, Done: true, Error: err.Error()})
+			return
+		}
+	}
+}
+
+// readTailUpdate returns the whole lines appended after offset, or the last lines of the
+// object with Reset set when it shrank or a non-appendable object was replaced.
+func readTailUpdate(bkt *oss.Bucket, key string, offset int64, last objectSizeInfo, info objectSizeInfo, lines int) (ObjectTailEvent, error) {
+	replaced := info.size < offset || (!info.appendable && info.etag != last.etag)
+	if replaced {
+		page, endOffset, err := readTailLines(bkt, key, info.size, lines)
+		if err != nil {
+			return ObjectTailEvent{}, err
+		}
+		return ObjectTailEvent{Lines: page.Lines, StartOffset: page.StartOffset, EndOffset: endOffset, ObjectSize: info.size, Reset: true}, nil
+	}
+
+	update := ObjectTailEvent{Lines: []string{}, StartOffset: offset, EndOffset: offset, ObjectSize: info.size}
+	if info.size == offset {
+		return update, nil
+	}
+	start := offset
+	if info.size-start > objectTailMaxRead {
+		start = info.size - objectTailMaxRead
+		update.Skipped = true
+	}
+	data, err := readObjectRange(bkt, key, start, info.size)
+	if err != nil {
+		return ObjectTailEvent{}, err
+	}
+	if update.Skipped {
+		// Drop the partial line the jump landed in.
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+			start += int64(i + 1)
+		}
+	}
+	update.StartOffset = start
+	complete := bytes.LastIndexByte(data, '\n')
+	if complete < 0 {
+		update.EndOffset = start
+		return update, nil
+	}
+	for _, line := range bytes.Split(data[:complete], []byte("\n")) {
+		update.Lines = append(update.Lines, objectLineText(line))
+	}
+	update.EndOffset = start + int64(complete) + 1
+	return update, nil
+}
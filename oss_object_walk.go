@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// objectTarget is a single object resolved from a user selection of files and folders.
+type objectTarget struct {
+	Key          string
+	Size         int64
+	StorageClass string
+	ETag         string
+	LastModified time.Time
+	RestoreInfo  string
+}
+
+func objectTargetFromProperties(object oss.ObjectProperties) objectTarget {
+	return objectTarget{
+		Key:          object.Key,
+		Size:         object.Size,
+		StorageClass: object.StorageClass,
+		ETag:         strings.Trim(object.ETag, "\""),
+		LastModified: object.LastModified,
+		RestoreInfo:  object.RestoreInfo,
+	}
+}
+
+func objectTargetFromHeader(key string, header http.Header) objectTarget {
+	size, _ := strconv.ParseInt(header.Get(oss.HTTPHeaderContentLength), 10, 64)
+	storageClass := header.Get(oss.HTTPHeaderOssStorageClass)
+	if storageClass == "" {
+		storageClass = string(oss.StorageStandard)
+	}
+	lastModified, _ := http.ParseTime(header.Get(oss.HTTPHeaderLastModified))
+	return objectTarget{
+		Key:          key,
+		Size:         size,
+		StorageClass: storageClass,
+		ETag:         strings.Trim(header.Get(oss.HTTPHeaderEtag), "\""),
+		LastModified: lastModified,
+		RestoreInfo:  header.Get(ossRestoreHeader),
+	}
+}
+
+// walkObjectsUnderPrefix lists every object below prefix (recursively) and calls fn for each one.
+func walkObjectsUnderPrefix(bkt *oss.Bucket, prefix string, fn func(object oss.ObjectProperties) error) error {
+	marker := ""
+	for {
+		lor, err := bkt.ListObjects(
+			oss.Prefix(prefix),
+			oss.Marker(marker),
+			oss.MaxKeys(1000),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to list folder objects: %w", err)
+		}
+
+		for _, object := range lor.Objects {
+			if !strings.HasPrefix(object.Key, prefix) {
+				continue
+			}
+			if err := fn(object); err != nil {
+				return err
+			}
+		}
+
+		if !lor.IsTruncated || lor.NextMarker == "" {
+			return nil
+		}
+		marker = lor.NextMarker
+	}
+}
+
+// collectObjectTargets resolves a selection of keys into objects. Keys ending with "/" are
+// treated as folders and expanded recursively; folder placeholder objects are skipped.
+func collectObjectTargets(bkt *oss.Bucket, keys []string) ([]objectTarget, error) {
+	seen := make(map[string]struct{}, len(keys))
+	out := make([]objectTarget, 0, len(keys))
+	add := func(target objectTarget) {
+		if target.Key == "" || strings.HasSuffix(target.Key, "/") {
+			return
+		}
+		if _, ok := seen[target.Key]; ok {
+			return
+		}
+		seen[target.Key] = struct{}{}
+		out = append(out, target)
+	}
+
+	for _, key := range keys {
+		key = normalizeObjectKey(key)
+		if key == "" {
+			continue
+		}
+
+		if strings.HasSuffix(key, "/") {
+			err := walkObjectsUnderPrefix(bkt, key, func(object oss.ObjectProperties) error {
+				add(objectTargetFromProperties(object))
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		header, err := bkt.GetObjectDetailedMeta(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read object %s: %w", key, err)
+		}
+		add(objectTargetFromHeader(key, header))
+	}
+
+	if len(out) == 0 {
+		return nil, errors.New("no objects found in selection")
+	}
+	return out, nil
+}
+
+// describeObjectSelection returns a display name and the key of the first selected item,
+// for example "photos/" and "2024/photos/" for a folder selection.
+func describeObjectSelection(keys []string) (string, string) {
+	for _, key := range keys {
+		key = normalizeObjectKey(key)
+		if key == "" {
+			continue
+		}
+		name := path.Base(strings.TrimSuffix(key, "/"))
+		if strings.HasSuffix(key, "/") {
+			name += "/"
+		}
+		if len(keys) > 1 {
+			name = fmt.Sprintf("%s and %d more", name, len(keys)-1)
+		}
+		return name, key
+	}
+	return "", ""
+}
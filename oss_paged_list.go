@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
@@ -31,7 +32,7 @@ func sdkEndpointForConfig(config OSSConfig) (string, error) {
 	return endpoint, nil
 }
 
-func sdkClientFromConfig(config OSSConfig) (*oss.Client, error) {
+func sdkClientFromConfig(config OSSConfig, extra ...oss.ClientOption) (*oss.Client, error) {
 	endpoint, err := sdkEndpointForConfig(config)
 	if err != nil {
 		return nil, err
@@ -42,10 +43,28 @@ func sdkClientFromConfig(config OSSConfig) (*oss.Client, error) {
 	if region != "" {
 		options = append(options, oss.Region(region))
 	}
+	options = append(options, extra...)
 
 	return oss.New(endpoint, config.AccessKeyID, config.AccessKeySecret, options...)
 }
 
+// openBucket creates an SDK client for config and opens bucketName.
+func (s *OSSService) openBucket(config OSSConfig, bucketName string) (*oss.Bucket, error) {
+	bucketName = normalizeTransferBucket(bucketName)
+	if bucketName == "" {
+		return nil, errors.New("bucket is empty")
+	}
+	client, err := sdkClientFromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	bkt, err := client.Bucket(bucketName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bucket: %w", err)
+	}
+	return bkt, nil
+}
+
 func sdkSmokeTestListBuckets(config OSSConfig) error {
 	client, err := sdkClientFromConfig(config)
 	if err != nil {
@@ -63,6 +82,12 @@ func formatObjectLastModified(ts time.Time) string {
 }
 
 func normalizeObjectPrefix(prefix string) string {
+	if raw, ok := decodeObjectKeyParam(prefix); ok {
+		if raw != "" && !strings.HasSuffix(raw, "/") {
+			raw += "/"
+		}
+		return raw
+	}
 	prefix = strings.TrimSpace(prefix)
 	prefix = strings.TrimLeft(prefix, "/")
 	if prefix == "" {
@@ -87,13 +112,24 @@ func buildOssPath(bucketName string, key string) string {
 }
 
 func (s *OSSService) ListObjectsPage(config OSSConfig, bucketName string, prefix string, marker string, maxKeys int) (ObjectListPageResult, error) {
+	result, err := s.listObjectsPage(config, bucketName, prefix, marker, maxKeys)
+	if err != nil {
+		return ObjectListPageResult{}, err
+	}
+	// The cache write reads the profile list and rewrites the folder's cache file; keep it
+	// off the navigation path.
+	go s.storeListingPage(config, bucketName, prefix, marker, maxKeys, result)
+	return result, nil
+}
+
+func (s *OSSService) listObjectsPage(config OSSConfig, bucketName string, prefix string, marker string, maxKeys int) (ObjectListPageResult, error) {
 	bucketName = strings.TrimSpace(bucketName)
 	if bucketName == "" {
 		return ObjectListPageResult{}, fmt.Errorf("bucket name is required")
 	}
 
 	prefix = normalizeObjectPrefix(prefix)
-	marker = strings.TrimSpace(marker)
+	marker = normalizeMarkerParam(marker)
 
 	if maxKeys <= 0 {
 		maxKeys = 200
@@ -134,9 +170,10 @@ func (s *OSSService) ListObjectsPage(config OSSConfig, bucketName string, prefix
 		}
 
 		folders = append(folders, ObjectInfo{
-			Name: relative,
-			Path: buildOssPath(bucketName, prefix+relative+"/"),
-			Type: "Folder",
+			Name:   relative,
+			Path:   buildOssPath(bucketName, prefix+relative+"/"),
+			RawKey: rawObjectKey(prefix + relative + "/"),
+			Type:   "Folder",
 		})
 	}
 
@@ -158,15 +195,24 @@ func (s *OSSService) ListObjectsPage(config OSSConfig, bucketName string, prefix
 			continue
 		}
 
+		restoreState, restoreExpiry := objectRestoreState(object.StorageClass, object.RestoreInfo)
 		files = append(files, ObjectInfo{
-			Name:         relative,
-			Path:         buildOssPath(bucketName, key),
-			Size:         object.Size,
-			Type:         "File",
-			LastModified: formatObjectLastModified(object.LastModified),
-			StorageClass: object.StorageClass,
+			Name:          relative,
+			Path:          buildOssPath(bucketName, key),
+			RawKey:        rawObjectKey(key),
+			Size:          object.Size,
+			Type:          "File",
+			LastModified:  formatObjectLastModified(object.LastModified),
+			StorageClass:  object.StorageClass,
+			ETag:          strings.Trim(object.ETag, "\""),
+			RestoreState:  restoreState,
+			RestoreExpiry: restoreExpiry,
+			IsSymlink:     object.Type == ossObjectTypeSymlink,
 		})
 	}
+	fillSymlinkTargets(bucket, prefix, files)
+
+	s.fillCachedFolderSizes(config, bucketName, prefix, folders)
 
 	sort.Slice(folders, func(i, j int) bool { return folders[i].Name < folders[j].Name })
 	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
@@ -177,7 +223,7 @@ func (s *OSSService) ListObjectsPage(config OSSConfig, bucketName string, prefix
 
 	return ObjectListPageResult{
 		Items:       items,
-		NextMarker:  lor.NextMarker,
+		NextMarker:  objectKeyForClient(lor.NextMarker),
 		IsTruncated: lor.IsTruncated,
 	}, nil
 }
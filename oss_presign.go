@@ -0,0 +1,211 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+const (
+	defaultPresignExpires = 15 * time.Minute
+	maxPresignV4Expires   = 7 * 24 * time.Hour
+
+	// OSS accepts single-connection traffic limits between 100 KB/s and 100 MB/s.
+	minPresignTrafficLimitKBps = 100
+	maxPresignTrafficLimitKBps = 100 * 1024
+)
+
+// PresignOptions configures PresignObjectWithOptions. Empty fields keep the defaults of
+// PresignObject: a GET URL that expires after 15 minutes.
+type PresignOptions struct {
+	Method                     string `json:"method"`  // "GET", "PUT" or "HEAD"
+	Expires                    string `json:"expires"` // Go duration such as "15m" or "24h"
+	ContentType                string `json:"contentType"`
+	ResponseContentType        string `json:"responseContentType"`
+	ResponseContentDisposition string `json:"responseContentDisposition"`
+	DownloadFilename           string `json:"downloadFilename"` // Shortcut for an attachment content disposition
+	Process                    string `json:"process"`          // x-oss-process, e.g. "image/resize,w_800"
+	VersionID                  string `json:"versionId"`
+	TrafficLimitKBps           int64  `json:"trafficLimitKBps"`
+	SignatureV4                bool   `json:"signatureV4"`
+}
+
+// PresignResult is a signed URL together with the request headers the caller must send with
+// it; a PUT URL signed with a content type only accepts uploads that send that Content-Type.
+type PresignResult struct {
+	URL         string            `json:"url"`
+	Method      string            `json:"method"`
+	ExpiresAtMs int64             `json:"expiresAtMs"`
+	Headers     map[string]string `json:"headers,omitempty"`
+}
+
+func parsePresignExpires(expiresDuration string) (time.Duration, error) {
+	expiresDuration = strings.TrimSpace(expiresDuration)
+	if expiresDuration == "" {
+		return defaultPresignExpires, nil
+	}
+
+	expires, err := time.ParseDuration(expiresDuration)
+	if err != nil {
+		return 0, fmt.Errorf("invalid expires duration: %w", err)
+	}
+	if expires < 0 {
+		return 0, fmt.Errorf("invalid expires duration: must be non-negative")
+	}
+	return expires, nil
+}
+
+// signObjectURL signs a URL for object. Slashes in the object path are kept readable.
+func signObjectURL(config OSSConfig, bucket string, object string, method oss.HTTPMethod, expires time.Duration, clientOptions []oss.ClientOption, options ...oss.Option) (string, error) {
+	bucket = strings.TrimSpace(bucket)
+	object = normalizeObjectKey(object)
+
+	if bucket == "" {
+		return "", fmt.Errorf("bucket name is required")
+	}
+	if object == "" {
+		return "", fmt.Errorf("object key is required")
+	}
+
+	client, err := sdkClientFromConfig(config, clientOptions...)
+	if err != nil {
+		return "", err
+	}
+	bkt, err := client.Bucket(bucket)
+	if err != nil {
+		return "", fmt.Errorf("failed to open bucket: %w", err)
+	}
+	return signBucketURL(bkt, object, method, expires, options...)
+}
+
+func signBucketURL(bkt *oss.Bucket, object string, method oss.HTTPMethod, expires time.Duration, options ...oss.Option) (string, error) {
+	timeoutSeconds := int64(expires.Seconds())
+	signedURL, err := bkt.SignURL(object, method, timeoutSeconds, options...)
+	if err != nil {
+		return "", fmt.Errorf("presign failed: %w", err)
+	}
+
+	parts := strings.SplitN(signedURL, "?", 2)
+	parts[0] = strings.ReplaceAll(parts[0], "%2F", "/")
+	if len(parts) == 2 {
+		return parts[0] + "?" + parts[1], nil
+	}
+	return parts[0], nil
+}
+
+// presignClientOptions returns the client options for the requested signature version.
+func presignClientOptions(config OSSConfig, expires time.Duration, signatureV4 bool) ([]oss.ClientOption, error) {
+	if !signatureV4 {
+		return nil, nil
+	}
+	if normalizeRegion(config.Region) == "" {
+		return nil, errors.New("signature v4 requires a region")
+	}
+	if expires > maxPresignV4Expires {
+		return nil, errors.New("signature v4 URLs expire after at most 7 days")
+	}
+	return []oss.ClientOption{oss.AuthVersion(oss.AuthV4)}, nil
+}
+
+func normalizePresignMethod(method string) (oss.HTTPMethod, error) {
+	switch strings.ToUpper(strings.TrimSpace(method)) {
+	case "", "GET":
+		return oss.HTTPGet, nil
+	case "PUT":
+		return oss.HTTPPut, nil
+	case "HEAD":
+		return oss.HTTPHead, nil
+	default:
+		return "", fmt.Errorf("unsupported presign method: %s", method)
+	}
+}
+
+// attachmentDisposition builds a Content-Disposition that forces a download as filename,
+// with an RFC 5987 form for names that are not plain ASCII.
+func attachmentDisposition(filename string) string {
+	filename = strings.NewReplacer("\r", "", "\n", "").Replace(strings.TrimSpace(filename))
+	if disposition := mime.FormatMediaType("attachment", map[string]string{"filename": filename}); disposition != "" {
+		return disposition
+	}
+	return "attachment"
+}
+
+// PresignObjectWithOptions signs a URL for object with a choice of method, expiry, response
+// header overrides, image processing, version, traffic limit and signature version.
+func (s *OSSService) PresignObjectWithOptions(config OSSConfig, bucket string, object string, options PresignOptions) (PresignResult, error) {
+	method, err := normalizePresignMethod(options.Method)
+	if err != nil {
+		return PresignResult{}, err
+	}
+	expires, err := parsePresignExpires(options.Expires)
+	if err != nil {
+		return PresignResult{}, err
+	}
+
+	clientOptions, err := presignClientOptions(config, expires, options.SignatureV4)
+	if err != nil {
+		return PresignResult{}, err
+	}
+
+	headers := map[string]string{}
+	signOptions := []oss.Option{}
+	if contentType := strings.TrimSpace(options.ContentType); contentType != "" {
+		if method != oss.HTTPPut {
+			return PresignResult{}, errors.New("content type can only be set for PUT URLs")
+		}
+		signOptions = append(signOptions, oss.ContentType(contentType))
+		headers["Content-Type"] = contentType
+	}
+
+	if method == oss.HTTPPut {
+		if options.ResponseContentType != "" || options.ResponseContentDisposition != "" || options.DownloadFilename != "" || options.Process != "" || options.VersionID != "" {
+			return PresignResult{}, errors.New("response overrides, processing and versions only apply to GET URLs")
+		}
+	}
+
+	if value := strings.TrimSpace(options.ResponseContentType); value != "" {
+		signOptions = append(signOptions, oss.ResponseContentType(value))
+	}
+	disposition := strings.TrimSpace(options.ResponseContentDisposition)
+	if filename := strings.TrimSpace(options.DownloadFilename); filename != "" {
+		if disposition != "" {
+			return PresignResult{}, errors.New("set either a download filename or a content disposition")
+		}
+		disposition = attachmentDisposition(filename)
+	}
+	if disposition != "" {
+		signOptions = append(signOptions, oss.ResponseContentDisposition(disposition))
+	}
+	if process := strings.TrimSpace(options.Process); process != "" {
+		signOptions = append(signOptions, oss.Process(process))
+	}
+	if versionID := strings.TrimSpace(options.VersionID); versionID != "" {
+		signOptions = append(signOptions, oss.VersionId(versionID))
+	}
+	if limit := options.TrafficLimitKBps; limit != 0 {
+		if limit < minPresignTrafficLimitKBps || limit > maxPresignTrafficLimitKBps {
+			return PresignResult{}, fmt.Errorf("traffic limit must be between %d KB/s and %d KB/s", minPresignTrafficLimitKBps, maxPresignTrafficLimitKBps)
+		}
+		// The limit is passed in bit/s.
+		signOptions = append(signOptions, oss.TrafficLimitParam(limit*1024*8))
+	}
+
+	signedAt := time.Now()
+	signedURL, err := signObjectURL(config, bucket, object, method, expires, clientOptions, signOptions...)
+	if err != nil {
+		return PresignResult{}, err
+	}
+	result := PresignResult{
+		URL:         signedURL,
+		Method:      string(method),
+		ExpiresAtMs: signedAt.Add(expires).UnixMilli(),
+	}
+	if len(headers) > 0 {
+		result.Headers = headers
+	}
+	return result, nil
+}
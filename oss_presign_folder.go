@@ -0,0 +1,304 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+const defaultPresignFolderLimit = 10000
+
+// PresignFolderOptions configures PresignFolder. The index is written to LocalPath, to
+// UploadKey in the same bucket, or both; with neither set only the manifest is returned.
+type PresignFolderOptions struct {
+	Format        string `json:"format"` // "csv" (default), "json" or "html"
+	LocalPath     string `json:"localPath"`
+	UploadKey     string `json:"uploadKey"`
+	CustomDomain  string `json:"customDomain"` // Bound CNAME such as "https://files.example.com"
+	ForceDownload bool   `json:"forceDownload"`
+	SignatureV4   bool   `json:"signatureV4"`
+	MaxObjects    int    `json:"maxObjects"`
+	Note          string `json:"note"` // Stored with the links in the share registry
+}
+
+type PresignFolderEntry struct {
+	Name        string `json:"name"` // Key relative to the shared prefix
+	Key         string `json:"key"`
+	Size        int64  `json:"size"`
+	URL         string `json:"url"`
+	ExpiresAtMs int64  `json:"expiresAtMs"`
+}
+
+type PresignFolderResult struct {
+	Bucket      string               `json:"bucket"`
+	Prefix      string               `json:"prefix"`
+	Format      string               `json:"format"`
+	Count       int                  `json:"count"`
+	TotalBytes  int64                `json:"totalBytes"`
+	ExpiresAtMs int64                `json:"expiresAtMs"`
+	LocalPath   string               `json:"localPath,omitempty"`
+	UploadedKey string               `json:"uploadedKey,omitempty"`
+	Entries     []PresignFolderEntry `json:"entries"`
+}
+
+// sdkClientForCustomDomain creates a client that signs URLs for a domain bound to the bucket.
+func sdkClientForCustomDomain(config OSSConfig, domain string, extra ...oss.ClientOption) (*oss.Client, error) {
+	domain = strings.TrimRight(strings.TrimSpace(domain), "/")
+	if domain == "" {
+		return nil, errors.New("custom domain is empty")
+	}
+	if !strings.HasPrefix(domain, "http://") && !strings.HasPrefix(domain, "https://") {
+		domain = "https://" + domain
+	}
+	options := []oss.ClientOption{oss.UseCname(true)}
+	if region := normalizeRegion(config.Region); region != "" {
+		options = append(options, oss.Region(region))
+	}
+	options = append(options, extra...)
+	return oss.New(domain, config.AccessKeyID, config.AccessKeySecret, options...)
+}
+
+func normalizePresignFolderFormat(format string) (string, error) {
+	switch format = strings.ToLower(strings.TrimSpace(format)); format {
+	case "":
+		return "csv", nil
+	case "csv", "json", "html":
+		return format, nil
+	default:
+		return "", fmt.Errorf("unsupported index format: %s", format)
+	}
+}
+
+// PresignFolder presigns every object below prefix and writes an index of name, size, URL
+// and expiry as CSV, JSON or an HTML page.
+func (s *OSSService) PresignFolder(config OSSConfig, bucketName string, prefix string, expiresDuration string, options PresignFolderOptions) (PresignFolderResult, error) {
+	format, err := normalizePresignFolderFormat(options.Format)
+	if err != nil {
+		return PresignFolderResult{}, err
+	}
+	expires, err := parsePresignExpires(expiresDuration)
+	if err != nil {
+		return PresignFolderResult{}, err
+	}
+	clientOptions, err := presignClientOptions(config, expires, options.SignatureV4)
+	if err != nil {
+		return PresignFolderResult{}, err
+	}
+	limit := options.MaxObjects
+	if limit <= 0 {
+		limit = defaultPresignFolderLimit
+	}
+
+	bkt, err := s.openBucket(config, bucketName)
+	if err != nil {
+		return PresignFolderResult{}, err
+	}
+	signer := bkt
+	if strings.TrimSpace(options.CustomDomain) != "" {
+		client, err := sdkClientForCustomDomain(config, options.CustomDomain, clientOptions...)
+		if err != nil {
+			return PresignFolderResult{}, err
+		}
+		if signer, err = client.Bucket(bkt.BucketName); err != nil {
+			return PresignFolderResult{}, fmt.Errorf("failed to open bucket: %w", err)
+		}
+	} else if len(clientOptions) > 0 {
+		client, err := sdkClientFromConfig(config, clientOptions...)
+		if err != nil {
+			return PresignFolderResult{}, err
+		}
+		if signer, err = client.Bucket(bkt.BucketName); err != nil {
+			return PresignFolderResult{}, fmt.Errorf("failed to open bucket: %w", err)
+		}
+	}
+
+	prefix = normalizeObjectPrefix(prefix)
+	expiresAt := time.Now().Add(expires).UnixMilli()
+	result := PresignFolderResult{
+		Bucket:      bkt.BucketName,
+		Prefix:      prefix,
+		Format:      format,
+		ExpiresAtMs: expiresAt,
+		Entries:     []PresignFolderEntry{},
+	}
+
+	errLimit := errors.New("limit reached")
+	err = walkObjectsUnderPrefix(bkt, prefix, func(object oss.ObjectProperties) error {
+		if strings.HasSuffix(object.Key, "/") {
+			return nil
+		}
+		if len(result.Entries) >= limit {
+			return errLimit
+		}
+		var signOptions []oss.Option
+		if options.ForceDownload {
+			signOptions = append(signOptions, oss.ResponseContentDisposition(attachmentDisposition(path.Base(object.Key))))
+		}
+		signedURL, err := signBucketURL(signer, object.Key, oss.HTTPGet, expires, signOptions...)
+		if err != nil {
+			return err
+		}
+		result.Entries = append(result.Entries, PresignFolderEntry{
+			Name:        strings.TrimPrefix(object.Key, prefix),
+			Key:         object.Key,
+			Size:        object.Size,
+			URL:         signedURL,
+			ExpiresAtMs: expiresAt,
+		})
+		result.TotalBytes += object.Size
+		return nil
+	})
+	if errors.Is(err, errLimit) {
+		return PresignFolderResult{}, fmt.Errorf("folder has more than %d objects", limit)
+	}
+	if err != nil {
+		return PresignFolderResult{}, err
+	}
+	if len(result.Entries) == 0 {
+		return PresignFolderResult{}, errors.New("folder has no files to share")
+	}
+	result.Count = len(result.Entries)
+
+	if err := s.writePresignFolderIndex(config, bkt, &result, options); err != nil {
+		return PresignFolderResult{}, err
+	}
+
+	// Links are only recorded once the index they are listed in was written, so a failed
+	// share leaves nothing behind in the registry.
+	links := make([]ShareLink, 0, len(result.Entries))
+	for _, entry := range result.Entries {
+		links = append(links, ShareLink{
+			Bucket:      result.Bucket,
+			Key:         entry.Key,
+			Method:      string(oss.HTTPGet),
+			URL:         entry.URL,
+			ExpiresAtMs: entry.ExpiresAtMs,
+			Note:        strings.TrimSpace(options.Note),
+			Source:      "folder",
+		})
+	}
+	if err := s.recordShareLinks(config, links); err != nil {
+		return PresignFolderResult{}, err
+	}
+	return result, nil
+}
+
+// writePresignFolderIndex saves the HTML index of result to options.LocalPath and uploads it
+// to options.UploadKey, whichever are set.
+func (s *OSSService) writePresignFolderIndex(config OSSConfig, bkt *oss.Bucket, result *PresignFolderResult, options PresignFolderOptions) error {
+	localPath := strings.TrimSpace(options.LocalPath)
+	uploadKey := normalizeObjectKey(options.UploadKey)
+	if localPath == "" && uploadKey == "" {
+		return nil
+	}
+	if strings.HasSuffix(uploadKey, "/") {
+		return errors.New("upload key must be a file name")
+	}
+
+	data, contentType, err := renderPresignFolderIndex(*result)
+	if err != nil {
+		return err
+	}
+	if localPath != "" {
+		if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+			return fmt.Errorf("create local directory failed: %w", err)
+		}
+		if err := os.WriteFile(localPath, data, 0o644); err != nil {
+			return fmt.Errorf("write index failed: %w", err)
+		}
+		result.LocalPath = localPath
+	}
+	if uploadKey != "" {
+		if err := bkt.PutObject(uploadKey, bytes.NewReader(data), oss.ContentType(contentType)); err != nil {
+			return fmt.Errorf("upload index failed: %w", err)
+		}
+		s.invalidateListingCacheForConfig(config, bkt.BucketName, uploadKey)
+		result.UploadedKey = uploadKey
+	}
+	return nil
+}
+
+var presignFolderIndexTemplate = template.Must(template.New("index").Funcs(template.FuncMap{
+	"size": formatBytesHuman,
+	"time": func(ms int64) string { return time.UnixMilli(ms).Format("2006-01-02 15:04 MST") },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Bucket}}/{{.Prefix}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", sans-serif; margin: 2rem; color: #222; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: 0.4rem 0.6rem; border-bottom: 1px solid #ddd; }
+td.size { text-align: right; white-space: nowrap; }
+</style>
+</head>
+<body>
+<h1>{{.Bucket}}/{{.Prefix}}</h1>
+<p>{{.Count}} files, {{size .TotalBytes}}. Links expire {{time .ExpiresAtMs}}.</p>
+<table>
+<tr><th>Name</th><th class="size">Size</th></tr>
+{{range .Entries}}<tr><td><a href="{{.URL}}">{{.Name}}</a></td><td class="size">{{size .Size}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+func formatBytesHuman(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return strconv.FormatInt(size, 10) + " B"
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// renderPresignFolderIndex renders the index file in the result's format.
+func renderPresignFolderIndex(result PresignFolderResult) ([]byte, string, error) {
+	var buf bytes.Buffer
+	switch result.Format {
+	case "json":
+		encoder := json.NewEncoder(&buf)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(result); err != nil {
+			return nil, "", fmt.Errorf("render index failed: %w", err)
+		}
+		return buf.Bytes(), "application/json; charset=utf-8", nil
+	case "html":
+		if err := presignFolderIndexTemplate.Execute(&buf, result); err != nil {
+			return nil, "", fmt.Errorf("render index failed: %w", err)
+		}
+		return buf.Bytes(), "text/html; charset=utf-8", nil
+	default:
+		writer := csv.NewWriter(&buf)
+		_ = writer.Write([]string{"name", "size", "url", "expires"})
+		for _, entry := range result.Entries {
+			_ = writer.Write([]string{
+				entry.Name,
+				strconv.FormatInt(entry.Size, 10),
+				entry.URL,
+				time.UnixMilli(entry.ExpiresAtMs).UTC().Format(time.RFC3339),
+			})
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return nil, "", fmt.Errorf("render index failed: %w", err)
+		}
+		return buf.Bytes(), "text/csv; charset=utf-8", nil
+	}
+}
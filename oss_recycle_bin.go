@@ -0,0 +1,658 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// With the recycle bin enabled for a profile, DeleteObject moves objects into
+// <prefix><timestamp>/ instead of deleting them, either in the bucket they came from or in
+// a separate trash bucket, where the original bucket is the first path segment after the
+// timestamp. The original location is also kept in the object's metadata. Settings are
+// stored per profile in recycle-bin.json in the work dir.
+
+const (
+	recycleBinFileName        = "recycle-bin.json"
+	recycleBinSchemaVersion   = 1
+	recycleBinDefaultPrefix   = ".walioss-trash/"
+	recycleBinTimestamp       = "20060102-150405.000"
+	recycleBinWorkers         = 8
+	recycleBinPurgeCheckEvery = time.Hour
+	recycleBinPurgeEvery      = 24 * time.Hour
+
+	recycleBinMetaOriginalBucket = "Walioss-Original-Bucket"
+	recycleBinMetaOriginalKey    = "Walioss-Original-Key"
+	recycleBinMetaDeletedAt      = "Walioss-Deleted-At"
+)
+
+// RecycleBinSettings configures the recycle bin of one profile. Bucket is the trash bucket;
+// when empty, deleted objects stay in their own bucket under Prefix. With AutoPurge set,
+// items older than RetentionDays are purged about once a day while the app runs.
+type RecycleBinSettings struct {
+	Enabled          bool   `json:"enabled"`
+	Prefix           string `json:"prefix"`
+	Bucket           string `json:"bucket"`
+	RetentionDays    int    `json:"retentionDays"`
+	AutoPurge        bool   `json:"autoPurge"`
+	LastPurgedAtMs   int64  `json:"lastPurgedAtMs,omitempty"`
+	LastPurgeMessage string `json:"lastPurgeMessage,omitempty"`
+}
+
+// TrashItem is one deleted object in the recycle bin.
+type TrashItem struct {
+	TrashBucket    string `json:"trashBucket"`
+	TrashKey       string `json:"trashKey"`
+	OriginalBucket string `json:"originalBucket"`
+	OriginalKey    string `json:"originalKey"`
+	OriginalPath   string `json:"originalPath"`
+	DeletedAtMs    int64  `json:"deletedAtMs"`
+	Size           int64  `json:"size"`
+}
+
+type TrashRestoreResult struct {
+	Restored []string          `json:"restored"`
+	Failed   map[string]string `json:"failed"`
+}
+
+type recycleBinStore struct {
+	SchemaVersion int                           `json:"schemaVersion"`
+	Profiles      map[string]RecycleBinSettings `json:"profiles"`
+}
+
+func (s *OSSService) recycleBinPath() string {
+	return filepath.Join(normalizeWorkDirPath(s.configDir, s.defaultConfigDir), recycleBinFileName)
+}
+
+func (s *OSSService) loadRecycleBinLocked() recycleBinStore {
+	store := recycleBinStore{SchemaVersion: recycleBinSchemaVersion, Profiles: map[string]RecycleBinSettings{}}
+	data, err := os.ReadFile(s.recycleBinPath())
+	if err != nil {
+		return store
+	}
+	if err := json.Unmarshal(data, &store); err != nil || store.Profiles == nil {
+		store.Profiles = map[string]RecycleBinSettings{}
+	}
+	store.SchemaVersion = recycleBinSchemaVersion
+	return store
+}
+
+func (s *OSSService) saveRecycleBinLocked(store recycleBinStore) error {
+	path := s.recycleBinPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+func normalizeRecycleBinSettings(settings RecycleBinSettings) RecycleBinSettings {
+	settings.Prefix = normalizeObjectPrefix(settings.Prefix)
+	if settings.Prefix == "" {
+		settings.Prefix = recycleBinDefaultPrefix
+	}
+	settings.Bucket = normalizeTransferBucket(settings.Bucket)
+	if settings.RetentionDays < 0 {
+		settings.RetentionDays = 0
+	}
+	return settings
+}
+
+// recycleBinSettings returns the settings of a saved profile. Connections that are not
+// saved all share one anonymous name, so they never get a recycle bin.
+func (s *OSSService) recycleBinSettings(profileName string) RecycleBinSettings {
+	if profileName == transferProfileAnonymous {
+		return normalizeRecycleBinSettings(RecycleBinSettings{})
+	}
+	s.recycleBinMu.Lock()
+	defer s.recycleBinMu.Unlock()
+	return normalizeRecycleBinSettings(s.loadRecycleBinLocked().Profiles[profileName])
+}
+
+// GetRecycleBinSettings returns the recycle bin settings of the profile config belongs to.
+func (s *OSSService) GetRecycleBinSettings(config OSSConfig) (RecycleBinSettings, error) {
+	return s.recycleBinSettings(s.resolveTransferProfileName(config)), nil
+}
+
+// SaveRecycleBinSettings stores the recycle bin settings of the profile config belongs to.
+func (s *OSSService) SaveRecycleBinSettings(config OSSConfig, settings RecycleBinSettings) error {
+	settings = normalizeRecycleBinSettings(settings)
+	profileName := s.resolveTransferProfileName(config)
+	if profileName == transferProfileAnonymous {
+		return errors.New("save this connection as a profile to use a recycle bin")
+	}
+	if settings.Enabled && settings.Bucket != "" {
+		if err := checkTrashBucketRegion(config, settings.Bucket, normalizeRegion(config.Region)); err != nil {
+			return err
+		}
+	}
+
+	s.recycleBinMu.Lock()
+	defer s.recycleBinMu.Unlock()
+	store := s.loadRecycleBinLocked()
+	previous := store.Profiles[profileName]
+	settings.LastPurgedAtMs = previous.LastPurgedAtMs
+	settings.LastPurgeMessage = previous.LastPurgeMessage
+	store.Profiles[profileName] = settings
+	if err := s.saveRecycleBinLocked(store); err != nil {
+		return fmt.Errorf("failed to save recycle bin settings: %w", err)
+	}
+	return nil
+}
+
+// bucketRegion returns the region bucketName is in, e.g. "cn-hangzhou".
+func bucketRegion(config OSSConfig, bucketName string) (string, error) {
+	client, err := sdkClientFromConfig(config)
+	if err != nil {
+		return "", err
+	}
+	info, err := client.GetBucketInfo(normalizeTransferBucket(bucketName))
+	if err != nil {
+		return "", fmt.Errorf("failed to read bucket info of %s: %w", bucketName, err)
+	}
+	return normalizeRegion(info.BucketInfo.Location), nil
+}
+
+// checkTrashBucketRegion refuses a trash bucket outside region: objects are moved into the
+// bin with server-side copies, which OSS only does within a region.
+func checkTrashBucketRegion(config OSSConfig, trashBucket string, region string) error {
+	trashRegion, err := bucketRegion(config, trashBucket)
+	if err != nil {
+		return fmt.Errorf("trash bucket: %w", err)
+	}
+	if region != "" && trashRegion != "" && trashRegion != region {
+		return fmt.Errorf("trash bucket %s is in %s, but deleted objects are in %s; pick a bucket in the same region", trashBucket, trashRegion, region)
+	}
+	return nil
+}
+
+// trashBucketName returns the bucket deleted objects of bucketName go to.
+func (settings RecycleBinSettings) trashBucketName(bucketName string) string {
+	if settings.Bucket != "" {
+		return settings.Bucket
+	}
+	return bucketName
+}
+
+// trashKey places key of bucketName into the batch folder of one delete.
+func (settings RecycleBinSettings) trashKey(batch string, bucketName string, key string) string {
+	if settings.Bucket != "" {
+		return settings.Prefix + batch + "/" + bucketName + "/" + key
+	}
+	return settings.Prefix + batch + "/" + key
+}
+
+// parseTrashKey recovers the deletion time and original location from a trash key.
+func (settings RecycleBinSettings) parseTrashKey(trashBucket string, trashKey string) (TrashItem, bool) {
+	rest, ok := strings.CutPrefix(trashKey, settings.Prefix)
+	if !ok {
+		return TrashItem{}, false
+	}
+	batch, original, ok := strings.Cut(rest, "/")
+	if !ok || original == "" {
+		return TrashItem{}, false
+	}
+	deletedAt, err := time.ParseInLocation(recycleBinTimestamp, batch, time.UTC)
+	if err != nil {
+		return TrashItem{}, false
+	}
+	item := TrashItem{TrashBucket: trashBucket, TrashKey: trashKey, OriginalBucket: trashBucket, OriginalKey: original, DeletedAtMs: deletedAt.UnixMilli()}
+	if settings.Bucket != "" {
+		item.OriginalBucket, item.OriginalKey, ok = strings.Cut(original, "/")
+		if !ok || item.OriginalKey == "" {
+			return TrashItem{}, false
+		}
+	}
+	item.OriginalPath = buildOssPath(item.OriginalBucket, item.OriginalKey)
+	return item, true
+}
+
+// copyObjectWithMetadata copies src to destKey in bkt with header's metadata plus meta,
+// which CopyObject only accepts when the metadata is replaced as a whole.
+func copyObjectWithMetadata(bkt *oss.Bucket, srcBkt *oss.Bucket, src objectCopySource, header http.Header, destKey string, meta map[string]string) error {
+	options := objectHeaderOptions(header)
+	if storageClass := header.Get(oss.HTTPHeaderOssStorageClass); storageClass != "" {
+		options = append(options, oss.ObjectStorageClass(oss.StorageClassType(storageClass)))
+	}
+	for name, value := range meta {
+		options = append(options, oss.Meta(name, value))
+	}
+	if src.Size <= maxSingleCopyObjectSize {
+		options = append(options, oss.MetadataDirective(oss.MetaReplace))
+		var err error
+		if src.Bucket == bkt.BucketName {
+			_, err = bkt.CopyObject(src.Key, destKey, options...)
+		} else {
+			_, err = bkt.CopyObjectFrom(src.Bucket, src.Key, destKey, options...)
+		}
+		if err != nil {
+			return fmt.Errorf("copy failed: %w", err)
+		}
+		return nil
+	}
+	tagging, hasTags, err := objectTaggingOption(srcBkt, src.Key)
+	if err != nil {
+		return fmt.Errorf("failed to read object tags: %w", err)
+	}
+	if hasTags {
+		options = append(options, tagging)
+	}
+	if err := multipartCopyObject(bkt, src, destKey, options); err != nil {
+		return fmt.Errorf("multipart copy failed: %w", err)
+	}
+	return nil
+}
+
+// stripRecycleBinMeta drops the recycle bin's own metadata from a trashed object's header.
+func stripRecycleBinMeta(header http.Header) http.Header {
+	out := header.Clone()
+	for _, name := range []string{recycleBinMetaOriginalBucket, recycleBinMetaOriginalKey, recycleBinMetaDeletedAt} {
+		out.Del(oss.HTTPHeaderOssMetaPrefix + name)
+	}
+	return out
+}
+
+// runRecycleBinWorkers runs fn for every key with recycleBinWorkers goroutines and returns
+// the errors by key.
+func runRecycleBinWorkers(keys []string, fn func(key string) error) map[string]string {
+	failed := map[string]string{}
+	jobs := make(chan string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < recycleBinWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range jobs {
+				if err := fn(key); err != nil {
+					mu.Lock()
+					failed[key] = err.Error()
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, key := range keys {
+		jobs <- key
+	}
+	close(jobs)
+	wg.Wait()
+	return failed
+}
+
+// checkTrashableObject refuses archived objects that are not restored, which cannot be
+// copied into the recycle bin.
+func checkTrashableObject(key string, storageClass string, restoreInfo string) error {
+	if state, _ := objectRestoreState(storageClass, restoreInfo); state != "" && state != RestoreStateRestored {
+		return fmt.Errorf("%s is archived (%s) and must be restored before it can be moved to the recycle bin; disable the recycle bin to delete it permanently", key, storageClass)
+	}
+	return nil
+}
+
+// moveToTrash moves object, or every object under it when it is a folder, into the
+// recycle bin as one batch. It returns the trash bucket and the trash keys of the objects
+// it moved, including those moved before an error.
+func (s *OSSService) moveToTrash(config OSSConfig, settings RecycleBinSettings, bucketName string, object string) (string, []string, error) {
+	bkt, err := s.openBucket(config, bucketName)
+	if err != nil {
+		return "", nil, err
+	}
+	trashBkt := bkt
+	if settings.Bucket != "" && settings.Bucket != bkt.BucketName {
+		trashBkt, err = s.openBucket(config, settings.Bucket)
+		if err != nil {
+			return "", nil, fmt.Errorf("trash bucket: %w", err)
+		}
+		region, err := bucketRegion(config, bkt.BucketName)
+		if err != nil {
+			return "", nil, err
+		}
+		if err := checkTrashBucketRegion(config, trashBkt.BucketName, region); err != nil {
+			return "", nil, err
+		}
+	}
+	key := normalizeObjectKey(object)
+	if trashBkt.BucketName == bkt.BucketName && strings.HasPrefix(key, settings.Prefix) {
+		return "", nil, errors.New("items in the recycle bin are removed with PurgeTrash")
+	}
+
+	keys := []string{key}
+	if strings.HasSuffix(key, "/") {
+		keys = keys[:0]
+		archived := make([]string, 0)
+		err := walkObjectsUnderPrefix(bkt, key, func(object oss.ObjectProperties) error {
+			if err := checkTrashableObject(object.Key, object.StorageClass, object.RestoreInfo); err != nil {
+				archived = append(archived, object.Key)
+			}
+			keys = append(keys, object.Key)
+			return nil
+		})
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to list folder objects: %w", err)
+		}
+		// Nothing is moved when part of the folder cannot be, so it is not left half deleted.
+		if len(archived) > 0 {
+			return "", nil, fmt.Errorf("%d archived objects must be restored before they can be moved to the recycle bin (first: %s); disable the recycle bin to delete them permanently", len(archived), archived[0])
+		}
+	}
+
+	now := time.Now().UTC()
+	batch := now.Format(recycleBinTimestamp)
+	var mu sync.Mutex
+	trashKeys := make([]string, 0, len(keys))
+	failed := runRecycleBinWorkers(keys, func(key string) error {
+		header, err := bkt.GetObjectDetailedMeta(key)
+		if err != nil {
+			return fmt.Errorf("failed to read object metadata: %w", err)
+		}
+		if err := checkTrashableObject(key, header.Get(oss.HTTPHeaderOssStorageClass), header.Get(ossRestoreHeader)); err != nil {
+			return err
+		}
+		size, _ := strconv.ParseInt(header.Get(oss.HTTPHeaderContentLength), 10, 64)
+		src := objectCopySource{Bucket: bkt.BucketName, Key: key, Size: size}
+		// Metadata values must be ASCII, so the key is stored escaped.
+		meta := map[string]string{
+			recycleBinMetaOriginalBucket: bkt.BucketName,
+			recycleBinMetaOriginalKey:    url.PathEscape(key),
+			recycleBinMetaDeletedAt:      now.Format(time.RFC3339),
+		}
+		trashKey := settings.trashKey(batch, bkt.BucketName, key)
+		if err := copyObjectWithMetadata(trashBkt, bkt, src, header, trashKey, meta); err != nil {
+			return err
+		}
+		if err := bkt.DeleteObject(key); err != nil {
+			_ = trashBkt.DeleteObject(trashKey)
+			return err
+		}
+		mu.Lock()
+		trashKeys = append(trashKeys, trashKey)
+		mu.Unlock()
+		return nil
+	})
+	sort.Strings(trashKeys)
+	s.invalidateListingCacheForConfig(config, bkt.BucketName, key)
+	s.invalidateListingCacheForConfig(config, trashBkt.BucketName, settings.Prefix+batch+"/")
+	if len(failed) == 0 {
+		return trashBkt.BucketName, trashKeys, nil
+	}
+	failedKeys := make([]string, 0, len(failed))
+	for failedKey := range failed {
+		failedKeys = append(failedKeys, failedKey)
+	}
+	sort.Strings(failedKeys)
+	return trashBkt.BucketName, trashKeys, fmt.Errorf("move to recycle bin failed for %d of %d objects (first error: %s: %s)", len(failed), len(keys), failedKeys[0], failed[failedKeys[0]])
+}
+
+// ListTrash returns the items in the recycle bin that bucketName's deletes go to, newest
+// first.
+func (s *OSSService) ListTrash(config OSSConfig, bucketName string) ([]TrashItem, error) {
+	settings := s.recycleBinSettings(s.resolveTransferProfileName(config))
+	bkt, err := s.openBucket(config, settings.trashBucketName(bucketName))
+	if err != nil {
+		return nil, err
+	}
+	items := make([]TrashItem, 0)
+	err = walkObjectsUnderPrefix(bkt, settings.Prefix, func(object oss.ObjectProperties) error {
+		item, ok := settings.parseTrashKey(bkt.BucketName, object.Key)
+		if !ok {
+			return nil
+		}
+		if settings.Bucket != "" && normalizeTransferBucket(bucketName) != "" && item.OriginalBucket != normalizeTransferBucket(bucketName) {
+			return nil
+		}
+		item.TrashKey = objectKeyForClient(item.TrashKey)
+		item.Size = object.Size
+		items = append(items, item)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recycle bin: %w", err)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].DeletedAtMs != items[j].DeletedAtMs {
+			return items[i].DeletedAtMs > items[j].DeletedAtMs
+		}
+		return items[i].TrashKey < items[j].TrashKey
+	})
+	return items, nil
+}
+
+// RestoreTrashItems moves items of the recycle bin in trashBucket back to where they were
+// deleted from. An item whose original location is taken again is left in the bin unless
+// overwrite is set.
+func (s *OSSService) RestoreTrashItems(config OSSConfig, trashBucket string, trashKeys []string, overwrite bool) (TrashRestoreResult, error) {
+	settings := s.recycleBinSettings(s.resolveTransferProfileName(config))
+	trashBkt, err := s.openBucket(config, trashBucket)
+	if err != nil {
+		return TrashRestoreResult{}, err
+	}
+	keys := make([]string, 0, len(trashKeys))
+	for _, key := range trashKeys {
+		if key = normalizeObjectKey(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return TrashRestoreResult{}, errors.New("no items selected")
+	}
+
+	var mu sync.Mutex
+	buckets := map[string]*oss.Bucket{trashBkt.BucketName: trashBkt}
+	openOriginal := func(name string) (*oss.Bucket, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if bkt, ok := buckets[name]; ok {
+			return bkt, nil
+		}
+		bkt, err := s.openBucket(config, name)
+		if err != nil {
+			return nil, err
+		}
+		buckets[name] = bkt
+		return bkt, nil
+	}
+
+	restored := make([]string, 0, len(keys))
+	failed := runRecycleBinWorkers(keys, func(key string) error {
+		header, err := trashBkt.GetObjectDetailedMeta(key)
+		if err != nil {
+			return fmt.Errorf("failed to read object metadata: %w", err)
+		}
+		item, ok := settings.parseTrashKey(trashBkt.BucketName, key)
+		if bucket := header.Get(oss.HTTPHeaderOssMetaPrefix + recycleBinMetaOriginalBucket); bucket != "" {
+			if original, err := url.PathUnescape(header.Get(oss.HTTPHeaderOssMetaPrefix + recycleBinMetaOriginalKey)); err == nil && original != "" {
+				item.OriginalBucket, item.OriginalKey, ok = bucket, original, true
+			}
+		}
+		if !ok {
+			return errors.New("original location is unknown")
+		}
+		originalBkt, err := openOriginal(item.OriginalBucket)
+		if err != nil {
+			return err
+		}
+		if !overwrite {
+			exists, err := originalBkt.IsObjectExist(item.OriginalKey)
+			if err != nil {
+				return fmt.Errorf("failed to check %s: %w", item.OriginalKey, err)
+			}
+			if exists {
+				return fmt.Errorf("%s already exists", buildOssPath(item.OriginalBucket, item.OriginalKey))
+			}
+		}
+		size, _ := strconv.ParseInt(header.Get(oss.HTTPHeaderContentLength), 10, 64)
+		src := objectCopySource{Bucket: trashBkt.BucketName, Key: key, Size: size}
+		if err := copyObjectWithMetadata(originalBkt, trashBkt, src, stripRecycleBinMeta(header), item.OriginalKey, nil); err != nil {
+			return err
+		}
+		s.invalidateListingCacheForConfig(config, item.OriginalBucket, item.OriginalKey)
+		if err := trashBkt.DeleteObject(key); err != nil {
+			return fmt.Errorf("restored, but failed to remove it from the recycle bin: %w", err)
+		}
+		mu.Lock()
+		restored = append(restored, key)
+		mu.Unlock()
+		return nil
+	})
+	s.invalidateListingCacheForConfig(config, trashBkt.BucketName, keys...)
+	sort.Strings(restored)
+	return TrashRestoreResult{Restored: restored, Failed: failed}, nil
+}
+
+// purgeTrashBucket permanently deletes the items in bkt's recycle bin deleted before cutoff,
+// or all of them when cutoff is zero.
+func (s *OSSService) purgeTrashBucket(config OSSConfig, settings RecycleBinSettings, bkt *oss.Bucket, cutoff time.Time) (int, error) {
+	keys := make([]string, 0)
+	err := walkObjectsUnderPrefix(bkt, settings.Prefix, func(object oss.ObjectProperties) error {
+		item, ok := settings.parseTrashKey(bkt.BucketName, object.Key)
+		if ok && (cutoff.IsZero() || item.DeletedAtMs < cutoff.UnixMilli()) {
+			keys = append(keys, object.Key)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list recycle bin: %w", err)
+	}
+	purged := 0
+	for start := 0; start < len(keys); start += 1000 {
+		end := start + 1000
+		if end > len(keys) {
+			end = len(keys)
+		}
+		result, err := bkt.DeleteObjects(keys[start:end])
+		if err != nil {
+			return purged, fmt.Errorf("purge failed: %w", err)
+		}
+		purged += len(result.DeletedObjects)
+	}
+	if len(keys) > 0 {
+		s.invalidateListingCacheForConfig(config, bkt.BucketName, settings.Prefix)
+	}
+	return purged, nil
+}
+
+// PurgeTrash permanently deletes items of the recycle bin of bucketName deleted more than
+// olderThanDays days ago; 0 purges everything. It returns the number of objects deleted.
+func (s *OSSService) PurgeTrash(config OSSConfig, bucketName string, olderThanDays int) (int, error) {
+	settings := s.recycleBinSettings(s.resolveTransferProfileName(config))
+	bkt, err := s.openBucket(config, settings.trashBucketName(bucketName))
+	if err != nil {
+		return 0, err
+	}
+	var cutoff time.Time
+	if olderThanDays > 0 {
+		cutoff = time.Now().AddDate(0, 0, -olderThanDays)
+	}
+	return s.purgeTrashBucket(config, settings, bkt, cutoff)
+}
+
+// purgeProfileTrash purges the items past retention for one profile: its trash bucket, or
+// the recycle bin of every bucket when deleted objects stay in their own bucket. A bucket
+// that fails is reported and the others are still purged.
+func (s *OSSService) purgeProfileTrash(config OSSConfig, settings RecycleBinSettings) (int, error) {
+	// Buckets of other regions are opened through the endpoint of their own region.
+	bucketConfigs := map[string]OSSConfig{settings.Bucket: config}
+	if settings.Bucket == "" {
+		client, err := sdkClientFromConfig(config)
+		if err != nil {
+			return 0, err
+		}
+		delete(bucketConfigs, "")
+		marker := ""
+		for {
+			result, err := client.ListBuckets(oss.Marker(marker), oss.MaxKeys(1000))
+			if err != nil {
+				return 0, fmt.Errorf("failed to list buckets: %w", err)
+			}
+			for _, bucket := range result.Buckets {
+				bucketConfig := config
+				if region := normalizeRegion(bucket.Location); region != "" && region != normalizeRegion(config.Region) {
+					bucketConfig.Region = region
+					bucketConfig.Endpoint = suggestServiceEndpoint(region)
+				}
+				bucketConfigs[bucket.Name] = bucketConfig
+			}
+			if !result.IsTruncated || result.NextMarker == "" {
+				break
+			}
+			marker = result.NextMarker
+		}
+	}
+
+	bucketNames := make([]string, 0, len(bucketConfigs))
+	for bucketName := range bucketConfigs {
+		bucketNames = append(bucketNames, bucketName)
+	}
+	sort.Strings(bucketNames)
+	cutoff := time.Now().AddDate(0, 0, -settings.RetentionDays)
+	total := 0
+	failures := make([]string, 0)
+	for _, bucketName := range bucketNames {
+		bkt, err := s.openBucket(bucketConfigs[bucketName], bucketName)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", bucketName, err))
+			continue
+		}
+		purged, err := s.purgeTrashBucket(bucketConfigs[bucketName], settings, bkt, cutoff)
+		total += purged
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", bucketName, err))
+		}
+	}
+	if len(failures) > 0 {
+		return total, fmt.Errorf("purged %d objects; %d of %d buckets failed: %s", total, len(failures), len(bucketNames), strings.Join(failures, "; "))
+	}
+	return total, nil
+}
+
+// runScheduledTrashPurge purges the recycle bins of saved profiles with AutoPurge about
+// once a day until ctx ends.
+func (s *OSSService) runScheduledTrashPurge(ctx context.Context) {
+	ticker := time.NewTicker(recycleBinPurgeCheckEvery)
+	defer ticker.Stop()
+	for {
+		profiles, err := s.LoadProfiles()
+		if err == nil {
+			for _, profile := range profiles {
+				profileName := normalizeTransferProfileName(profile.Name)
+				settings := s.recycleBinSettings(profileName)
+				if !settings.AutoPurge || settings.RetentionDays <= 0 || time.Since(time.UnixMilli(settings.LastPurgedAtMs)) < recycleBinPurgeEvery {
+					continue
+				}
+				purged, err := s.purgeProfileTrash(profile.Config, settings)
+				message := fmt.Sprintf("purged %d objects", purged)
+				if err != nil {
+					message = err.Error()
+				}
+				s.recycleBinMu.Lock()
+				store := s.loadRecycleBinLocked()
+				current := store.Profiles[profileName]
+				current.LastPurgedAtMs = time.Now().UnixMilli()
+				current.LastPurgeMessage = message
+				store.Profiles[profileName] = current
+				_ = s.saveRecycleBinLocked(store)
+				s.recycleBinMu.Unlock()
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,394 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+const (
+	RestoreStateArchived = "archived"
+	RestoreStateOngoing  = "ongoing"
+	RestoreStateRestored = "restored"
+
+	ossRestoreHeader = "X-Oss-Restore"
+
+	restoreWatchInitialInterval = time.Minute
+	restoreWatchMaxInterval     = 15 * time.Minute
+	restoreWatchMaxAge          = 7 * 24 * time.Hour
+	restoreWatchWorkers         = 4
+)
+
+var (
+	reRestoreOngoing = regexp.MustCompile(`ongoing-request\s*=\s*"(true|false)"`)
+	reRestoreExpiry  = regexp.MustCompile(`expiry-date\s*=\s*"([^"]+)"`)
+)
+
+// RestoreStatusEvent is emitted as "restore:update" when a watched restore finishes. Key is
+// in the form listings use.
+type RestoreStatusEvent struct {
+	ProfileName        string `json:"profileName,omitempty"`
+	Bucket             string `json:"bucket"`
+	Key                string `json:"key"`
+	State              string `json:"state"`
+	Expiry             string `json:"expiry,omitempty"`
+	DownloadTransferID string `json:"downloadTransferId,omitempty"`
+	Message            string `json:"message,omitempty"`
+}
+
+// restoreWatch is a restore being polled until it finishes. Watches only live in memory: when
+// the app quits they are lost, no "restore:update" is sent for them and a download queued
+// with EnqueueDownloadAfterRestore does not start.
+type restoreWatch struct {
+	Config      OSSConfig
+	ProfileName string
+	Bucket      string
+	Key         string
+	Size        int64
+	LocalPath   string
+	CreatedAt   time.Time
+	NextPollAt  time.Time
+	Interval    time.Duration
+}
+
+func isArchiveStorageClass(storageClass string) bool {
+	switch oss.StorageClassType(storageClass) {
+	case oss.StorageArchive, oss.StorageColdArchive, oss.StorageDeepColdArchive:
+		return true
+	}
+	return false
+}
+
+// parseRestoreInfo parses the x-oss-restore header (or the RestoreInfo listing field), e.g.
+// ongoing-request="false", expiry-date="Sun, 16 Apr 2017 08:12:33 GMT".
+func parseRestoreInfo(value string) (ongoing bool, expiry time.Time, ok bool) {
+	m := reRestoreOngoing.FindStringSubmatch(value)
+	if len(m) != 2 {
+		return false, time.Time{}, false
+	}
+	ongoing = m[1] == "true"
+	if e := reRestoreExpiry.FindStringSubmatch(value); len(e) == 2 {
+		expiry, _ = http.ParseTime(e[1])
+	}
+	return ongoing, expiry, true
+}
+
+// objectRestoreState maps a storage class and restore info to the state shown in the UI.
+// Objects that are not archived have no restore state.
+func objectRestoreState(storageClass string, restoreInfo string) (string, string) {
+	if !isArchiveStorageClass(storageClass) {
+		return "", ""
+	}
+	ongoing, expiry, ok := parseRestoreInfo(restoreInfo)
+	if !ok {
+		return RestoreStateArchived, ""
+	}
+	if ongoing {
+		return RestoreStateOngoing, ""
+	}
+	if !expiry.IsZero() && expiry.Before(time.Now()) {
+		return RestoreStateArchived, ""
+	}
+	return RestoreStateRestored, formatObjectLastModified(expiry)
+}
+
+func normalizeRestoreTier(tier string) (string, error) {
+	tier = strings.TrimSpace(tier)
+	if tier == "" {
+		return "", nil
+	}
+	for _, candidate := range []oss.RestoreMode{oss.RestoreExpedited, oss.RestoreStandard, oss.RestoreBulk} {
+		if strings.EqualFold(string(candidate), tier) {
+			return string(candidate), nil
+		}
+	}
+	return "", fmt.Errorf("unsupported restore tier: %s", tier)
+}
+
+func isRestoreAlreadyInProgress(err error) bool {
+	var serviceErr oss.ServiceError
+	if errors.As(err, &serviceErr) {
+		return serviceErr.StatusCode == http.StatusConflict && serviceErr.Code == "RestoreAlreadyInProgress"
+	}
+	return false
+}
+
+func restoreObject(bkt *oss.Bucket, target objectTarget, days int, tier string) error {
+	restoreConfig := oss.RestoreConfiguration{Days: int32(days)}
+	// Archive objects only accept Days; the retrieval tier applies to cold archive classes.
+	if target.StorageClass != string(oss.StorageArchive) {
+		restoreConfig.Tier = tier
+	}
+	body, err := xml.Marshal(restoreConfig)
+	if err != nil {
+		return err
+	}
+	err = bkt.RestoreObjectXML(target.Key, string(body))
+	if err != nil && !isRestoreAlreadyInProgress(err) {
+		return err
+	}
+	return nil
+}
+
+// RestoreObjects starts restoring the selected archived objects. Keys ending with "/" are
+// restored recursively. Objects that are not archived are skipped. The returned transfer ID
+// tracks the restore requests; completion is reported later through "restore:update" events.
+func (s *OSSService) RestoreObjects(config OSSConfig, bucketName string, keys []string, days int, tier string) (string, error) {
+	if days <= 0 {
+		days = 1
+	}
+	if days > 365 {
+		days = 365
+	}
+	tier, err := normalizeRestoreTier(tier)
+	if err != nil {
+		return "", err
+	}
+
+	bkt, err := s.openBucket(config, bucketName)
+	if err != nil {
+		return "", err
+	}
+	bucketName = bkt.BucketName
+
+	targets, err := collectObjectTargets(bkt, keys)
+	if err != nil {
+		return "", err
+	}
+	archived := make([]objectTarget, 0, len(targets))
+	for _, target := range targets {
+		if isArchiveStorageClass(target.StorageClass) {
+			archived = append(archived, target)
+		}
+	}
+	if len(archived) == 0 {
+		return "", errors.New("no archived objects in selection")
+	}
+
+	selectionName, selectionKey := describeObjectSelection(keys)
+	update := TransferUpdate{
+		Type:   TransferTypeRestore,
+		Name:   selectionName,
+		Bucket: bucketName,
+		Key:    selectionKey,
+	}
+	profileName := normalizeTransferProfileName(s.resolveTransferProfileName(config))
+	update.ProfileName = profileName
+	id := s.enqueueObjectJob(config, update, bkt, archived, func(bkt *oss.Bucket, target objectTarget) error {
+		if err := restoreObject(bkt, target, days, tier); err != nil {
+			return err
+		}
+		s.addRestoreWatch(restoreWatch{
+			Config:      config,
+			ProfileName: profileName,
+			Bucket:      bucketName,
+			Key:         target.Key,
+			Size:        target.Size,
+		})
+		return nil
+	}, nil)
+	return id, nil
+}
+
+// GetObjectInfo returns the details of a single object, including its restore state.
+func (s *OSSService) GetObjectInfo(config OSSConfig, bucketName string, key string) (ObjectInfo, error) {
+	key = normalizeObjectKey(key)
+	if key == "" {
+		return ObjectInfo{}, errors.New("object key is empty")
+	}
+	bkt, err := s.openBucket(config, bucketName)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	header, err := bkt.GetObjectDetailedMeta(key)
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("failed to read object: %w", err)
+	}
+	info := objectInfoFromHeader(bkt.BucketName, key, header)
+	if info.IsSymlink {
+		if target, err := readSymlinkTarget(bkt, key); err == nil {
+			info.SymlinkTarget = target
+		}
+	}
+	return info, nil
+}
+
+func objectInfoFromHeader(bucketName string, key string, header http.Header) ObjectInfo {
+	target := objectTargetFromHeader(key, header)
+	restoreState, restoreExpiry := objectRestoreState(target.StorageClass, header.Get(ossRestoreHeader))
+	return ObjectInfo{
+		Name:          path.Base(key),
+		Path:          buildOssPath(bucketName, key),
+		RawKey:        rawObjectKey(key),
+		Size:          target.Size,
+		Type:          "File",
+		LastModified:  formatObjectLastModified(target.LastModified),
+		StorageClass:  target.StorageClass,
+		ETag:          target.ETag,
+		RestoreState:  restoreState,
+		RestoreExpiry: restoreExpiry,
+		IsSymlink:     header.Get(ossObjectTypeHeader) == ossObjectTypeSymlink,
+	}
+}
+
+// EnqueueDownloadAfterRestore downloads an archived object as soon as it is restored. If the
+// object is readable already the download starts immediately and its transfer ID is returned;
+// otherwise the object is watched and the download starts when the restore finishes, provided
+// the app is still running then.
+func (s *OSSService) EnqueueDownloadAfterRestore(config OSSConfig, bucketName string, key string, localPath string) (string, error) {
+	info, err := s.GetObjectInfo(config, bucketName, key)
+	if err != nil {
+		return "", err
+	}
+	localPath = strings.TrimSpace(localPath)
+	if localPath == "" {
+		return "", errors.New("local path is empty")
+	}
+
+	switch info.RestoreState {
+	case "", RestoreStateRestored:
+		return s.EnqueueDownload(config, bucketName, key, localPath, info.Size)
+	case RestoreStateArchived:
+		return "", errors.New("object is archived, restore it first")
+	}
+
+	s.addRestoreWatch(restoreWatch{
+		Config:      config,
+		ProfileName: normalizeTransferProfileName(s.resolveTransferProfileName(config)),
+		Bucket:      normalizeTransferBucket(bucketName),
+		Key:         normalizeObjectKey(key),
+		Size:        info.Size,
+		LocalPath:   localPath,
+	})
+	return "", nil
+}
+
+func restoreWatchID(watch restoreWatch) string {
+	return watch.ProfileName + "::" + watch.Bucket + "/" + watch.Key
+}
+
+func (s *OSSService) addRestoreWatch(watch restoreWatch) {
+	now := time.Now()
+	watch.CreatedAt = now
+	watch.Interval = restoreWatchInitialInterval
+	watch.NextPollAt = now.Add(watch.Interval)
+
+	s.restoreWatchMu.Lock()
+	defer s.restoreWatchMu.Unlock()
+	if s.restoreWatches == nil {
+		s.restoreWatches = make(map[string]*restoreWatch)
+	}
+	id := restoreWatchID(watch)
+	if existing, ok := s.restoreWatches[id]; ok && watch.LocalPath == "" {
+		watch.LocalPath = existing.LocalPath
+	}
+	s.restoreWatches[id] = &watch
+
+	if !s.restoreWatchRunning {
+		s.restoreWatchRunning = true
+		go s.runRestoreWatcher()
+	}
+}
+
+// runRestoreWatcher polls watched objects with HEAD requests, backing off per object, until
+// every restore has finished or the watch has expired.
+func (s *OSSService) runRestoreWatcher() {
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		now := time.Now()
+		s.restoreWatchMu.Lock()
+		if len(s.restoreWatches) == 0 {
+			s.restoreWatchRunning = false
+			s.restoreWatchMu.Unlock()
+			return
+		}
+		due := make([]restoreWatch, 0, len(s.restoreWatches))
+		for id, watch := range s.restoreWatches {
+			if now.Sub(watch.CreatedAt) > restoreWatchMaxAge {
+				delete(s.restoreWatches, id)
+				continue
+			}
+			if now.Before(watch.NextPollAt) {
+				continue
+			}
+			watch.Interval *= 2
+			if watch.Interval > restoreWatchMaxInterval {
+				watch.Interval = restoreWatchMaxInterval
+			}
+			watch.NextPollAt = now.Add(watch.Interval)
+			due = append(due, *watch)
+		}
+		s.restoreWatchMu.Unlock()
+
+		jobs := make(chan restoreWatch)
+		var wg sync.WaitGroup
+		for i := 0; i < restoreWatchWorkers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for watch := range jobs {
+					s.pollRestoreWatch(watch)
+				}
+			}()
+		}
+		for _, watch := range due {
+			jobs <- watch
+		}
+		close(jobs)
+		wg.Wait()
+	}
+}
+
+func (s *OSSService) pollRestoreWatch(watch restoreWatch) {
+	event := RestoreStatusEvent{
+		ProfileName: watch.ProfileName,
+		Bucket:      watch.Bucket,
+		Key:         objectKeyForClient(watch.Key),
+	}
+
+	info, err := s.GetObjectInfo(watch.Config, watch.Bucket, watch.Key)
+	if err != nil {
+		var serviceErr oss.ServiceError
+		if !errors.As(err, &serviceErr) || serviceErr.StatusCode != http.StatusNotFound {
+			return
+		}
+		event.State = RestoreStateArchived
+		event.Message = "object no longer exists"
+		s.removeRestoreWatch(watch)
+		s.emitEvent("restore:update", event)
+		return
+	}
+	if info.RestoreState == RestoreStateOngoing {
+		return
+	}
+
+	s.removeRestoreWatch(watch)
+	event.State = info.RestoreState
+	event.Expiry = info.RestoreExpiry
+	if info.RestoreState == RestoreStateRestored && watch.LocalPath != "" {
+		transferID, err := s.EnqueueDownload(watch.Config, watch.Bucket, watch.Key, watch.LocalPath, info.Size)
+		if err != nil {
+			event.Message = fmt.Sprintf("download failed: %v", err)
+		} else {
+			event.DownloadTransferID = transferID
+		}
+	}
+	s.emitEvent("restore:update", event)
+}
+
+func (s *OSSService) removeRestoreWatch(watch restoreWatch) {
+	s.restoreWatchMu.Lock()
+	delete(s.restoreWatches, restoreWatchID(watch))
+	s.restoreWatchMu.Unlock()
+}
@@ -0,0 +1,359 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+const (
+	searchListWorkers   = 8
+	searchPageSize      = 100
+	searchFlushInterval = 500 * time.Millisecond
+	defaultSearchLimit  = 10000
+)
+
+// ObjectSearchQuery filters the objects returned by SearchObjects. Empty fields do not filter.
+type ObjectSearchQuery struct {
+	NamePattern    string   `json:"namePattern"` // Glob such as "*.mov", or a regular expression when NameIsRegex is set
+	NameIsRegex    bool     `json:"nameIsRegex"`
+	CaseSensitive  bool     `json:"caseSensitive"`
+	MinSize        int64    `json:"minSize"`
+	MaxSize        int64    `json:"maxSize"`
+	ModifiedAfter  string   `json:"modifiedAfter"` // "2006-01-02" or RFC 3339
+	ModifiedBefore string   `json:"modifiedBefore"`
+	StorageClasses []string `json:"storageClasses"`
+	Extensions     []string `json:"extensions"` // Without or with the leading dot, e.g. "mov"
+	MaxResults     int      `json:"maxResults"`
+
+	// ProblemKeysOnly limits results to keys that need RawKey: control characters, edge
+	// whitespace in a path segment or invalid UTF-8.
+	ProblemKeysOnly bool `json:"problemKeysOnly"`
+}
+
+// ObjectSearchPage is emitted as "search:results" while a search runs. The last page of a
+// search has Done set; Error is set when the search stopped because of a failure.
+type ObjectSearchPage struct {
+	SearchID     string       `json:"searchId"`
+	Items        []ObjectInfo `json:"items"`
+	ScannedCount int64        `json:"scannedCount"`
+	MatchedCount int64        `json:"matchedCount"`
+	Done         bool         `json:"done"`
+	Cancelled    bool         `json:"cancelled,omitempty"`
+	Truncated    bool         `json:"truncated,omitempty"`
+	Error        string       `json:"error,omitempty"`
+}
+
+type objectMatcher struct {
+	name           *regexp.Regexp
+	nameHasSlash   bool
+	minSize        int64
+	maxSize        int64
+	after          time.Time
+	before         time.Time
+	storageClasses map[string]struct{}
+	extensions     map[string]struct{}
+	problemKeys    bool
+}
+
+// globToRegexp converts a shell glob ("*", "?", "[...]") into an anchored regular expression.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+func parseSearchTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date: %s", value)
+}
+
+func newObjectMatcher(query ObjectSearchQuery) (*objectMatcher, error) {
+	m := &objectMatcher{minSize: query.MinSize, maxSize: query.MaxSize, problemKeys: query.ProblemKeysOnly}
+
+	if pattern := strings.TrimSpace(query.NamePattern); pattern != "" {
+		expr := pattern
+		if !query.NameIsRegex {
+			expr = globToRegexp(pattern)
+		}
+		if !query.CaseSensitive {
+			expr = "(?i)" + expr
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid name pattern: %w", err)
+		}
+		m.name = re
+		m.nameHasSlash = strings.Contains(pattern, "/")
+	}
+
+	var err error
+	if m.after, err = parseSearchTime(query.ModifiedAfter); err != nil {
+		return nil, err
+	}
+	if m.before, err = parseSearchTime(query.ModifiedBefore); err != nil {
+		return nil, err
+	}
+
+	if len(query.StorageClasses) > 0 {
+		m.storageClasses = make(map[string]struct{}, len(query.StorageClasses))
+		for _, storageClass := range query.StorageClasses {
+			if storageClass = strings.TrimSpace(storageClass); storageClass != "" {
+				m.storageClasses[strings.ToLower(storageClass)] = struct{}{}
+			}
+		}
+	}
+	if len(query.Extensions) > 0 {
+		m.extensions = make(map[string]struct{}, len(query.Extensions))
+		for _, ext := range query.Extensions {
+			ext = strings.TrimPrefix(strings.TrimSpace(ext), ".")
+			if ext != "" {
+				m.extensions["."+strings.ToLower(ext)] = struct{}{}
+			}
+		}
+	}
+	return m, nil
+}
+
+// Match reports whether object matches. relative is the key relative to the search prefix;
+// name patterns containing "/" are matched against it, others against the base name only.
+func (m *objectMatcher) Match(object oss.ObjectProperties, relative string) bool {
+	if m.problemKeys && !objectKeyNeedsEncoding(object.Key) {
+		return false
+	}
+	if m.name != nil {
+		subject := path.Base(relative)
+		if m.nameHasSlash {
+			subject = relative
+		}
+		if !m.name.MatchString(subject) {
+			return false
+		}
+	}
+	if m.minSize > 0 && object.Size < m.minSize {
+		return false
+	}
+	if m.maxSize > 0 && object.Size > m.maxSize {
+		return false
+	}
+	if !m.after.IsZero() && object.LastModified.Before(m.after) {
+		return false
+	}
+	if !m.before.IsZero() && !object.LastModified.Before(m.before) {
+		return false
+	}
+	if len(m.storageClasses) > 0 {
+		if _, ok := m.storageClasses[strings.ToLower(objectStorageClassOrDefault(object.StorageClass))]; !ok {
+			return false
+		}
+	}
+	if len(m.extensions) > 0 {
+		if _, ok := m.extensions[strings.ToLower(path.Ext(object.Key))]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// walkPrefixesParallel lists prefix one folder level at a time and fans the sub-prefixes out
+// to workers. fn is called concurrently for every object found below prefix.
+func walkPrefixesParallel(ctx context.Context, bkt *oss.Bucket, prefix string, workers int, fn func(object oss.ObjectProperties)) error {
+	var (
+		mu       sync.Mutex
+		cond     = sync.NewCond(&mu)
+		queue    = []string{prefix}
+		pending  = 1
+		firstErr error
+	)
+
+	listLevel := func(current string) error {
+		marker := ""
+		for {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			lor, err := bkt.ListObjects(
+				oss.Prefix(current),
+				oss.Delimiter("/"),
+				oss.Marker(marker),
+				oss.MaxKeys(1000),
+			)
+			if err != nil {
+				return fmt.Errorf("failed to list %s: %w", current, err)
+			}
+			for _, object := range lor.Objects {
+				if strings.HasSuffix(object.Key, "/") {
+					continue
+				}
+				fn(object)
+			}
+			if len(lor.CommonPrefixes) > 0 {
+				mu.Lock()
+				queue = append(queue, lor.CommonPrefixes...)
+				pending += len(lor.CommonPrefixes)
+				mu.Unlock()
+				cond.Broadcast()
+			}
+			if !lor.IsTruncated || lor.NextMarker == "" {
+				return nil
+			}
+			marker = lor.NextMarker
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				mu.Lock()
+				for len(queue) == 0 && pending > 0 && firstErr == nil {
+					cond.Wait()
+				}
+				if pending == 0 || firstErr != nil {
+					mu.Unlock()
+					return
+				}
+				current := queue[len(queue)-1]
+				queue = queue[:len(queue)-1]
+				mu.Unlock()
+
+				err := listLevel(current)
+
+				mu.Lock()
+				pending--
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+				cond.Broadcast()
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// SearchObjects starts a recursive search below prefix and returns its search ID. Matches
+// are streamed in pages through "search:results" events; CancelTask stops the search.
+func (s *OSSService) SearchObjects(config OSSConfig, bucketName string, prefix string, query ObjectSearchQuery) (string, error) {
+	matcher, err := newObjectMatcher(query)
+	if err != nil {
+		return "", err
+	}
+	bkt, err := s.openBucket(config, bucketName)
+	if err != nil {
+		return "", err
+	}
+	prefix = normalizeObjectPrefix(prefix)
+	limit := query.MaxResults
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+
+	searchID, ctx := s.startTask("search")
+	go s.runSearch(ctx, searchID, bkt, prefix, matcher, limit)
+	return searchID, nil
+}
+
+func (s *OSSService) runSearch(ctx context.Context, searchID string, bkt *oss.Bucket, prefix string, matcher *objectMatcher, limit int) {
+	defer s.finishTask(searchID)
+
+	var mu sync.Mutex
+	page := ObjectSearchPage{SearchID: searchID, Items: make([]ObjectInfo, 0, searchPageSize)}
+	lastFlush := time.Now()
+	flushLocked := func() {
+		s.emitEvent("search:results", page)
+		page.Items = make([]ObjectInfo, 0, searchPageSize)
+		lastFlush = time.Now()
+	}
+
+	searchCtx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	err := walkPrefixesParallel(searchCtx, bkt, prefix, searchListWorkers, func(object oss.ObjectProperties) {
+		relative := strings.TrimPrefix(object.Key, prefix)
+		matched := matcher.Match(object, relative)
+
+		mu.Lock()
+		defer mu.Unlock()
+		page.ScannedCount++
+		if matched && page.MatchedCount < int64(limit) {
+			page.MatchedCount++
+			restoreState, restoreExpiry := objectRestoreState(object.StorageClass, object.RestoreInfo)
+			page.Items = append(page.Items, ObjectInfo{
+				Name:          relative,
+				Path:          buildOssPath(bkt.BucketName, object.Key),
+				RawKey:        rawObjectKey(object.Key),
+				Size:          object.Size,
+				Type:          "File",
+				LastModified:  formatObjectLastModified(object.LastModified),
+				StorageClass:  object.StorageClass,
+				ETag:          strings.Trim(object.ETag, "\""),
+				RestoreState:  restoreState,
+				RestoreExpiry: restoreExpiry,
+				IsSymlink:     object.Type == ossObjectTypeSymlink,
+			})
+			if page.MatchedCount >= int64(limit) {
+				page.Truncated = true
+				stop()
+			}
+		}
+		if len(page.Items) >= searchPageSize || time.Since(lastFlush) >= searchFlushInterval {
+			flushLocked()
+		}
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	page.Done = true
+	switch {
+	case page.Truncated:
+	case ctx.Err() != nil:
+		page.Cancelled = true
+	case err != nil && !errors.Is(err, context.Canceled):
+		page.Error = err.Error()
+	}
+	flushLocked()
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// SelectObject runs OSS Select, so a query scans a CSV or JSON lines object on the server and
+// only matching records are transferred.
+
+const (
+	selectDefaultLimit = 1000
+	selectMaxLimit     = 100000
+	selectSniffLength  = 64 * 1024
+)
+
+// SelectInputFormat describes the object being queried.
+type SelectInputFormat struct {
+	Format           string `json:"format"`     // "csv" (default) or "json"
+	FileHeader       string `json:"fileHeader"` // CSV: "auto" (default), "use", "ignore" or "none"
+	FieldDelimiter   string `json:"fieldDelimiter"`
+	RecordDelimiter  string `json:"recordDelimiter"`
+	QuoteCharacter   string `json:"quoteCharacter"`
+	CommentCharacter string `json:"commentCharacter"`
+	JSONType         string `json:"jsonType"`    // JSON: "lines" (default) or "document"
+	Compression      string `json:"compression"` // "none" (default) or "gzip"
+}
+
+// SelectOutputOptions limits the rows returned and optionally saves the full result to
+// LocalPath, to TargetKey in the same bucket, or both.
+type SelectOutputOptions struct {
+	Limit     int    `json:"limit"`
+	LocalPath string `json:"localPath"`
+	TargetKey string `json:"targetKey"`
+}
+
+// SelectResult holds up to Limit result rows. CSV results are split into Rows with Columns
+// naming them when the file has a header; JSON results are returned as one record per row.
+type SelectResult struct {
+	Bucket         string     `json:"bucket"`
+	Key            string     `json:"key"`
+	Format         string     `json:"format"`
+	Columns        []string   `json:"columns"`
+	Rows           [][]string `json:"rows"`
+	RowCount       int        `json:"rowCount"`
+	Truncated      bool       `json:"truncated"`
+	HeaderDetected bool       `json:"headerDetected"`
+	LocalPath      string     `json:"localPath,omitempty"`
+	TargetKey      string     `json:"targetKey,omitempty"`
+}
+
+func normalizeSelectInput(input SelectInputFormat) (SelectInputFormat, error) {
+	input.Format = strings.ToLower(strings.TrimSpace(input.Format))
+	if input.Format == "" {
+		input.Format = "csv"
+	}
+	if input.Format != "csv" && input.Format != "json" {
+		return input, fmt.Errorf("unsupported input format: %s", input.Format)
+	}
+	input.FileHeader = strings.ToLower(strings.TrimSpace(input.FileHeader))
+	switch input.FileHeader {
+	case "":
+		input.FileHeader = "auto"
+	case "auto", "use", "ignore", "none":
+	default:
+		return input, fmt.Errorf("unsupported file header mode: %s", input.FileHeader)
+	}
+	input.JSONType = strings.ToLower(strings.TrimSpace(input.JSONType))
+	switch input.JSONType {
+	case "":
+		input.JSONType = "lines"
+	case "lines", "document":
+	default:
+		return input, fmt.Errorf("unsupported JSON type: %s", input.JSONType)
+	}
+	input.Compression = strings.ToLower(strings.TrimSpace(input.Compression))
+	switch input.Compression {
+	case "":
+		input.Compression = "none"
+	case "none", "gzip":
+	default:
+		return input, fmt.Errorf("unsupported compression: %s", input.Compression)
+	}
+	if input.FieldDelimiter == "" {
+		input.FieldDelimiter = ","
+	}
+	if input.RecordDelimiter == "" {
+		input.RecordDelimiter = "\n"
+	}
+	if input.QuoteCharacter == "" {
+		input.QuoteCharacter = "\""
+	}
+	return input, nil
+}
+
+// looksLikeCSVHeader treats a first row as a header when every cell is a distinct,
+// non-empty, non-numeric name.
+func looksLikeCSVHeader(row []string) bool {
+	if len(row) == 0 {
+		return false
+	}
+	seen := make(map[string]struct{}, len(row))
+	for _, cell := range row {
+		cell = strings.TrimSpace(cell)
+		if cell == "" {
+			return false
+		}
+		if _, err := strconv.ParseFloat(cell, 64); err == nil {
+			return false
+		}
+		if _, ok := seen[cell]; ok {
+			return false
+		}
+		seen[cell] = struct{}{}
+	}
+	return true
+}
+
+// sniffCSVHeader reads the start of the object and reports whether its first row is a header.
+func sniffCSVHeader(bkt *oss.Bucket, key string, input SelectInputFormat) (bool, error) {
+	body, err := bkt.GetObject(key, oss.Range(0, selectSniffLength-1))
+	if err != nil {
+		return false, fmt.Errorf("failed to read object header: %w", err)
+	}
+	defer body.Close()
+	var reader io.Reader = body
+	if input.Compression == "gzip" {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return false, fmt.Errorf("failed to read object header: %w", err)
+		}
+		reader = gz
+	}
+	line, err := bufio.NewReader(reader).ReadString(input.RecordDelimiter[0])
+	if err != nil && line == "" {
+		return false, nil
+	}
+	cr := csv.NewReader(strings.NewReader(strings.TrimSuffix(line, input.RecordDelimiter)))
+	cr.Comma = []rune(input.FieldDelimiter)[0]
+	cr.LazyQuotes = true
+	row, err := cr.Read()
+	if err != nil {
+		return false, nil
+	}
+	return looksLikeCSVHeader(row), nil
+}
+
+// SelectObject runs sql against a CSV or JSON lines object with OSS Select, for example
+// "select _1, _3 from ossobject where _2 > 100" or, with a header, "select name from
+// ossobject". Up to outputOptions.Limit rows are returned; the full result can be saved
+// locally or as a new object.
+func (s *OSSService) SelectObject(config OSSConfig, bucketName string, key string, sql string, inputFormat SelectInputFormat, outputOptions SelectOutputOptions) (SelectResult, error) {
+	sql = strings.TrimSpace(sql)
+	if sql == "" {
+		return SelectResult{}, errors.New("query is empty")
+	}
+	input, err := normalizeSelectInput(inputFormat)
+	if err != nil {
+		return SelectResult{}, err
+	}
+	bkt, key, err := s.openObjectForRange(config, bucketName, key)
+	if err != nil {
+		return SelectResult{}, err
+	}
+	limit := outputOptions.Limit
+	if limit <= 0 {
+		limit = selectDefaultLimit
+	}
+	if limit > selectMaxLimit {
+		limit = selectMaxLimit
+	}
+	targetKey := normalizeObjectKey(outputOptions.TargetKey)
+	if strings.HasSuffix(targetKey, "/") {
+		return SelectResult{}, errors.New("target key must be a file name")
+	}
+
+	result := SelectResult{
+		Bucket:  bkt.BucketName,
+		Key:     objectKeyForClient(key),
+		Format:  input.Format,
+		Columns: []string{},
+		Rows:    [][]string{},
+	}
+
+	request := oss.SelectRequest{Expression: sql}
+	if input.Compression == "gzip" {
+		request.InputSerializationSelect.CompressionType = "GZIP"
+	}
+	if input.Format == "json" {
+		request.InputSerializationSelect.JsonBodyInput.JSONType = strings.ToUpper(input.JSONType)
+		request.OutputSerializationSelect.JsonBodyOutput.RecordDelimiter = "\n"
+	} else {
+		header := input.FileHeader
+		if header == "auto" {
+			detected, err := sniffCSVHeader(bkt, key, input)
+			if err != nil {
+				return SelectResult{}, err
+			}
+			result.HeaderDetected = detected
+			header = "none"
+			if detected {
+				header = "use"
+			}
+		}
+		csvInput := &request.InputSerializationSelect.CsvBodyInput
+		csvInput.FileHeaderInfo = strings.ToUpper(header)
+		csvInput.FieldDelimiter = input.FieldDelimiter
+		csvInput.RecordDelimiter = input.RecordDelimiter
+		csvInput.QuoteCharacter = input.QuoteCharacter
+		csvInput.CommentCharacter = input.CommentCharacter
+		request.OutputSerializationSelect.CsvBodyOutput.FieldDelimiter = ","
+		request.OutputSerializationSelect.CsvBodyOutput.RecordDelimiter = "\n"
+		if header == "use" {
+			outputHeader := true
+			request.OutputSerializationSelect.OutputHeader = &outputHeader
+		}
+	}
+
+	body, err := bkt.SelectObject(key, request)
+	if err != nil {
+		return SelectResult{}, fmt.Errorf("select failed: %w", err)
+	}
+	defer body.Close()
+
+	// With a destination the whole result is saved first and the preview is read back from
+	// the saved file.
+	var source io.Reader = body
+	localPath := strings.TrimSpace(outputOptions.LocalPath)
+	savePath := localPath
+	if savePath == "" && targetKey != "" {
+		tmp, err := os.CreateTemp("", "walioss-select-*")
+		if err != nil {
+			return SelectResult{}, fmt.Errorf("failed to create temp file: %w", err)
+		}
+		savePath = tmp.Name()
+		tmp.Close()
+		defer os.Remove(savePath)
+	}
+	if savePath != "" {
+		if err := os.MkdirAll(filepath.Dir(savePath), 0o755); err != nil {
+			return SelectResult{}, fmt.Errorf("create local directory failed: %w", err)
+		}
+		file, err := os.Create(savePath)
+		if err != nil {
+			return SelectResult{}, fmt.Errorf("failed to save result: %w", err)
+		}
+		_, copyErr := io.Copy(file, body)
+		closeErr := file.Close()
+		if copyErr == nil {
+			copyErr = closeErr
+		}
+		if copyErr != nil {
+			return SelectResult{}, fmt.Errorf("failed to save result: %w", copyErr)
+		}
+		if targetKey != "" {
+			if err := bkt.PutObjectFromFile(targetKey, savePath); err != nil {
+				return SelectResult{}, fmt.Errorf("failed to upload result: %w", err)
+			}
+			s.invalidateListingCacheForConfig(config, bkt.BucketName, targetKey)
+			result.TargetKey = targetKey
+		}
+		if localPath != "" {
+			result.LocalPath = localPath
+		}
+		saved, err := os.Open(savePath)
+		if err != nil {
+			return SelectResult{}, fmt.Errorf("failed to read result: %w", err)
+		}
+		defer saved.Close()
+		source = saved
+	}
+
+	if input.Format == "json" {
+		scanner := bufio.NewScanner(source)
+		scanner.Buffer(make([]byte, 64*1024), objectLineMaxBytes)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			if len(result.Rows) >= limit {
+				result.Truncated = true
+				break
+			}
+			result.Rows = append(result.Rows, []string{line})
+		}
+		if err := scanner.Err(); err != nil {
+			return SelectResult{}, fmt.Errorf("failed to read result: %w", err)
+		}
+	} else {
+		cr := csv.NewReader(source)
+		cr.FieldsPerRecord = -1
+		cr.LazyQuotes = true
+		withHeader := request.OutputSerializationSelect.OutputHeader != nil
+		for {
+			row, err := cr.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return SelectResult{}, fmt.Errorf("failed to read result: %w", err)
+			}
+			if withHeader {
+				result.Columns = row
+				withHeader = false
+				continue
+			}
+			if len(result.Rows) >= limit {
+				result.Truncated = true
+				break
+			}
+			result.Rows = append(result.Rows, row)
+		}
+	}
+	result.RowCount = len(result.Rows)
+	return result, nil
+}
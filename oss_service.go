@@ -78,6 +78,30 @@ type OSSService struct {
 	transferHistoryLoaded        bool
 	transferHistoryLoadedDir     string
 	transferHistoryLastPersistAt time.Time
+	restoreWatchMu               sync.Mutex
+	restoreWatches               map[string]*restoreWatch
+	restoreWatchRunning          bool
+	taskMu                       sync.Mutex
+	tasks                        map[string]context.CancelFunc
+	folderIndexMu                sync.Mutex
+	folderIndexes                map[string]*folderIndex
+	folderStatsMu                sync.Mutex
+	folderStatsCache             map[string]FolderStats
+	folderStatsLoadedPath        string
+	listingCacheMu               sync.Mutex
+	listingRevalidating          map[string]struct{}
+	offlineMode                  bool
+	shareLinkMu                  sync.Mutex
+	thumbnailCacheMu             sync.Mutex
+	compareMu                    sync.Mutex
+	comparisons                  []*folderComparison
+	locationComparisons          []*locationComparison
+	streamCopyMu                 sync.Mutex
+	streamCopySources            map[string]OSSConfig
+	recycleBinMu                 sync.Mutex
+	journalMu                    sync.Mutex
+	webdavMu                     sync.Mutex
+	webdav                       *webdavServer
 }
 
 const (
@@ -769,145 +793,41 @@ func (s *OSSService) parseBucketList(output string) []BucketInfo {
 	return buckets
 }
 
-// ListObjects lists objects in a bucket with optional prefix
+// ListObjects lists the folders and files directly under prefix. It pages through the
+// URL-encoded SDK listing so keys with spaces, control characters or invalid UTF-8 are kept.
 func (s *OSSService) ListObjects(config OSSConfig, bucketName string, prefix string) ([]ObjectInfo, error) {
-	bucketUrl := fmt.Sprintf("oss://%s/%s", bucketName, prefix)
-	region := normalizeRegion(config.Region)
-	endpoint := normalizeEndpoint(config.Endpoint)
-
-	args := []string{
-		"ls",
-		bucketUrl,
-		"--access-key-id", config.AccessKeyID,
-		"--access-key-secret", config.AccessKeySecret,
-		"--region", region,
-	}
-
-	if endpoint != "" {
-		args = append(args, "--endpoint", endpoint)
-	}
-
-	output, err := s.runOssutil(args...)
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to list objects: %s", ossutilOutputOrError(err, output))
-	}
-
-	return s.parseObjectList(string(output), bucketName, prefix), nil
-}
-
-// parseObjectList parses ossutil ls output and simulates folder navigation
-func (s *OSSService) parseObjectList(output string, bucketName string, prefix string) []ObjectInfo {
-	var objects []ObjectInfo
-	lines := strings.Split(output, "\n")
-	seenFolders := make(map[string]bool)
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "Object Number") || strings.HasPrefix(line, "Total Size") {
-			continue
-		}
-
-		// Parse file lines with metadata
-		// Format: LastModifiedTime Size(B) StorageClass ETAG ObjectName
-		// Example: 2023-01-01 12:00:00 1234567 Standard D41D8CD98F oss://bucket/path/file.mp4
-		fields := strings.Fields(line)
-		if len(fields) < 5 {
-			continue
-		}
-
-		objectPath := fields[len(fields)-1]
-		if !strings.HasPrefix(objectPath, "oss://") {
-			continue
-		}
-
-		// Extract full key relative to bucket
-		fullKey := strings.TrimPrefix(objectPath, fmt.Sprintf("oss://%s/", bucketName))
-
-		// Skip if this is before our prefix
-		if !strings.HasPrefix(fullKey, prefix) && fullKey+"/" != prefix {
-			continue
-		}
-
-		// Get the part after our prefix
-		relativePath := strings.TrimPrefix(fullKey, prefix)
-		if relativePath == "" {
-			continue // Skip the prefix folder itself
+	objects := make([]ObjectInfo, 0, 64)
+	marker := ""
+	for {
+		page, err := s.listObjectsPage(config, bucketName, prefix, marker, 1000)
+		if err != nil {
+			return nil, err
 		}
-
-		// Check if this is a direct child or a nested item
-		slashIdx := strings.Index(relativePath, "/")
-
-		if slashIdx != -1 {
-			// This is inside a subfolder - extract the folder name
-			folderName := relativePath[:slashIdx]
-			if folderName == "" {
-				continue
-			}
-
-			// Only add folder once
-			if !seenFolders[folderName] {
-				seenFolders[folderName] = true
-				objects = append(objects, ObjectInfo{
-					Name: folderName,
-					Path: fmt.Sprintf("oss://%s/%s%s/", bucketName, prefix, folderName),
-					Type: "Folder",
-				})
-			}
-		} else {
-			// This is a direct child file
-			// Parse metadata - find oss:// path position to determine field layout
-			// Format may include timezone: 2023-01-01 12:00:00 +0800 1234567 Standard ETAG oss://...
-			// Or without: 2023-01-01 12:00:00 1234567 Standard ETAG oss://...
-			ossPathIdx := len(fields) - 1
-
-			var size int64
-			var lastModified, storageClass string
-
-			// Work backwards from oss:// path
-			// fields[ossPathIdx] = oss://path
-			// fields[ossPathIdx-1] = ETAG
-			// fields[ossPathIdx-2] = StorageClass
-			// fields[ossPathIdx-3] = Size
-			if ossPathIdx >= 4 {
-				fmt.Sscanf(fields[ossPathIdx-3], "%d", &size)
-				storageClass = fields[ossPathIdx-2]
-			}
-
-			// Date and time are always the first two fields
-			if len(fields) >= 2 {
-				lastModified = fields[0] + " " + fields[1]
-			}
-
-			objects = append(objects, ObjectInfo{
-				Name:         relativePath,
-				Path:         objectPath,
-				Size:         size,
-				Type:         "File",
-				LastModified: lastModified,
-				StorageClass: storageClass,
-			})
+		objects = append(objects, page.Items...)
+		if !page.IsTruncated || page.NextMarker == "" {
+			break
 		}
+		marker = page.NextMarker
 	}
-
-	return objects
+	return objects, nil
 }
 
 // DownloadFile downloads a file from OSS
 func (s *OSSService) DownloadFile(config OSSConfig, bucket string, object string, localPath string) error {
-	cloudUrl := fmt.Sprintf("oss://%s/%s", bucket, object)
+	cloudUrl, localArg, keyArgs := ossutilObjectURL(bucket, objectKeyParam(object), localPath)
 	region := normalizeRegion(config.Region)
 	endpoint := normalizeEndpoint(config.Endpoint)
 
 	args := []string{
 		"cp",
 		cloudUrl,
-		localPath,
+		localArg,
 		"--access-key-id", config.AccessKeyID,
 		"--access-key-secret", config.AccessKeySecret,
 		"--region", region,
 		"-f", // Force overwrite
 	}
+	args = append(args, keyArgs...)
 
 	if endpoint != "" {
 		args = append(args, "--endpoint", endpoint)
@@ -925,19 +845,21 @@ func (s *OSSService) DownloadFile(config OSSConfig, bucket string, object string
 // UploadFile uploads a file to OSS
 func (s *OSSService) UploadFile(config OSSConfig, bucket string, prefix string, localPath string) error {
 	fileName := filepath.Base(localPath)
-	cloudUrl := fmt.Sprintf("oss://%s/%s%s", bucket, prefix, fileName)
+	prefix = objectKeyParam(prefix)
+	cloudUrl, localArg, keyArgs := ossutilObjectURL(bucket, prefix+fileName, localPath)
 	region := normalizeRegion(config.Region)
 	endpoint := normalizeEndpoint(config.Endpoint)
 
 	args := []string{
 		"cp",
-		localPath,
+		localArg,
 		cloudUrl,
 		"--access-key-id", config.AccessKeyID,
 		"--access-key-secret", config.AccessKeySecret,
 		"--region", region,
 		"-f", // Force overwrite
 	}
+	args = append(args, keyArgs...)
 
 	if endpoint != "" {
 		args = append(args, "--endpoint", endpoint)
@@ -948,13 +870,27 @@ func (s *OSSService) UploadFile(config OSSConfig, bucket string, prefix string,
 	if err != nil {
 		return fmt.Errorf("upload failed: %s", ossutilOutputOrError(err, output))
 	}
+	s.invalidateListingCacheForConfig(config, bucket, prefix+fileName)
 
 	return nil
 }
 
-// DeleteObject deletes an object from OSS
+// DeleteObject deletes an object from OSS, or moves it to the recycle bin when the profile
+// has one enabled. Deletes are journaled, and can be undone unless they removed data for
+// good.
 func (s *OSSService) DeleteObject(config OSSConfig, bucket string, object string) error {
-	cloudUrl := fmt.Sprintf("oss://%s/%s", bucket, object)
+	if settings := s.recycleBinSettings(s.resolveTransferProfileName(config)); settings.Enabled {
+		trashBucket, trashKeys, err := s.moveToTrash(config, settings, bucket, object)
+		if len(trashKeys) > 0 {
+			s.recordOperation(config, newTrashDeleteOperation(bucket, object, trashBucket, trashKeys))
+		}
+		return err
+	}
+	if handled, err := s.deleteFromVersionedBucket(config, bucket, object); handled {
+		return err
+	}
+	object = objectKeyParam(object)
+	cloudUrl, _, keyArgs := ossutilObjectURL(bucket, object, "")
 	region := normalizeRegion(config.Region)
 	endpoint := normalizeEndpoint(config.Endpoint)
 
@@ -966,6 +902,7 @@ func (s *OSSService) DeleteObject(config OSSConfig, bucket string, object string
 		"--region", region,
 		"-f", // Force delete without confirmation prompt (since we handle it in UI)
 	}
+	args = append(args, keyArgs...)
 
 	// recursive delete if it looks like a directory (though in OSS directories are fake, ossutil -r helps for common prefixes)
 	if strings.HasSuffix(object, "/") {
@@ -977,63 +914,40 @@ func (s *OSSService) DeleteObject(config OSSConfig, bucket string, object string
 	}
 
 	output, err := s.runOssutil(args...)
+	s.invalidateListingCacheForConfig(config, bucket, object)
 
 	if err != nil {
 		return fmt.Errorf("delete failed: %s", ossutilOutputOrError(err, output))
 	}
 
+	s.recordOperation(config, OperationEntry{
+		Kind:        OperationKindDelete,
+		Description: "delete " + buildOssPath(normalizeTransferBucket(bucket), object),
+		Bucket:      normalizeTransferBucket(bucket),
+		Key:         object,
+		Reason:      "it was deleted permanently; enable bucket versioning or the recycle bin to make deletes undoable",
+	})
 	return nil
 }
 
 func (s *OSSService) PresignObject(config OSSConfig, bucket string, object string, expiresDuration string) (string, error) {
-	bucket = strings.TrimSpace(bucket)
-	object = strings.TrimLeft(strings.TrimSpace(object), "/")
-
-	if bucket == "" {
-		return "", fmt.Errorf("bucket name is required")
-	}
-	if object == "" {
-		return "", fmt.Errorf("object key is required")
-	}
-
-	expiresDuration = strings.TrimSpace(expiresDuration)
-	if expiresDuration == "" {
-		expiresDuration = "15m"
-	}
-
-	expires, err := time.ParseDuration(expiresDuration)
-	if err != nil {
-		return "", fmt.Errorf("invalid expires duration: %w", err)
-	}
-	if expires < 0 {
-		return "", fmt.Errorf("invalid expires duration: must be non-negative")
-	}
+	return s.presignObject(config, bucket, object, expiresDuration)
+}
 
-	client, err := sdkClientFromConfig(config)
+func (s *OSSService) presignObject(config OSSConfig, bucket string, object string, expiresDuration string, options ...oss.Option) (string, error) {
+	expires, err := parsePresignExpires(expiresDuration)
 	if err != nil {
 		return "", err
 	}
-	bkt, err := client.Bucket(bucket)
-	if err != nil {
-		return "", fmt.Errorf("failed to open bucket: %w", err)
-	}
-
-	timeoutSeconds := int64(expires.Seconds())
-	signedURL, err := bkt.SignURL(object, oss.HTTPGet, timeoutSeconds)
-	if err != nil {
-		return "", fmt.Errorf("presign failed: %w", err)
-	}
-
-	parts := strings.SplitN(signedURL, "?", 2)
-	parts[0] = strings.ReplaceAll(parts[0], "%2F", "/")
-	if len(parts) == 2 {
-		return parts[0] + "?" + parts[1], nil
-	}
-	return parts[0], nil
+	return signObjectURL(config, bucket, object, oss.HTTPGet, expires, nil, options...)
 }
 
 func (s *OSSService) GetObjectText(config OSSConfig, bucket string, object string, maxBytes int) (string, error) {
-	cloudUrl := fmt.Sprintf("oss://%s/%s", bucket, object)
+	return s.getObjectText(config, bucket, object, "", maxBytes)
+}
+
+func (s *OSSService) getObjectText(config OSSConfig, bucket string, object string, versionID string, maxBytes int) (string, error) {
+	cloudUrl, _, keyArgs := ossutilObjectURL(bucket, objectKeyParam(object), "")
 	region := normalizeRegion(config.Region)
 	endpoint := normalizeEndpoint(config.Endpoint)
 
@@ -1052,6 +966,11 @@ func (s *OSSService) GetObjectText(config OSSConfig, bucket string, object strin
 		"--region", region,
 		"--count", strconv.Itoa(maxBytes),
 	}
+	args = append(args, keyArgs...)
+
+	if versionID != "" {
+		args = append(args, "--version-id", versionID)
+	}
 
 	if endpoint != "" {
 		args = append(args, "--endpoint", endpoint)
@@ -1097,7 +1016,11 @@ func (s *OSSService) GetObjectText(config OSSConfig, bucket string, object strin
 		return "", fmt.Errorf("read object failed: %s", msg)
 	}
 
-	return stripOssutilElapsedFooter(string(stdout)), nil
+	text, _, _, err := decodeObjectText([]byte(stripOssutilElapsedFooter(string(stdout))), "", true)
+	if err != nil {
+		return "", err
+	}
+	return text, nil
 }
 
 func stripOssutilElapsedFooter(output string) string {
@@ -1137,47 +1060,13 @@ func isOssutilElapsedFooterLine(line string) bool {
 	return err == nil
 }
 
-func (s *OSSService) PutObjectText(config OSSConfig, bucket string, object string, content string) error {
-	cloudUrl := fmt.Sprintf("oss://%s/%s", bucket, object)
-	region := normalizeRegion(config.Region)
-	endpoint := normalizeEndpoint(config.Endpoint)
-
-	tmpFile, err := os.CreateTemp("", "walioss-edit-*")
-	if err != nil {
-		return fmt.Errorf("create temp file failed: %w", err)
-	}
-	defer func() {
-		_ = os.Remove(tmpFile.Name())
-	}()
-
-	if _, err := tmpFile.WriteString(content); err != nil {
-		_ = tmpFile.Close()
-		return fmt.Errorf("write temp file failed: %w", err)
-	}
-	if err := tmpFile.Close(); err != nil {
-		return fmt.Errorf("close temp file failed: %w", err)
-	}
-
-	args := []string{
-		"cp",
-		tmpFile.Name(),
-		cloudUrl,
-		"--access-key-id", config.AccessKeyID,
-		"--access-key-secret", config.AccessKeySecret,
-		"--region", region,
-		"-f",
-	}
-
-	if endpoint != "" {
-		args = append(args, "--endpoint", endpoint)
-	}
-
-	output, err := s.runOssutil(args...)
-	if err != nil {
-		return fmt.Errorf("save failed: %s", ossutilOutputOrError(err, output))
-	}
-
-	return nil
+// PutObjectText overwrites an object with content unconditionally, keeping its headers and
+// tags. The text is saved in textEncoding (UTF-8 when empty), after a byte order mark when
+// bom is set. Editors should use OpenObjectText and SaveObjectText so concurrent changes
+// are not lost.
+func (s *OSSService) PutObjectText(config OSSConfig, bucket string, object string, content string, textEncoding string, bom bool) error {
+	_, err := s.SaveObjectText(config, bucket, object, content, SaveTextOptions{Encoding: textEncoding, BOM: bom})
+	return err
 }
 
 // CheckOssutilInstalled checks if ossutil is installed and accessible
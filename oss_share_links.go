@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Share links are presigned URLs handed out on purpose (ShareObject, PresignFolder). They are
+// recorded per profile in share-links.json in the work dir so it stays answerable which files
+// were shared and until when. URLs signed for previews and thumbnails are not recorded.
+
+const (
+	shareLinksFileName      = "share-links.json"
+	shareLinksSchemaVersion = 1
+)
+
+type ShareLink struct {
+	ID          string `json:"id"`
+	Bucket      string `json:"bucket"`
+	Key         string `json:"key"`
+	VersionID   string `json:"versionId,omitempty"`
+	Method      string `json:"method"`
+	URL         string `json:"url"`
+	CreatedAtMs int64  `json:"createdAtMs"`
+	ExpiresAtMs int64  `json:"expiresAtMs"`
+	CreatedBy   string `json:"createdBy"`
+	Note        string `json:"note,omitempty"`
+	Source      string `json:"source"` // "object" or "folder"
+	Expired     bool   `json:"expired"`
+}
+
+type shareLinksStore struct {
+	SchemaVersion int                    `json:"schemaVersion"`
+	Profiles      map[string][]ShareLink `json:"profiles"`
+}
+
+func (s *OSSService) shareLinksPath() string {
+	return filepath.Join(normalizeWorkDirPath(s.configDir, s.defaultConfigDir), shareLinksFileName)
+}
+
+func (s *OSSService) loadShareLinksLocked() shareLinksStore {
+	store := shareLinksStore{SchemaVersion: shareLinksSchemaVersion, Profiles: map[string][]ShareLink{}}
+	data, err := os.ReadFile(s.shareLinksPath())
+	if err != nil {
+		return store
+	}
+	if err := json.Unmarshal(data, &store); err != nil || store.Profiles == nil {
+		store.Profiles = map[string][]ShareLink{}
+	}
+	store.SchemaVersion = shareLinksSchemaVersion
+	return store
+}
+
+func (s *OSSService) saveShareLinksLocked(store shareLinksStore) error {
+	path := s.shareLinksPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// shareLinkCreator names the local account that generated a link, as "user@host".
+func shareLinkCreator() string {
+	name := "unknown"
+	if current, err := user.Current(); err == nil && current.Username != "" {
+		name = current.Username
+	}
+	if host, err := os.Hostname(); err == nil && host != "" {
+		name += "@" + host
+	}
+	return name
+}
+
+// recordShareLinks appends links to the registry of the profile config belongs to. Only
+// saved profiles have a registry; links made from other connections are not recorded.
+func (s *OSSService) recordShareLinks(config OSSConfig, links []ShareLink) error {
+	if len(links) == 0 {
+		return nil
+	}
+	profileName := s.resolveTransferProfileName(config)
+	if profileName == transferProfileAnonymous {
+		return nil
+	}
+	creator := shareLinkCreator()
+	now := time.Now().UnixMilli()
+
+	s.shareLinkMu.Lock()
+	defer s.shareLinkMu.Unlock()
+	store := s.loadShareLinksLocked()
+	for i := range links {
+		if links[i].ID == "" {
+			links[i].ID = s.newTransferID()
+		}
+		if links[i].CreatedAtMs == 0 {
+			links[i].CreatedAtMs = now
+		}
+		links[i].CreatedBy = creator
+		links[i].Expired = false
+	}
+	store.Profiles[profileName] = append(store.Profiles[profileName], links...)
+	if err := s.saveShareLinksLocked(store); err != nil {
+		return fmt.Errorf("failed to record share links: %w", err)
+	}
+	return nil
+}
+
+// ShareObject presigns object like PresignObjectWithOptions and records the link in the
+// share registry with note.
+func (s *OSSService) ShareObject(config OSSConfig, bucket string, object string, options PresignOptions, note string) (ShareLink, error) {
+	presigned, err := s.PresignObjectWithOptions(config, bucket, object, options)
+	if err != nil {
+		return ShareLink{}, err
+	}
+	link := ShareLink{
+		Bucket:      normalizeTransferBucket(bucket),
+		Key:         normalizeObjectKey(object),
+		VersionID:   strings.TrimSpace(options.VersionID),
+		Method:      presigned.Method,
+		URL:         presigned.URL,
+		ExpiresAtMs: presigned.ExpiresAtMs,
+		Note:        strings.TrimSpace(note),
+		Source:      "object",
+	}
+	links := []ShareLink{link}
+	if err := s.recordShareLinks(config, links); err != nil {
+		return ShareLink{}, err
+	}
+	return links[0], nil
+}
+
+// ListShareLinks returns the profile's share links, newest first. status is "active",
+// "expired" or empty for all links.
+func (s *OSSService) ListShareLinks(config OSSConfig, status string) ([]ShareLink, error) {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if status != "" && status != "active" && status != "expired" {
+		return nil, fmt.Errorf("unknown share link status: %s", status)
+	}
+	profileName := s.resolveTransferProfileName(config)
+	if profileName == transferProfileAnonymous {
+		return []ShareLink{}, nil
+	}
+
+	s.shareLinkMu.Lock()
+	links := s.loadShareLinksLocked().Profiles[profileName]
+	s.shareLinkMu.Unlock()
+
+	now := time.Now().UnixMilli()
+	out := make([]ShareLink, 0, len(links))
+	for _, link := range links {
+		link.Expired = link.ExpiresAtMs <= now
+		if (status == "active" && link.Expired) || (status == "expired" && !link.Expired) {
+			continue
+		}
+		out = append(out, link)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAtMs > out[j].CreatedAtMs })
+	return out, nil
+}
+
+func (s *OSSService) findShareLink(config OSSConfig, id string) (ShareLink, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return ShareLink{}, errors.New("share link id is empty")
+	}
+	links, err := s.ListShareLinks(config, "")
+	if err != nil {
+		return ShareLink{}, err
+	}
+	for _, link := range links {
+		if link.ID == id {
+			return link, nil
+		}
+	}
+	return ShareLink{}, errors.New("share link not found")
+}
+
+// CopyShareLink puts the URL of a recorded link on the clipboard again.
+func (s *OSSService) CopyShareLink(config OSSConfig, id string) error {
+	link, err := s.findShareLink(config, id)
+	if err != nil {
+		return err
+	}
+	if link.Expired {
+		return errors.New("share link has expired")
+	}
+
+	s.transferCtxMu.RLock()
+	ctx := s.transferCtx
+	s.transferCtxMu.RUnlock()
+	if ctx == nil {
+		return errors.New("clipboard is not available")
+	}
+	if err := runtime.ClipboardSetText(ctx, link.URL); err != nil {
+		return fmt.Errorf("failed to copy link: %w", err)
+	}
+	return nil
+}
+
+// ExportShareLinks writes the profile's share links to localPath as CSV or JSON.
+func (s *OSSService) ExportShareLinks(config OSSConfig, status string, format string, localPath string) error {
+	localPath = strings.TrimSpace(localPath)
+	if localPath == "" {
+		return errors.New("local path is empty")
+	}
+	links, err := s.ListShareLinks(config, status)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "json":
+		encoder := json.NewEncoder(&buf)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(links); err != nil {
+			return fmt.Errorf("export failed: %w", err)
+		}
+	case "", "csv":
+		writer := csv.NewWriter(&buf)
+		_ = writer.Write([]string{"bucket", "key", "versionId", "method", "createdAt", "expiresAt", "expired", "createdBy", "note", "source", "url"})
+		for _, link := range links {
+			_ = writer.Write([]string{
+				link.Bucket,
+				link.Key,
+				link.VersionID,
+				link.Method,
+				time.UnixMilli(link.CreatedAtMs).UTC().Format(time.RFC3339),
+				time.UnixMilli(link.ExpiresAtMs).UTC().Format(time.RFC3339),
+				fmt.Sprint(link.Expired),
+				link.CreatedBy,
+				link.Note,
+				link.Source,
+				link.URL,
+			})
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return fmt.Errorf("export failed: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+		return fmt.Errorf("create local directory failed: %w", err)
+	}
+	if err := os.WriteFile(localPath, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("export failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+var supportedStorageClasses = []oss.StorageClassType{
+	oss.StorageStandard,
+	oss.StorageIA,
+	oss.StorageArchive,
+	oss.StorageColdArchive,
+	oss.StorageDeepColdArchive,
+}
+
+// StorageClassChangeGroup summarizes the objects that move from one storage class to another,
+// or, in Skipped, the objects of a class that cannot move and why.
+type StorageClassChangeGroup struct {
+	FromClass   string `json:"fromClass"`
+	ToClass     string `json:"toClass"`
+	ObjectCount int    `json:"objectCount"`
+	TotalBytes  int64  `json:"totalBytes"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+// storageClassSkipRestoreFirst is the reason archived objects that are not restored are
+// skipped: OSS cannot copy them, so their class cannot be changed yet.
+const storageClassSkipRestoreFirst = "restore first"
+
+// StorageClassChangeEstimate is the preview shown before a storage class change is started.
+type StorageClassChangeEstimate struct {
+	TargetClass    string                    `json:"targetClass"`
+	ObjectCount    int                       `json:"objectCount"`
+	TotalBytes     int64                     `json:"totalBytes"`
+	UnchangedCount int                       `json:"unchangedCount"`
+	UnchangedBytes int64                     `json:"unchangedBytes"`
+	SkippedCount   int                       `json:"skippedCount"`
+	SkippedBytes   int64                     `json:"skippedBytes"`
+	Changes        []StorageClassChangeGroup `json:"changes"`
+	Skipped        []StorageClassChangeGroup `json:"skipped"`
+}
+
+func normalizeStorageClass(storageClass string) (oss.StorageClassType, error) {
+	storageClass = strings.TrimSpace(storageClass)
+	for _, candidate := range supportedStorageClasses {
+		if strings.EqualFold(string(candidate), storageClass) {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported storage class: %s", storageClass)
+}
+
+func objectStorageClassOrDefault(storageClass string) string {
+	if strings.TrimSpace(storageClass) == "" {
+		return string(oss.StorageStandard)
+	}
+	return storageClass
+}
+
+// planStorageClassChange splits targets into objects that need rewriting and an estimate per
+// class. Archived objects that are not restored cannot be copied and are reported as skipped.
+func planStorageClassChange(targets []objectTarget, targetClass oss.StorageClassType) ([]objectTarget, StorageClassChangeEstimate) {
+	estimate := StorageClassChangeEstimate{
+		TargetClass: string(targetClass),
+		Changes:     []StorageClassChangeGroup{},
+		Skipped:     []StorageClassChangeGroup{},
+	}
+	changes := make([]objectTarget, 0, len(targets))
+	groups := make(map[string]*StorageClassChangeGroup)
+	skipped := make(map[string]*StorageClassChangeGroup)
+
+	for _, target := range targets {
+		fromClass := objectStorageClassOrDefault(target.StorageClass)
+		if strings.EqualFold(fromClass, string(targetClass)) {
+			estimate.UnchangedCount++
+			estimate.UnchangedBytes += target.Size
+			continue
+		}
+		if state, _ := objectRestoreState(fromClass, target.RestoreInfo); state != "" && state != RestoreStateRestored {
+			group, ok := skipped[fromClass]
+			if !ok {
+				group = &StorageClassChangeGroup{FromClass: fromClass, ToClass: string(targetClass), Reason: storageClassSkipRestoreFirst}
+				skipped[fromClass] = group
+			}
+			group.ObjectCount++
+			group.TotalBytes += target.Size
+			estimate.SkippedCount++
+			estimate.SkippedBytes += target.Size
+			continue
+		}
+
+		group, ok := groups[fromClass]
+		if !ok {
+			group = &StorageClassChangeGroup{FromClass: fromClass, ToClass: string(targetClass)}
+			groups[fromClass] = group
+		}
+		group.ObjectCount++
+		group.TotalBytes += target.Size
+		estimate.ObjectCount++
+		estimate.TotalBytes += target.Size
+		changes = append(changes, target)
+	}
+
+	for _, group := range groups {
+		estimate.Changes = append(estimate.Changes, *group)
+	}
+	sort.Slice(estimate.Changes, func(i, j int) bool {
+		return estimate.Changes[i].FromClass < estimate.Changes[j].FromClass
+	})
+	for _, group := range skipped {
+		estimate.Skipped = append(estimate.Skipped, *group)
+	}
+	sort.Slice(estimate.Skipped, func(i, j int) bool {
+		return estimate.Skipped[i].FromClass < estimate.Skipped[j].FromClass
+	})
+	return changes, estimate
+}
+
+func (s *OSSService) openStorageClassSelection(config OSSConfig, bucketName string, keys []string, storageClass string) (*oss.Bucket, oss.StorageClassType, []objectTarget, error) {
+	targetClass, err := normalizeStorageClass(storageClass)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	bkt, err := s.openBucket(config, bucketName)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	targets, err := collectObjectTargets(bkt, keys)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return bkt, targetClass, targets, nil
+}
+
+// EstimateStorageClassChange reports how many objects and bytes would change class, grouped
+// by their current storage class. Keys ending with "/" select a whole prefix.
+func (s *OSSService) EstimateStorageClassChange(config OSSConfig, bucketName string, keys []string, storageClass string) (StorageClassChangeEstimate, error) {
+	_, targetClass, targets, err := s.openStorageClassSelection(config, bucketName, keys, storageClass)
+	if err != nil {
+		return StorageClassChangeEstimate{}, err
+	}
+	_, estimate := planStorageClassChange(targets, targetClass)
+	return estimate, nil
+}
+
+// EnqueueStorageClassChange rewrites the selected objects in place with CopyObject, keeping
+// their metadata, and returns the ID of the transfer that tracks the job.
+func (s *OSSService) EnqueueStorageClassChange(config OSSConfig, bucketName string, keys []string, storageClass string) (string, error) {
+	bkt, targetClass, targets, err := s.openStorageClassSelection(config, bucketName, keys, storageClass)
+	if err != nil {
+		return "", err
+	}
+
+	changes, estimate := planStorageClassChange(targets, targetClass)
+	if len(changes) == 0 {
+		if estimate.SkippedCount > 0 {
+			return "", fmt.Errorf("%d archived object(s) must be restored first", estimate.SkippedCount)
+		}
+		return "", fmt.Errorf("all selected objects are already %s", targetClass)
+	}
+
+	selectionName, selectionKey := describeObjectSelection(keys)
+	name := fmt.Sprintf("%s → %s", selectionName, targetClass)
+	if estimate.ObjectCount > 1 {
+		name = fmt.Sprintf("%s (%d objects) → %s", selectionName, estimate.ObjectCount, targetClass)
+	}
+
+	update := TransferUpdate{
+		Type:   TransferTypeStorageClass,
+		Name:   name,
+		Bucket: bkt.BucketName,
+		Key:    selectionKey,
+	}
+	id := s.enqueueObjectJob(config, update, bkt, changes, func(bkt *oss.Bucket, target objectTarget) error {
+		src := objectCopySource{Bucket: bkt.BucketName, Key: target.Key, Size: target.Size}
+		return copyObjectPreservingMetadata(bkt, src, target.Key, oss.ObjectStorageClass(targetClass))
+	}, nil)
+	return id, nil
+}
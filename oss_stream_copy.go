@@ -0,0 +1,582 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// Copies between profiles cannot use CopyObject, which needs one set of credentials that can
+// read the source and write the destination. Instead the source is read with ranged GETs
+// and each range is uploaded as a part to the destination, so nothing touches local disk.
+// The parts uploaded so far are checkpointed in <work dir>/stream-copy; a failed copy keeps
+// its multipart upload and resumes from the checkpoint when it is run again.
+
+const (
+	streamCopyDirName       = "stream-copy"
+	streamCopyMinPartSize   = 16 * 1024 * 1024
+	streamCopyMaxParts      = 10000
+	streamCopyRoutines      = 4
+	streamCopyEmitInterval  = 250 * time.Millisecond
+	streamCopySchemaVersion = 1
+)
+
+// streamCopyCheckpoint records an unfinished multipart copy. It is only reused while the
+// source still has the same ETag and size.
+type streamCopyCheckpoint struct {
+	SchemaVersion int              `json:"schemaVersion"`
+	SourceProfile string           `json:"sourceProfile"`
+	SourceBucket  string           `json:"sourceBucket"`
+	SourceKey     string           `json:"sourceKey"`
+	SourceETag    string           `json:"sourceEtag"`
+	SourceSize    int64            `json:"sourceSize"`
+	DestProfile   string           `json:"destProfile"`
+	DestBucket    string           `json:"destBucket"`
+	DestKey       string           `json:"destKey"`
+	UploadID      string           `json:"uploadId"`
+	PartSize      int64            `json:"partSize"`
+	Parts         []oss.UploadPart `json:"parts"`
+}
+
+func (s *OSSService) streamCopyCheckpointPath(update TransferUpdate) string {
+	hash := listingCacheHash(strings.Join([]string{
+		update.SourceProfileName, update.SourceBucket, update.SourceKey,
+		update.ProfileName, update.Bucket, update.Key,
+	}, "\x1f"))
+	return filepath.Join(normalizeWorkDirPath(s.configDir, s.defaultConfigDir), streamCopyDirName, hash+".json")
+}
+
+func loadStreamCopyCheckpoint(filePath string) (streamCopyCheckpoint, bool) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return streamCopyCheckpoint{}, false
+	}
+	var checkpoint streamCopyCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil || checkpoint.SchemaVersion != streamCopySchemaVersion {
+		return streamCopyCheckpoint{}, false
+	}
+	return checkpoint, true
+}
+
+func saveStreamCopyCheckpoint(filePath string, checkpoint streamCopyCheckpoint) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(checkpoint, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, 0o600)
+}
+
+// streamCopyPartSize keeps large objects within the part count limit.
+func streamCopyPartSize(size int64) int64 {
+	partSize := int64(streamCopyMinPartSize)
+	if minimum := (size + streamCopyMaxParts - 1) / streamCopyMaxParts; minimum > partSize {
+		partSize = minimum
+	}
+	return partSize
+}
+
+// rememberStreamCopySource keeps the source config of a queued copy under its transfer
+// ID, so copies from connections that are not saved profiles, which all share the
+// anonymous profile name, each run against their own source.
+func (s *OSSService) rememberStreamCopySource(transferID string, config OSSConfig) {
+	s.streamCopyMu.Lock()
+	defer s.streamCopyMu.Unlock()
+	if s.streamCopySources == nil {
+		s.streamCopySources = make(map[string]OSSConfig)
+	}
+	s.streamCopySources[transferID] = config
+}
+
+// carryStreamCopySource hands the source config of a copy to the transfer resuming it.
+func (s *OSSService) carryStreamCopySource(fromID string, toID string) {
+	s.streamCopyMu.Lock()
+	defer s.streamCopyMu.Unlock()
+	if config, ok := s.streamCopySources[fromID]; ok && fromID != toID {
+		s.streamCopySources[toID] = config
+		delete(s.streamCopySources, fromID)
+	}
+}
+
+func (s *OSSService) forgetStreamCopySource(transferID string) {
+	s.streamCopyMu.Lock()
+	delete(s.streamCopySources, transferID)
+	s.streamCopyMu.Unlock()
+}
+
+func (s *OSSService) streamCopySourceConfig(update *TransferUpdate) (OSSConfig, error) {
+	s.streamCopyMu.Lock()
+	config, ok := s.streamCopySources[update.ID]
+	s.streamCopyMu.Unlock()
+	if ok {
+		return config, nil
+	}
+	if update.SourceProfileName == transferProfileAnonymous {
+		return OSSConfig{}, errors.New("source connection is not available; reconnect and copy again")
+	}
+	profile, err := s.GetProfile(update.SourceProfileName)
+	if err != nil {
+		return OSSConfig{}, fmt.Errorf("source profile is not available: %w", err)
+	}
+	return profile.Config, nil
+}
+
+// streamCopyDestConfig returns the config of the saved profile a copy was queued with.
+func (s *OSSService) streamCopyDestConfig(profileName string) (OSSConfig, error) {
+	profileName = normalizeTransferProfileName(profileName)
+	if profileName == transferProfileAnonymous {
+		return OSSConfig{}, errors.New("destination connection is not available; reconnect and copy again")
+	}
+	profile, err := s.GetProfile(profileName)
+	if err != nil {
+		return OSSConfig{}, fmt.Errorf("destination profile is not available: %w", err)
+	}
+	return profile.Config, nil
+}
+
+// streamCopyProgress counts bytes as they are uploaded and reports them through the
+// transfer update at most every streamCopyEmitInterval.
+type streamCopyProgress struct {
+	s        *OSSService
+	mu       sync.Mutex
+	update   *TransferUpdate
+	onUpdate func(TransferUpdate)
+	lastEmit time.Time
+}
+
+func (p *streamCopyProgress) add(n int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.update.DoneBytes += n
+	now := time.Now()
+	if now.Sub(p.lastEmit) < streamCopyEmitInterval {
+		return
+	}
+	p.lastEmit = now
+	elapsed := now.Sub(time.UnixMilli(p.update.StartedAtMs)).Seconds()
+	if elapsed > 0 && p.update.DoneBytes > 0 {
+		p.update.SpeedBytesPerSec = float64(p.update.DoneBytes) / elapsed
+	}
+	if p.update.SpeedBytesPerSec > 0 && p.update.TotalBytes > p.update.DoneBytes {
+		p.update.EtaSeconds = int64(float64(p.update.TotalBytes-p.update.DoneBytes) / p.update.SpeedBytesPerSec)
+	} else {
+		p.update.EtaSeconds = 0
+	}
+	p.update.UpdatedAtMs = now.UnixMilli()
+	p.s.emitTransfer(*p.update, p.onUpdate)
+}
+
+type streamCopyReader struct {
+	reader   io.Reader
+	progress *streamCopyProgress
+}
+
+func (r *streamCopyReader) Read(buf []byte) (int, error) {
+	n, err := r.reader.Read(buf)
+	if n > 0 {
+		r.progress.add(int64(n))
+	}
+	return n, err
+}
+
+// runStreamCopy copies update.SourceKey from the source profile to update.Key with the
+// destination config, then checks the result against the source's CRC-64.
+func (s *OSSService) runStreamCopy(config OSSConfig, update *TransferUpdate, onUpdate func(TransferUpdate)) error {
+	sourceConfig, err := s.streamCopySourceConfig(update)
+	if err != nil {
+		return err
+	}
+	srcBkt, err := s.openBucket(sourceConfig, update.SourceBucket)
+	if err != nil {
+		return fmt.Errorf("source: %w", err)
+	}
+	destBkt, err := s.openBucket(config, update.Bucket)
+	if err != nil {
+		return fmt.Errorf("destination: %w", err)
+	}
+
+	header, err := srcBkt.GetObjectDetailedMeta(update.SourceKey)
+	if err != nil {
+		return fmt.Errorf("failed to read source metadata: %w", err)
+	}
+	size, _ := strconv.ParseInt(header.Get(oss.HTTPHeaderContentLength), 10, 64)
+	etag := objectETag(header)
+	update.TotalBytes = size
+	update.DoneBytes = 0
+
+	options := objectHeaderOptions(header)
+	if storageClass := header.Get(oss.HTTPHeaderOssStorageClass); storageClass != "" {
+		options = append(options, oss.ObjectStorageClass(oss.StorageClassType(storageClass)))
+	}
+	tagging, hasTags, err := objectTaggingOption(srcBkt, update.SourceKey)
+	if err != nil {
+		return fmt.Errorf("failed to read source tags: %w", err)
+	}
+	if hasTags {
+		options = append(options, tagging)
+	}
+
+	progress := &streamCopyProgress{s: s, update: update, onUpdate: onUpdate}
+	ifMatch := oss.IfMatch("\"" + etag + "\"")
+	if size <= streamCopyMinPartSize {
+		body, err := srcBkt.GetObject(update.SourceKey, ifMatch)
+		if err != nil {
+			return fmt.Errorf("read failed: %w", err)
+		}
+		err = destBkt.PutObject(update.Key, &streamCopyReader{reader: body, progress: progress}, options...)
+		body.Close()
+		if err != nil {
+			return fmt.Errorf("upload failed: %w", err)
+		}
+	} else if err := s.streamCopyMultipart(srcBkt, destBkt, update, etag, size, options, progress); err != nil {
+		return err
+	}
+
+	destHeader, err := destBkt.GetObjectDetailedMeta(update.Key)
+	if err != nil {
+		return fmt.Errorf("verification failed: %w", err)
+	}
+	if destSize, _ := strconv.ParseInt(destHeader.Get(oss.HTTPHeaderContentLength), 10, 64); destSize != size {
+		return fmt.Errorf("verification failed: copied %d of %d bytes", destSize, size)
+	}
+	sourceCRC, destCRC := header.Get(oss.HTTPHeaderOssCRC64), destHeader.Get(oss.HTTPHeaderOssCRC64)
+	if sourceCRC != "" && destCRC != "" && sourceCRC != destCRC {
+		return errors.New("verification failed: CRC-64 of the copy does not match the source")
+	}
+	s.forgetStreamCopySource(update.ID)
+	return nil
+}
+
+func (s *OSSService) streamCopyMultipart(srcBkt *oss.Bucket, destBkt *oss.Bucket, update *TransferUpdate, etag string, size int64, options []oss.Option, progress *streamCopyProgress) error {
+	checkpointPath := s.streamCopyCheckpointPath(*update)
+	checkpoint, resumed := loadStreamCopyCheckpoint(checkpointPath)
+	if resumed && (checkpoint.SourceETag != etag || checkpoint.SourceSize != size || checkpoint.PartSize <= 0) {
+		_ = destBkt.AbortMultipartUpload(oss.InitiateMultipartUploadResult{Bucket: destBkt.BucketName, Key: update.Key, UploadID: checkpoint.UploadID})
+		resumed = false
+	}
+	imur := oss.InitiateMultipartUploadResult{Bucket: destBkt.BucketName, Key: update.Key, UploadID: checkpoint.UploadID}
+
+	// Only parts OSS still lists count as done; the checkpoint may be ahead of a part that
+	// failed to record or behind one that finished after the last save.
+	done := make(map[int]oss.UploadPart)
+	if resumed {
+		marker := 0
+		for {
+			result, err := destBkt.ListUploadedParts(imur, oss.MaxParts(1000), oss.PartNumberMarker(marker))
+			if err != nil {
+				resumed = false
+				done = make(map[int]oss.UploadPart)
+				break
+			}
+			for _, part := range result.UploadedParts {
+				done[part.PartNumber] = oss.UploadPart{PartNumber: part.PartNumber, ETag: part.ETag}
+			}
+			next, _ := strconv.Atoi(result.NextPartNumberMarker)
+			if !result.IsTruncated || next <= marker {
+				break
+			}
+			marker = next
+		}
+	}
+	if !resumed {
+		var err error
+		imur, err = destBkt.InitiateMultipartUpload(update.Key, options...)
+		if err != nil {
+			return fmt.Errorf("upload failed: %w", err)
+		}
+		checkpoint = streamCopyCheckpoint{
+			SchemaVersion: streamCopySchemaVersion,
+			SourceProfile: update.SourceProfileName,
+			SourceBucket:  update.SourceBucket,
+			SourceKey:     update.SourceKey,
+			SourceETag:    etag,
+			SourceSize:    size,
+			DestProfile:   update.ProfileName,
+			DestBucket:    update.Bucket,
+			DestKey:       update.Key,
+			UploadID:      imur.UploadID,
+			PartSize:      streamCopyPartSize(size),
+		}
+	}
+
+	type partRange struct {
+		number int
+		start  int64
+		size   int64
+	}
+	pending := make([]partRange, 0, size/checkpoint.PartSize+1)
+	checkpoint.Parts = checkpoint.Parts[:0]
+	for start := int64(0); start < size; start += checkpoint.PartSize {
+		r := partRange{number: len(pending) + len(checkpoint.Parts) + 1, start: start, size: checkpoint.PartSize}
+		if start+r.size > size {
+			r.size = size - start
+		}
+		if part, ok := done[r.number]; ok {
+			checkpoint.Parts = append(checkpoint.Parts, part)
+			progress.add(r.size)
+			continue
+		}
+		pending = append(pending, r)
+	}
+	if err := saveStreamCopyCheckpoint(checkpointPath, checkpoint); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+
+	jobs := make(chan partRange)
+	var mu sync.Mutex
+	var firstErr error
+	var wg sync.WaitGroup
+	for i := 0; i < streamCopyRoutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range jobs {
+				part, err := func() (oss.UploadPart, error) {
+					body, err := srcBkt.GetObject(update.SourceKey, oss.Range(r.start, r.start+r.size-1), oss.IfMatch("\""+etag+"\""))
+					if err != nil {
+						return oss.UploadPart{}, fmt.Errorf("read failed: %w", err)
+					}
+					defer body.Close()
+					part, err := destBkt.UploadPart(imur, &streamCopyReader{reader: body, progress: progress}, r.size, r.number)
+					if err != nil {
+						return oss.UploadPart{}, fmt.Errorf("upload failed: %w", err)
+					}
+					return part, nil
+				}()
+				mu.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = err
+					}
+				} else {
+					checkpoint.Parts = append(checkpoint.Parts, part)
+					_ = saveStreamCopyCheckpoint(checkpointPath, checkpoint)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, r := range pending {
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+		if failed {
+			break
+		}
+		jobs <- r
+	}
+	close(jobs)
+	wg.Wait()
+
+	// A failed copy keeps its upload and checkpoint so running it again resumes.
+	if firstErr != nil {
+		return firstErr
+	}
+	sort.Slice(checkpoint.Parts, func(i, j int) bool { return checkpoint.Parts[i].PartNumber < checkpoint.Parts[j].PartNumber })
+	if _, err := destBkt.CompleteMultipartUpload(imur, checkpoint.Parts); err != nil {
+		return fmt.Errorf("upload failed: %w", err)
+	}
+	_ = os.Remove(checkpointPath)
+	return nil
+}
+
+// parseCopySourcePath splits an oss:// source into bucket and key. The key part may be an
+// encoded raw key, as listings send for keys that are not plain text.
+func parseCopySourcePath(sourcePath string) (string, string, bool) {
+	trimmed := strings.TrimLeft(strings.TrimPrefix(strings.TrimSpace(sourcePath), "oss://"), "/")
+	if bucketName, rest, found := strings.Cut(trimmed, "/"); found {
+		if key, ok := decodeObjectKeyParam(rest); ok {
+			return normalizeTransferBucket(bucketName), key, bucketName != ""
+		}
+	}
+	bucketName, key, ok := parseDefaultPathLocation(sourcePath)
+	if !ok {
+		return "", "", false
+	}
+	// parseDefaultPathLocation treats the location as a folder; keep files as files.
+	if !strings.HasSuffix(strings.TrimSpace(sourcePath), "/") {
+		key = strings.TrimSuffix(key, "/")
+	}
+	return bucketName, normalizeObjectKey(key), true
+}
+
+// EnqueueCrossProfileCopy copies objects and folders, given as oss:// paths readable with
+// sourceConfig, into destPrefix of destBucket using destConfig. Each file is a "copy"
+// transfer; a folder becomes a group of them.
+func (s *OSSService) EnqueueCrossProfileCopy(sourceConfig OSSConfig, sourcePaths []string, destConfig OSSConfig, destBucket string, destPrefix string) ([]string, error) {
+	destBucket = normalizeTransferBucket(destBucket)
+	if destBucket == "" {
+		return nil, errors.New("destination bucket is empty")
+	}
+	destPrefix = normalizeObjectPrefix(destPrefix)
+	sourceProfile := s.resolveTransferProfileName(sourceConfig)
+
+	ids := make([]string, 0, len(sourcePaths))
+	for _, sourcePath := range sourcePaths {
+		bucketName, key, ok := parseCopySourcePath(sourcePath)
+		if !ok || key == "" {
+			return ids, fmt.Errorf("invalid source: %s", sourcePath)
+		}
+		name := path.Base(strings.TrimSuffix(key, "/"))
+
+		if !strings.HasSuffix(key, "/") {
+			update := TransferUpdate{
+				ID:                s.newTransferID(),
+				Type:              TransferTypeCopy,
+				Status:            TransferStatusQueued,
+				Name:              name,
+				Bucket:            destBucket,
+				Key:               destPrefix + name,
+				SourceProfileName: sourceProfile,
+				SourceBucket:      bucketName,
+				SourceKey:         key,
+				UpdatedAtMs:       time.Now().UnixMilli(),
+			}
+			s.rememberStreamCopySource(update.ID, sourceConfig)
+			s.enqueueTransfer(destConfig, update, nil)
+			ids = append(ids, update.ID)
+			continue
+		}
+
+		srcBkt, err := s.openBucket(sourceConfig, bucketName)
+		if err != nil {
+			return ids, err
+		}
+		destFolder := destPrefix + name + "/"
+		children := make([]TransferUpdate, 0, 32)
+		totalBytes := int64(0)
+		err = walkObjectsUnderPrefix(srcBkt, key, func(object oss.ObjectProperties) error {
+			relative := strings.TrimPrefix(object.Key, key)
+			if relative == "" || strings.HasSuffix(relative, "/") {
+				return nil
+			}
+			children = append(children, TransferUpdate{
+				ID:                s.newTransferID(),
+				Type:              TransferTypeCopy,
+				Status:            TransferStatusQueued,
+				Name:              path.Join(name, relative),
+				Bucket:            destBucket,
+				Key:               destFolder + relative,
+				SourceProfileName: sourceProfile,
+				SourceBucket:      bucketName,
+				SourceKey:         object.Key,
+				TotalBytes:        object.Size,
+				UpdatedAtMs:       time.Now().UnixMilli(),
+			})
+			totalBytes += object.Size
+			return nil
+		})
+		if err != nil {
+			return ids, fmt.Errorf("failed to list %s: %w", sourcePath, err)
+		}
+		if len(children) == 0 {
+			return ids, fmt.Errorf("folder has no files to copy: %s", sourcePath)
+		}
+		for _, child := range children {
+			s.rememberStreamCopySource(child.ID, sourceConfig)
+		}
+		group := TransferUpdate{
+			ID:                s.newTransferID(),
+			Type:              TransferTypeCopy,
+			Status:            TransferStatusQueued,
+			Name:              name,
+			Bucket:            destBucket,
+			Key:               destFolder,
+			SourceProfileName: sourceProfile,
+			SourceBucket:      bucketName,
+			SourceKey:         key,
+			TotalBytes:        totalBytes,
+			FileCount:         len(children),
+			UpdatedAtMs:       time.Now().UnixMilli(),
+			IsGroup:           true,
+		}
+		if err := s.enqueueTransferGroup(destConfig, group, children); err != nil {
+			return ids, err
+		}
+		ids = append(ids, group.ID)
+	}
+	return ids, nil
+}
+
+// ResumeCopyTransfer runs a failed copy again, continuing from the parts it already
+// uploaded. For a group, the children that did not succeed are run as a new group. The
+// destination is the saved profile the copy was queued with, whatever is open now.
+func (s *OSSService) ResumeCopyTransfer(transferID string) (string, error) {
+	transferID = strings.TrimSpace(transferID)
+	history, err := s.GetTransferHistory()
+	if err != nil {
+		return "", err
+	}
+	var target *TransferUpdate
+	children := make([]TransferUpdate, 0)
+	for i := range history {
+		item := history[i]
+		if item.ID == transferID {
+			target = &history[i]
+		} else if item.ParentID == transferID && item.Status != TransferStatusSuccess {
+			children = append(children, item)
+		}
+	}
+	if target == nil {
+		return "", errors.New("transfer not found")
+	}
+	if target.Type != TransferTypeCopy {
+		return "", errors.New("only copies can be resumed")
+	}
+	if !isTransferFinalStatus(target.Status) {
+		return "", errors.New("transfer is still running")
+	}
+	destConfig, err := s.streamCopyDestConfig(target.ProfileName)
+	if err != nil {
+		return "", err
+	}
+
+	reset := func(item TransferUpdate) TransferUpdate {
+		item.Status = TransferStatusQueued
+		item.Message = ""
+		item.DoneBytes = 0
+		item.SpeedBytesPerSec = 0
+		item.EtaSeconds = 0
+		item.StartedAtMs = 0
+		item.FinishedAtMs = 0
+		item.UpdatedAtMs = time.Now().UnixMilli()
+		return item
+	}
+	if !target.IsGroup {
+		if target.Status == TransferStatusSuccess {
+			return "", errors.New("transfer already succeeded")
+		}
+		s.enqueueTransfer(destConfig, reset(*target), nil)
+		return target.ID, nil
+	}
+	if len(children) == 0 {
+		return "", errors.New("every file of the group already succeeded")
+	}
+	group := reset(*target)
+	group.ID = s.newTransferID()
+	group.TotalBytes = 0
+	for i := range children {
+		children[i] = reset(children[i])
+		previousID := children[i].ID
+		children[i].ID = s.newTransferID()
+		s.carryStreamCopySource(previousID, children[i].ID)
+		group.TotalBytes += children[i].TotalBytes
+	}
+	if err := s.enqueueTransferGroup(destConfig, group, children); err != nil {
+		return "", err
+	}
+	return group.ID, nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+const (
+	ossObjectTypeHeader   = "X-Oss-Object-Type"
+	ossObjectTypeSymlink  = "Symlink"
+	maxSymlinkResolveHops = 8
+	symlinkLookupWorkers  = 8
+)
+
+func readSymlinkTarget(bkt *oss.Bucket, key string) (string, error) {
+	header, err := bkt.GetSymlink(key)
+	if err != nil {
+		return "", err
+	}
+	return header.Get(oss.HTTPHeaderOssSymlinkTarget), nil
+}
+
+// fillSymlinkTargets looks up the target of every symlink in items. Lookups that fail leave
+// the target empty; the item is still marked as a symlink.
+func fillSymlinkTargets(bkt *oss.Bucket, prefix string, items []ObjectInfo) {
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < symlinkLookupWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range indexes {
+				if target, err := readSymlinkTarget(bkt, prefix+items[idx].Name); err == nil {
+					items[idx].SymlinkTarget = target
+				}
+			}
+		}()
+	}
+	for i := range items {
+		if items[i].IsSymlink {
+			indexes <- i
+		}
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// CreateSymlink creates (or repoints) linkKey so that it refers to targetKey, like
+// ossutil create-symlink. The target does not have to exist yet.
+func (s *OSSService) CreateSymlink(config OSSConfig, bucketName string, linkKey string, targetKey string) error {
+	linkKey = normalizeObjectKey(linkKey)
+	targetKey = normalizeObjectKey(targetKey)
+	if linkKey == "" || targetKey == "" {
+		return errors.New("link key and target key are required")
+	}
+	if strings.HasSuffix(linkKey, "/") || strings.HasSuffix(targetKey, "/") {
+		return errors.New("symlinks can only point from a file to a file")
+	}
+	if linkKey == targetKey {
+		return errors.New("a symlink cannot point to itself")
+	}
+
+	bkt, err := s.openBucket(config, bucketName)
+	if err != nil {
+		return err
+	}
+	if err := bkt.PutSymlink(linkKey, targetKey); err != nil {
+		return fmt.Errorf("failed to create symlink: %w", err)
+	}
+	s.invalidateListingCacheForConfig(config, bkt.BucketName, linkKey)
+	return nil
+}
+
+// ResolveSymlink follows linkKey (and any symlinks it points to) and returns the final
+// object, so opening or previewing a link can show where it leads.
+func (s *OSSService) ResolveSymlink(config OSSConfig, bucketName string, linkKey string) (ObjectInfo, error) {
+	key := normalizeObjectKey(linkKey)
+	if key == "" {
+		return ObjectInfo{}, errors.New("link key is empty")
+	}
+	bkt, err := s.openBucket(config, bucketName)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	seen := map[string]struct{}{}
+	for hop := 0; hop <= maxSymlinkResolveHops; hop++ {
+		if _, ok := seen[key]; ok {
+			return ObjectInfo{}, fmt.Errorf("symlink loop at %s", key)
+		}
+		seen[key] = struct{}{}
+
+		header, err := bkt.GetObjectDetailedMeta(key)
+		if err != nil {
+			// HEAD of a symlink whose target is gone fails; report the dangling target instead.
+			if target, linkErr := readSymlinkTarget(bkt, key); linkErr == nil && target != "" {
+				return ObjectInfo{}, fmt.Errorf("symlink target %s does not exist", target)
+			}
+			return ObjectInfo{}, fmt.Errorf("failed to read object %s: %w", key, err)
+		}
+		if header.Get(ossObjectTypeHeader) != ossObjectTypeSymlink {
+			return objectInfoFromHeader(bkt.BucketName, key, header), nil
+		}
+
+		target, err := readSymlinkTarget(bkt, key)
+		if err != nil {
+			return ObjectInfo{}, fmt.Errorf("failed to read symlink %s: %w", key, err)
+		}
+		key = normalizeObjectKey(target)
+	}
+	return ObjectInfo{}, fmt.Errorf("too many symlink levels starting at %s", linkKey)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Background tasks are long-running, cancellable operations (search, folder scans, diffs)
+// that stream their results through events instead of returning them from the call.
+
+func (s *OSSService) startTask(kind string) (string, context.Context) {
+	id := fmt.Sprintf("%s-%d-%d", kind, time.Now().UnixMilli(), atomic.AddUint64(&s.transferSeq, 1))
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s.taskMu.Lock()
+	if s.tasks == nil {
+		s.tasks = make(map[string]context.CancelFunc)
+	}
+	s.tasks[id] = cancel
+	s.taskMu.Unlock()
+	return id, ctx
+}
+
+func (s *OSSService) finishTask(id string) {
+	s.taskMu.Lock()
+	cancel, ok := s.tasks[id]
+	delete(s.tasks, id)
+	s.taskMu.Unlock()
+	if ok {
+		cancel()
+	}
+}
+
+// CancelTask stops a running background task. Cancelling a task that already finished is
+// not an error.
+func (s *OSSService) CancelTask(taskID string) error {
+	taskID = strings.TrimSpace(taskID)
+	if taskID == "" {
+		return errors.New("task id is empty")
+	}
+	s.finishTask(taskID)
+	return nil
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// Text objects are decoded to UTF-8 for preview and editing and encoded back on save. The
+// encoding comes from a byte order mark when there is one and is guessed otherwise.
+
+const (
+	textEncodingUTF8        = "UTF-8"
+	textEncodingUTF16LE     = "UTF-16LE"
+	textEncodingUTF16BE     = "UTF-16BE"
+	textEncodingGBK         = "GBK"
+	textEncodingGB18030     = "GB18030"
+	textEncodingShiftJIS    = "Shift_JIS"
+	textEncodingBig5        = "Big5"
+	textEncodingEUCKR       = "EUC-KR"
+	textEncodingWindows1252 = "Windows-1252"
+
+	// Only the start of the object is looked at when guessing.
+	textEncodingSniffLength = 64 * 1024
+)
+
+var textEncodings = map[string]encoding.Encoding{
+	textEncodingUTF8:        unicode.UTF8,
+	textEncodingUTF16LE:     unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM),
+	textEncodingUTF16BE:     unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM),
+	textEncodingGBK:         simplifiedchinese.GBK,
+	textEncodingGB18030:     simplifiedchinese.GB18030,
+	textEncodingShiftJIS:    japanese.ShiftJIS,
+	textEncodingBig5:        traditionalchinese.Big5,
+	textEncodingEUCKR:       korean.EUCKR,
+	textEncodingWindows1252: charmap.Windows1252,
+}
+
+var textEncodingBOMs = map[string][]byte{
+	textEncodingUTF8:    {0xef, 0xbb, 0xbf},
+	textEncodingUTF16LE: {0xff, 0xfe},
+	textEncodingUTF16BE: {0xfe, 0xff},
+}
+
+// ListTextEncodings returns the encodings text objects can be opened and saved in.
+func (s *OSSService) ListTextEncodings() []string {
+	return []string{
+		textEncodingUTF8,
+		textEncodingUTF16LE,
+		textEncodingUTF16BE,
+		textEncodingGBK,
+		textEncodingGB18030,
+		textEncodingShiftJIS,
+		textEncodingBig5,
+		textEncodingEUCKR,
+		textEncodingWindows1252,
+	}
+}
+
+// normalizeTextEncoding maps a user-supplied encoding name to one of textEncodings; an empty
+// name stays empty and means auto-detection.
+func normalizeTextEncoding(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil
+	}
+	for known := range textEncodings {
+		if strings.EqualFold(known, name) {
+			return known, nil
+		}
+	}
+	switch strings.ToLower(strings.NewReplacer("-", "", "_", "").Replace(name)) {
+	case "utf8":
+		return textEncodingUTF8, nil
+	case "gb2312", "cp936":
+		return textEncodingGBK, nil
+	case "sjis", "shiftjis", "cp932":
+		return textEncodingShiftJIS, nil
+	case "latin1", "iso88591", "cp1252":
+		return textEncodingWindows1252, nil
+	}
+	return "", fmt.Errorf("unsupported text encoding: %s", name)
+}
+
+// bomEncoding returns the encoding announced by a byte order mark at the start of data.
+func bomEncoding(data []byte) string {
+	for _, name := range []string{textEncodingUTF8, textEncodingUTF16LE, textEncodingUTF16BE} {
+		if bytes.HasPrefix(data, textEncodingBOMs[name]) {
+			return name
+		}
+	}
+	return ""
+}
+
+// validUTF8Prefix reports whether data is UTF-8, allowing a rune cut off at the end when the
+// data is only the start of the object.
+func validUTF8Prefix(data []byte, truncated bool) bool {
+	if utf8.Valid(data) {
+		return true
+	}
+	if !truncated {
+		return false
+	}
+	for cut := 1; cut <= 3 && cut < len(data); cut++ {
+		if utf8.Valid(data[:len(data)-cut]) {
+			return true
+		}
+	}
+	return false
+}
+
+// guessUTF16 recognizes BOM-less UTF-16 by its zero bytes, which text in a Latin script has
+// on every other position.
+func guessUTF16(data []byte) string {
+	if len(data) < 4 {
+		return ""
+	}
+	var even, odd int
+	for i, b := range data {
+		if b != 0 {
+			continue
+		}
+		if i%2 == 0 {
+			even++
+		} else {
+			odd++
+		}
+	}
+	half := len(data) / 2
+	switch {
+	case odd > half*3/10 && even < half/20:
+		return textEncodingUTF16LE
+	case even > half*3/10 && odd < half/20:
+		return textEncodingUTF16BE
+	}
+	return ""
+}
+
+// Frequent characters of the languages the legacy encodings are used for. Text decoded with
+// the wrong code page still yields valid characters, but rarely the common ones.
+var textEncodingCommonRunes = map[string]string{
+	textEncodingGBK:      "的一是不了人我在有他这为之大来以个中上们到说国和地也子时道出而要于就下得可你年生自会那后能对着事其里所去行过家十用发天如然作方成者多都三小同么经法当起与好看学进种将还分此心前面又定见只主没公从名数据号码期间金额",
+	textEncodingBig5:     "的一是不了人我在有他這為之大來以個中上們到說國和地也子時道出而要於就下得可你年生自會那後能對著事其裡所去行過家十用發天如然作方成者多都三小同麼經法當起與好看學進種將還分此心前面又定見只主沒公從名數據號碼期間金額",
+	textEncodingShiftJIS: "のにはをたがでてとしれさいるかなもすあうこっまよりらんどけくおだ。、ーアイウエオカキクケコサシスセソタチツテトナニヌネノハヒフヘホマミムメモヤユヨラリルレロワン",
+	textEncodingEUCKR:    "이의는에가을를다고한하지서기로도있사게어수자시리정대해아니습그보인일나구부내것들과요전상면제만주",
+}
+
+// scoreDecoded rates how plausible text decoded with a legacy encoding is: frequent
+// characters of its language count for it, replacement and private-use characters against.
+func scoreDecoded(name string, text string) int {
+	common := textEncodingCommonRunes[name]
+	score := 0
+	for _, r := range text {
+		switch {
+		case r == utf8.RuneError:
+			score -= 20
+		case r >= 0xe000 && r <= 0xf8ff:
+			score -= 10
+		case r >= 0x80 && strings.ContainsRune(common, r):
+			score += 4
+		}
+	}
+	return score
+}
+
+// detectTextEncoding guesses the encoding of data, which is the start of an object when
+// truncated is set.
+func detectTextEncoding(data []byte, truncated bool) string {
+	if name := bomEncoding(data); name != "" {
+		return name
+	}
+	sample := data
+	if len(sample) > textEncodingSniffLength {
+		sample, truncated = sample[:textEncodingSniffLength], true
+	}
+	if name := guessUTF16(sample); name != "" {
+		return name
+	}
+	if validUTF8Prefix(sample, truncated) {
+		return textEncodingUTF8
+	}
+
+	best, bestScore := textEncodingWindows1252, 0
+	for _, name := range []string{textEncodingGBK, textEncodingShiftJIS, textEncodingBig5, textEncodingEUCKR} {
+		decoded, err := textEncodings[name].NewDecoder().Bytes(sample)
+		if err != nil {
+			continue
+		}
+		text := string(decoded)
+		if truncated {
+			text = strings.TrimSuffix(text, string(utf8.RuneError))
+		}
+		if score := scoreDecoded(name, text); score > bestScore {
+			best, bestScore = name, score
+		}
+	}
+	if bestScore == 0 {
+		// Nothing recognizable; most non-UTF-8 text here comes from Chinese Windows tools.
+		if decoded, err := simplifiedchinese.GBK.NewDecoder().Bytes(sample); err == nil && !bytes.ContainsRune(decoded, utf8.RuneError) {
+			best = textEncodingGBK
+		}
+	}
+	if best == textEncodingGBK {
+		// GB18030 extends GBK; prefer the narrower name when GBK covers the text.
+		if decoded, err := simplifiedchinese.GBK.NewDecoder().Bytes(sample); err == nil && bytes.ContainsRune(decoded, utf8.RuneError) {
+			if decoded18030, err := simplifiedchinese.GB18030.NewDecoder().Bytes(sample); err == nil && !bytes.ContainsRune(decoded18030, utf8.RuneError) {
+				return textEncodingGB18030
+			}
+		}
+	}
+	return best
+}
+
+// decodeObjectText converts data to UTF-8. name selects the encoding; empty means detect it.
+// It returns the encoding used and whether data started with its byte order mark.
+func decodeObjectText(data []byte, name string, truncated bool) (string, string, bool, error) {
+	name, err := normalizeTextEncoding(name)
+	if err != nil {
+		return "", "", false, err
+	}
+	if name == "" {
+		name = detectTextEncoding(data, truncated)
+	}
+	hasBOM := false
+	if bom := textEncodingBOMs[name]; bom != nil && bytes.HasPrefix(data, bom) {
+		data = data[len(bom):]
+		hasBOM = true
+	}
+	if name == textEncodingUTF8 {
+		return strings.ToValidUTF8(string(data), "\uFFFD"), name, hasBOM, nil
+	}
+	if truncated && (name == textEncodingUTF16LE || name == textEncodingUTF16BE) && len(data)%2 == 1 {
+		data = data[:len(data)-1]
+	}
+	decoded, err := textEncodings[name].NewDecoder().Bytes(data)
+	if err != nil {
+		return "", "", false, fmt.Errorf("failed to decode text as %s: %w", name, err)
+	}
+	return string(decoded), name, hasBOM, nil
+}
+
+// encodeObjectText converts UTF-8 text to name, prefixed with the byte order mark when bom is
+// set. Characters the encoding cannot represent are an error rather than silently replaced.
+func encodeObjectText(text string, name string, bom bool) ([]byte, error) {
+	name, err := normalizeTextEncoding(name)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = textEncodingUTF8
+	}
+	var out []byte
+	if bom {
+		out = append(out, textEncodingBOMs[name]...)
+	}
+	if name == textEncodingUTF8 {
+		return append(out, text...), nil
+	}
+	encoded, err := textEncodings[name].NewEncoder().Bytes([]byte(text))
+	if err != nil {
+		return nil, fmt.Errorf("the text cannot be saved as %s: %w", name, err)
+	}
+	return append(out, encoded...), nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func encodeForTest(t *testing.T, enc encoding.Encoding, text string) []byte {
+	t.Helper()
+	data, err := enc.NewEncoder().Bytes([]byte(text))
+	if err != nil {
+		t.Fatalf("encode %q: %v", text, err)
+	}
+	return data
+}
+
+func TestDetectTextEncoding(t *testing.T) {
+	const (
+		english     = "The quick brown fox jumps over the lazy dog.\n"
+		simplified  = "这是一个中文的测试文件，我们在这里写一些常用的字，看看能不能认出来。\n"
+		traditional = "這是一個中文的測試文件，我們在這裡寫一些常用的字，看看能不能認出來。\n"
+		japaneseTxt = "これは日本語のテストです。ファイルの内容を確認してください。\n"
+	)
+	utf16le := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+	utf16be := unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)
+	utf8Chinese := []byte(simplified)
+	gbk := encodeForTest(t, simplifiedchinese.GBK, simplified)
+
+	tests := []struct {
+		name      string
+		data      []byte
+		truncated bool
+		want      string
+	}{
+		{"utf-8 bom", append([]byte{0xef, 0xbb, 0xbf}, simplified...), false, textEncodingUTF8},
+		{"utf-16le bom", append([]byte{0xff, 0xfe}, encodeForTest(t, utf16le, simplified)...), false, textEncodingUTF16LE},
+		{"utf-16be bom", append([]byte{0xfe, 0xff}, encodeForTest(t, utf16be, simplified)...), false, textEncodingUTF16BE},
+		{"utf-16le without bom", encodeForTest(t, utf16le, english), false, textEncodingUTF16LE},
+		{"utf-16be without bom", encodeForTest(t, utf16be, english), false, textEncodingUTF16BE},
+		{"ascii", []byte(english), false, textEncodingUTF8},
+		{"utf-8", utf8Chinese, false, textEncodingUTF8},
+		{"gbk", gbk, false, textEncodingGBK},
+		{"big5", encodeForTest(t, traditionalchinese.Big5, traditional), false, textEncodingBig5},
+		{"shift_jis", encodeForTest(t, japanese.ShiftJIS, japaneseTxt), false, textEncodingShiftJIS},
+		{"windows-1252", encodeForTest(t, charmap.Windows1252, "café, naïve résumé\n"), false, textEncodingWindows1252},
+		{"utf-8 cut inside a rune", utf8Chinese[:len(utf8Chinese)-3], true, textEncodingUTF8},
+		{"gbk cut inside a character", gbk[:len(gbk)-2], true, textEncodingGBK},
+		{"empty", nil, false, textEncodingUTF8},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := detectTextEncoding(test.data, test.truncated); got != test.want {
+				t.Errorf("detectTextEncoding() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// Text edits are saved through the SDK rather than `ossutil cp`, so the object keeps its
+// headers, user metadata and tags, and a save only goes through when the object still has
+// the ETag it had when the editor opened it.
+
+const objectTextMaxBytes = 5 * 1024 * 1024
+
+// ObjectTextDocument is the text of an object together with the version it was read from.
+type ObjectTextDocument struct {
+	Bucket         string `json:"bucket"`
+	Key            string `json:"key"`
+	Content        string `json:"content"`
+	ETag           string `json:"etag"`
+	VersionID      string `json:"versionId,omitempty"`
+	Size           int64  `json:"size"`
+	LastModifiedMs int64  `json:"lastModifiedMs"`
+	Truncated      bool   `json:"truncated"`
+	Encoding       string `json:"encoding"` // See ListTextEncodings
+	HasBOM         bool   `json:"hasBom"`
+}
+
+type SaveTextOptions struct {
+	// ExpectedETag is the ETag from OpenObjectText. When set, the save fails with an
+	// ObjectConflictError if the object changed or was deleted since.
+	ExpectedETag string `json:"expectedEtag"`
+	// Backup keeps the previous content before overwriting it. Buckets with versioning
+	// enabled already keep it as a version; elsewhere it is copied to BackupKey, by default
+	// "<key>.<timestamp>.bak".
+	Backup    bool   `json:"backup"`
+	BackupKey string `json:"backupKey"`
+	// Encoding and BOM write the text back the way OpenObjectText found it; an empty
+	// encoding saves UTF-8.
+	Encoding string `json:"encoding"`
+	BOM      bool   `json:"bom"`
+}
+
+type SaveTextResult struct {
+	ETag              string `json:"etag"`
+	VersionID         string `json:"versionId,omitempty"`
+	BackupKey         string `json:"backupKey,omitempty"`
+	PreviousVersionID string `json:"previousVersionId,omitempty"`
+}
+
+// ObjectConflictError is returned by SaveObjectText when the object no longer has the
+// expected ETag. It carries the remote version so the editor can show a diff; the error
+// formatter passes it to the frontend as an object with Kind "conflict".
+type ObjectConflictError struct {
+	Kind                 string `json:"kind"`
+	Message              string `json:"message"`
+	Bucket               string `json:"bucket"`
+	Key                  string `json:"key"`
+	ExpectedETag         string `json:"expectedEtag"`
+	RemoteETag           string `json:"remoteEtag,omitempty"`
+	RemoteVersionID      string `json:"remoteVersionId,omitempty"`
+	RemoteSize           int64  `json:"remoteSize"`
+	RemoteLastModifiedMs int64  `json:"remoteLastModifiedMs,omitempty"`
+	RemoteContent        string `json:"remoteContent"`
+	RemoteTruncated      bool   `json:"remoteTruncated"`
+	RemoteDeleted        bool   `json:"remoteDeleted"`
+}
+
+func (e *ObjectConflictError) Error() string {
+	return e.Message
+}
+
+// formatBackendError shapes errors returned to the frontend: conflicts become objects with
+// the remote version, everything else stays a message string.
+func formatBackendError(err error) any {
+	var conflict *ObjectConflictError
+	if errors.As(err, &conflict) {
+		return conflict
+	}
+	return err.Error()
+}
+
+func objectETag(header http.Header) string {
+	return strings.Trim(header.Get(oss.HTTPHeaderEtag), "\"")
+}
+
+func objectLastModifiedMs(header http.Header) int64 {
+	if modified, err := http.ParseTime(header.Get(oss.HTTPHeaderLastModified)); err == nil {
+		return modified.UnixMilli()
+	}
+	return 0
+}
+
+func isObjectNotFound(err error) bool {
+	var serviceErr oss.ServiceError
+	return errors.As(err, &serviceErr) && serviceErr.StatusCode == http.StatusNotFound
+}
+
+func isPreconditionFailed(err error) bool {
+	var serviceErr oss.ServiceError
+	return errors.As(err, &serviceErr) && serviceErr.StatusCode == http.StatusPreconditionFailed
+}
+
+func isObjectAlreadyExists(err error) bool {
+	var serviceErr oss.ServiceError
+	return errors.As(err, &serviceErr) && serviceErr.Code == "FileAlreadyExists"
+}
+
+func clampObjectTextBytes(maxBytes int) int64 {
+	if maxBytes <= 0 {
+		return 256 * 1024
+	}
+	if maxBytes > objectTextMaxBytes {
+		return objectTextMaxBytes
+	}
+	return int64(maxBytes)
+}
+
+// readObjectText reads up to maxBytes of the version of key described by header and decodes
+// it from textEncoding, or from the detected encoding when textEncoding is empty.
+func readObjectText(bkt *oss.Bucket, key string, header http.Header, maxBytes int64, textEncoding string) (ObjectTextDocument, error) {
+	size, _ := strconv.ParseInt(header.Get(oss.HTTPHeaderContentLength), 10, 64)
+	doc := ObjectTextDocument{
+		Bucket:         bkt.BucketName,
+		Key:            objectKeyForClient(key),
+		ETag:           objectETag(header),
+		VersionID:      oss.GetVersionId(header),
+		Size:           size,
+		LastModifiedMs: objectLastModifiedMs(header),
+		Truncated:      size > maxBytes,
+		Encoding:       textEncodingUTF8,
+	}
+	if size == 0 {
+		return doc, nil
+	}
+	end := size
+	if end > maxBytes {
+		end = maxBytes
+	}
+	options := []oss.Option{oss.Range(0, end-1)}
+	if doc.ETag != "" {
+		options = append(options, oss.IfMatch("\""+doc.ETag+"\""))
+	}
+	if doc.VersionID != "" {
+		options = append(options, oss.VersionId(doc.VersionID))
+	}
+	body, err := bkt.GetObject(key, options...)
+	if err != nil {
+		return ObjectTextDocument{}, fmt.Errorf("read failed: %w", err)
+	}
+	defer body.Close()
+	data, err := io.ReadAll(io.LimitReader(body, end))
+	if err != nil {
+		return ObjectTextDocument{}, fmt.Errorf("read failed: %w", err)
+	}
+	doc.Content, doc.Encoding, doc.HasBOM, err = decodeObjectText(data, textEncoding, doc.Truncated)
+	if err != nil {
+		return ObjectTextDocument{}, err
+	}
+	return doc, nil
+}
+
+// OpenObjectText reads up to maxBytes (capped at 5 MB) of an object for editing and returns
+// the ETag to pass back to SaveObjectText. The text is decoded from textEncoding, or from
+// the encoding detected from a byte order mark or the content when it is empty.
+func (s *OSSService) OpenObjectText(config OSSConfig, bucketName string, key string, maxBytes int, textEncoding string) (ObjectTextDocument, error) {
+	bkt, key, err := s.openObjectForRange(config, bucketName, key)
+	if err != nil {
+		return ObjectTextDocument{}, err
+	}
+	header, err := bkt.GetObjectDetailedMeta(key)
+	if err != nil {
+		return ObjectTextDocument{}, fmt.Errorf("failed to read object metadata: %w", err)
+	}
+	return readObjectText(bkt, key, header, clampObjectTextBytes(maxBytes), textEncoding)
+}
+
+func (s *OSSService) objectConflict(bkt *oss.Bucket, key string, expected string) error {
+	conflict := &ObjectConflictError{
+		Kind:         "conflict",
+		Bucket:       bkt.BucketName,
+		Key:          objectKeyForClient(key),
+		ExpectedETag: expected,
+	}
+	header, err := bkt.GetObjectDetailedMeta(key)
+	switch {
+	case isObjectNotFound(err):
+		conflict.RemoteDeleted = true
+		conflict.Message = fmt.Sprintf("%s was deleted since it was opened", key)
+		return conflict
+	case err != nil:
+		return fmt.Errorf("failed to read object metadata: %w", err)
+	}
+	remote, err := readObjectText(bkt, key, header, objectTextMaxBytes, "")
+	if err != nil {
+		return err
+	}
+	conflict.Message = fmt.Sprintf("%s was changed since it was opened", key)
+	conflict.RemoteETag = remote.ETag
+	conflict.RemoteVersionID = remote.VersionID
+	conflict.RemoteSize = remote.Size
+	conflict.RemoteLastModifiedMs = remote.LastModifiedMs
+	conflict.RemoteContent = remote.Content
+	conflict.RemoteTruncated = remote.Truncated
+	return conflict
+}
+
+// SaveObjectText writes content to an object, keeping its headers, user metadata, storage
+// class and tags. With an expected ETag the write is conditional (If-Match) and a changed or
+// deleted object yields an ObjectConflictError instead of being overwritten.
+func (s *OSSService) SaveObjectText(config OSSConfig, bucketName string, key string, content string, options SaveTextOptions) (SaveTextResult, error) {
+	bkt, key, err := s.openObjectForRange(config, bucketName, key)
+	if err != nil {
+		return SaveTextResult{}, err
+	}
+	expected := strings.Trim(strings.TrimSpace(options.ExpectedETag), "\"")
+	data, err := encodeObjectText(content, options.Encoding, options.BOM)
+	if err != nil {
+		return SaveTextResult{}, err
+	}
+
+	header, err := bkt.GetObjectDetailedMeta(key)
+	exists := err == nil
+	if err != nil && !isObjectNotFound(err) {
+		return SaveTextResult{}, fmt.Errorf("failed to read object metadata: %w", err)
+	}
+	if expected != "" && (!exists || objectETag(header) != expected) {
+		return SaveTextResult{}, s.objectConflict(bkt, key, expected)
+	}
+
+	putOptions := []oss.Option{}
+	result := SaveTextResult{}
+	if exists {
+		putOptions = append(putOptions, objectHeaderOptions(header)...)
+		if storageClass := header.Get(oss.HTTPHeaderOssStorageClass); storageClass != "" {
+			putOptions = append(putOptions, oss.ObjectStorageClass(oss.StorageClassType(storageClass)))
+		}
+		tagging, hasTags, err := objectTaggingOption(bkt, key)
+		if err != nil {
+			return SaveTextResult{}, fmt.Errorf("failed to read object tags: %w", err)
+		}
+		if hasTags {
+			putOptions = append(putOptions, tagging)
+		}
+
+		if options.Backup {
+			versioning, err := bkt.Client.GetBucketVersioning(bkt.BucketName)
+			if err != nil {
+				return SaveTextResult{}, fmt.Errorf("failed to read bucket versioning: %w", err)
+			}
+			if versioning.Status == string(oss.VersionEnabled) {
+				result.PreviousVersionID = oss.GetVersionId(header)
+			} else {
+				backupKey := normalizeObjectKey(options.BackupKey)
+				if backupKey == "" {
+					backupKey = fmt.Sprintf("%s.%s.bak", key, time.Now().Format("20060102-150405"))
+				}
+				size, _ := strconv.ParseInt(header.Get(oss.HTTPHeaderContentLength), 10, 64)
+				src := objectCopySource{Bucket: bkt.BucketName, Key: key, Size: size}
+				if err := copyObjectPreservingMetadata(bkt, src, backupKey, oss.CopySourceIfMatch("\""+objectETag(header)+"\"")); err != nil {
+					return SaveTextResult{}, fmt.Errorf("backup failed: %w", err)
+				}
+				s.invalidateListingCacheForConfig(config, bkt.BucketName, backupKey)
+				result.BackupKey = objectKeyForClient(backupKey)
+			}
+		}
+	} else {
+		putOptions = append(putOptions, oss.ForbidOverWrite(true))
+	}
+	if expected != "" {
+		putOptions = append(putOptions, oss.IfMatch("\""+expected+"\""))
+	}
+
+	resp, err := bkt.DoPutObject(&oss.PutObjectRequest{ObjectKey: key, Reader: bytes.NewReader(data)}, putOptions)
+	if err != nil {
+		if isPreconditionFailed(err) || isObjectAlreadyExists(err) {
+			return SaveTextResult{}, s.objectConflict(bkt, key, expected)
+		}
+		return SaveTextResult{}, fmt.Errorf("save failed: %w", err)
+	}
+	resp.Body.Close()
+	s.invalidateListingCacheForConfig(config, bkt.BucketName, key)
+
+	result.ETag = objectETag(resp.Headers)
+	result.VersionID = oss.GetVersionId(resp.Headers)
+	return result, nil
+}
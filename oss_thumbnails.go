@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// Thumbnails are rendered by OSS image processing and cached in <work dir>/thumbnail-cache,
+// one file per object version and size named <hash of profile, bucket and key>_<hash of
+// ETag>_<size>, so an overwritten object never serves its old thumbnail. A cache hit
+// refreshes the file's modification time and the least recently used files are evicted
+// once the cache grows past thumbnailCacheMaxBytes.
+
+const (
+	thumbnailCacheDirName  = "thumbnail-cache"
+	thumbnailCacheMaxBytes = 256 << 20
+	thumbnailDefaultSize   = 160
+	thumbnailMinSize       = 16
+	thumbnailMaxSize       = 1024
+	thumbnailWorkers       = 8
+	thumbnailMaxKeys       = 500
+	thumbnailMaxBytes      = 4 << 20
+)
+
+// ThumbnailRequest names an object and the ETag its listing reported. Without an ETag the
+// current one is read from the object first.
+type ThumbnailRequest struct {
+	Key  string `json:"key"`
+	ETag string `json:"etag"`
+}
+
+type ThumbnailResult struct {
+	Key         string `json:"key"`
+	DataURL     string `json:"dataUrl,omitempty"`
+	LocalPath   string `json:"localPath,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	Cached      bool   `json:"cached"`
+	Error       string `json:"error,omitempty"`
+}
+
+func (s *OSSService) thumbnailCacheRoot() string {
+	return filepath.Join(normalizeWorkDirPath(s.configDir, s.defaultConfigDir), thumbnailCacheDirName)
+}
+
+func thumbnailCacheStem(profileName string, bucket string, key string) string {
+	return listingCacheHash(normalizeTransferProfileName(profileName) + "\x1f" + bucket + "\x1f" + key)[:32]
+}
+
+func (s *OSSService) thumbnailCachePath(profileName string, bucket string, key string, etag string, size int) string {
+	version := listingCacheHash(strings.Trim(etag, "\""))[:16]
+	return filepath.Join(s.thumbnailCacheRoot(), thumbnailCacheStem(profileName, bucket, key)+"_"+version+"_"+strconv.Itoa(size))
+}
+
+func normalizeThumbnailSize(size int) int {
+	switch {
+	case size <= 0:
+		return thumbnailDefaultSize
+	case size < thumbnailMinSize:
+		return thumbnailMinSize
+	case size > thumbnailMaxSize:
+		return thumbnailMaxSize
+	}
+	return size
+}
+
+// GetThumbnails returns thumbnails of at most size pixels on the longer edge for objects,
+// fetched concurrently with x-oss-process=image/resize. Each result carries a data URL and the
+// path of the cached file; objects that cannot be rendered, such as non-images, report an
+// error instead.
+func (s *OSSService) GetThumbnails(config OSSConfig, bucketName string, objects []ThumbnailRequest, size int) ([]ThumbnailResult, error) {
+	if len(objects) > thumbnailMaxKeys {
+		return nil, fmt.Errorf("at most %d thumbnails can be requested at once", thumbnailMaxKeys)
+	}
+	bkt, err := s.openBucket(config, bucketName)
+	if err != nil {
+		return nil, err
+	}
+	size = normalizeThumbnailSize(size)
+	profileName := s.resolveTransferProfileName(config)
+	process := fmt.Sprintf("image/resize,m_lfit,w_%d,h_%d", size, size)
+
+	if err := os.MkdirAll(s.thumbnailCacheRoot(), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create thumbnail cache: %w", err)
+	}
+
+	results := make([]ThumbnailResult, len(objects))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < thumbnailWorkers && w < len(objects); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				key := normalizeObjectKey(objects[i].Key)
+				result := ThumbnailResult{Key: objectKeyForClient(key)}
+				etag := strings.TrimSpace(objects[i].ETag)
+				if etag == "" && key != "" && !strings.HasSuffix(key, "/") {
+					if header, err := bkt.GetObjectDetailedMeta(key); err == nil {
+						etag = objectETag(header)
+					}
+				}
+				path := s.thumbnailCachePath(profileName, bkt.BucketName, key, etag, size)
+
+				data, err := os.ReadFile(path)
+				if err == nil {
+					now := time.Now()
+					_ = os.Chtimes(path, now, now)
+					result.Cached = true
+				} else {
+					data, err = fetchThumbnail(bkt, key, process)
+					if err == nil {
+						err = os.WriteFile(path, data, 0o600)
+					}
+				}
+				if err != nil {
+					result.Error = err.Error()
+				} else {
+					result.ContentType = http.DetectContentType(data)
+					result.DataURL = "data:" + result.ContentType + ";base64," + base64.StdEncoding.EncodeToString(data)
+					result.LocalPath = path
+				}
+				results[i] = result
+			}
+		}()
+	}
+	for i := range objects {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	s.evictThumbnailCache()
+	return results, nil
+}
+
+func fetchThumbnail(bkt *oss.Bucket, key string, process string) ([]byte, error) {
+	if key == "" || strings.HasSuffix(key, "/") {
+		return nil, errors.New("not a file")
+	}
+	body, err := bkt.GetObject(key, oss.Process(process))
+	if err != nil {
+		return nil, fmt.Errorf("thumbnail failed: %w", err)
+	}
+	defer body.Close()
+	data, err := io.ReadAll(io.LimitReader(body, thumbnailMaxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("thumbnail failed: %w", err)
+	}
+	if len(data) > thumbnailMaxBytes {
+		return nil, errors.New("thumbnail is too large")
+	}
+	return data, nil
+}
+
+// evictThumbnailCache removes the least recently used thumbnails until the cache fits.
+func (s *OSSService) evictThumbnailCache() {
+	s.thumbnailCacheMu.Lock()
+	defer s.thumbnailCacheMu.Unlock()
+
+	root := s.thumbnailCacheRoot()
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return
+	}
+	type cachedFile struct {
+		name    string
+		size    int64
+		modTime time.Time
+	}
+	files := make([]cachedFile, 0, len(entries))
+	var total int64
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		files = append(files, cachedFile{name: entry.Name(), size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+	}
+	if total <= thumbnailCacheMaxBytes {
+		return
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	for _, file := range files {
+		if total <= thumbnailCacheMaxBytes {
+			break
+		}
+		if err := os.Remove(filepath.Join(root, file.name)); err == nil {
+			total -= file.size
+		}
+	}
+}
+
+// dropThumbnails removes the cached thumbnails of keys in every size.
+func (s *OSSService) dropThumbnails(profileName string, bucketName string, keys ...string) {
+	root := s.thumbnailCacheRoot()
+	for _, key := range keys {
+		key = normalizeObjectKey(key)
+		if strings.HasSuffix(key, "/") {
+			continue
+		}
+		matches, _ := filepath.Glob(filepath.Join(root, thumbnailCacheStem(profileName, bucketName, key)+"_*"))
+		for _, match := range matches {
+			_ = os.Remove(match)
+		}
+	}
+}
+
+// ClearThumbnailCache removes every cached thumbnail.
+func (s *OSSService) ClearThumbnailCache() error {
+	s.thumbnailCacheMu.Lock()
+	defer s.thumbnailCacheMu.Unlock()
+	if err := os.RemoveAll(s.thumbnailCacheRoot()); err != nil {
+		return fmt.Errorf("failed to clear thumbnail cache: %w", err)
+	}
+	return nil
+}
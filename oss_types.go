@@ -2,10 +2,20 @@ package main
 
 // ObjectInfo represents an OSS object (file or folder)
 type ObjectInfo struct {
-	Name         string `json:"name"`
-	Path         string `json:"path"` // Full path including bucket
-	Size         int64  `json:"size"`
-	Type         string `json:"type"` // "File" or "Folder"
-	LastModified string `json:"lastModified"`
-	StorageClass string `json:"storageClass"`
+	Name          string `json:"name"`
+	Path          string `json:"path"`             // Full path including bucket
+	RawKey        string `json:"rawKey,omitempty"` // Encoded key to pass to APIs when the key is not plain text
+	Size          int64  `json:"size"`
+	Type          string `json:"type"` // "File" or "Folder"
+	LastModified  string `json:"lastModified"`
+	StorageClass  string `json:"storageClass"`
+	ETag          string `json:"etag,omitempty"`
+	RestoreState  string `json:"restoreState,omitempty"` // "archived", "ongoing" or "restored" (archive classes only)
+	RestoreExpiry string `json:"restoreExpiry,omitempty"`
+	IsSymlink     bool   `json:"isSymlink,omitempty"`
+	SymlinkTarget string `json:"symlinkTarget,omitempty"` // Target key for symlinks
+
+	// Folders only, filled from cached GetFolderStats results.
+	ObjectCount      int64 `json:"objectCount,omitempty"`
+	SizeComputedAtMs int64 `json:"sizeComputedAtMs,omitempty"`
 }
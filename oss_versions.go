@@ -0,0 +1,363 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// ObjectVersionInfo is one entry of a versioned listing: a folder, an object version or a
+// delete marker.
+type ObjectVersionInfo struct {
+	Name           string `json:"name"`
+	Path           string `json:"path"`
+	Key            string `json:"key"`
+	RawKey         string `json:"rawKey,omitempty"`
+	VersionID      string `json:"versionId,omitempty"`
+	Type           string `json:"type"` // "File" or "Folder"
+	IsLatest       bool   `json:"isLatest"`
+	IsDeleteMarker bool   `json:"isDeleteMarker"`
+	Size           int64  `json:"size"`
+	LastModified   string `json:"lastModified"`
+	StorageClass   string `json:"storageClass"`
+	ETag           string `json:"etag,omitempty"`
+}
+
+type ObjectVersionListPageResult struct {
+	Items               []ObjectVersionInfo `json:"items"`
+	NextKeyMarker       string              `json:"nextKeyMarker"`
+	NextVersionIdMarker string              `json:"nextVersionIdMarker"`
+	IsTruncated         bool                `json:"isTruncated"`
+}
+
+// ObjectVersionRef identifies a single version (or delete marker) of an object.
+type ObjectVersionRef struct {
+	Key       string `json:"key"`
+	VersionID string `json:"versionId"`
+}
+
+func objectVersionFromProperties(bucketName string, version oss.ObjectVersionProperties) ObjectVersionInfo {
+	return ObjectVersionInfo{
+		Name:         path.Base(version.Key),
+		Path:         buildOssPath(bucketName, version.Key),
+		Key:          version.Key,
+		RawKey:       rawObjectKey(version.Key),
+		VersionID:    version.VersionId,
+		Type:         "File",
+		IsLatest:     version.IsLatest,
+		Size:         version.Size,
+		LastModified: formatObjectLastModified(version.LastModified),
+		StorageClass: version.StorageClass,
+		ETag:         strings.Trim(version.ETag, "\""),
+	}
+}
+
+func objectDeleteMarkerInfo(bucketName string, marker oss.ObjectDeleteMarkerProperties) ObjectVersionInfo {
+	return ObjectVersionInfo{
+		Name:           path.Base(marker.Key),
+		Path:           buildOssPath(bucketName, marker.Key),
+		Key:            marker.Key,
+		RawKey:         rawObjectKey(marker.Key),
+		VersionID:      marker.VersionId,
+		Type:           "File",
+		IsLatest:       marker.IsLatest,
+		IsDeleteMarker: true,
+		LastModified:   formatObjectLastModified(marker.LastModified),
+	}
+}
+
+// objectVersionSortKey keys lastModified for sortObjectVersions. Version IDs are only unique
+// per key: every object written before versioning was enabled has the version ID "null".
+func objectVersionSortKey(key string, versionID string) string {
+	return key + "\x00" + versionID
+}
+
+// sortObjectVersions orders versions by key, then newest first, the order OSS lists them in.
+// Versions and delete markers come back in separate lists and have to be merged.
+func sortObjectVersions(items []ObjectVersionInfo, lastModified map[string]int64) {
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Key != items[j].Key {
+			return items[i].Key < items[j].Key
+		}
+		return lastModified[objectVersionSortKey(items[i].Key, items[i].VersionID)] > lastModified[objectVersionSortKey(items[j].Key, items[j].VersionID)]
+	})
+}
+
+// ListObjectVersionsPage lists one folder level of a versioned bucket, including old
+// versions and delete markers. Paging uses the key and version ID markers of the previous page.
+func (s *OSSService) ListObjectVersionsPage(config OSSConfig, bucketName string, prefix string, keyMarker string, versionIDMarker string, maxKeys int) (ObjectVersionListPageResult, error) {
+	prefix = normalizeObjectPrefix(prefix)
+	if maxKeys <= 0 {
+		maxKeys = 200
+	}
+	if maxKeys > 1000 {
+		maxKeys = 1000
+	}
+
+	bkt, err := s.openBucket(config, bucketName)
+	if err != nil {
+		return ObjectVersionListPageResult{}, err
+	}
+
+	options := []oss.Option{
+		oss.Prefix(prefix),
+		oss.Delimiter("/"),
+		oss.MaxKeys(maxKeys),
+	}
+	if keyMarker = normalizeMarkerParam(keyMarker); keyMarker != "" {
+		options = append(options, oss.KeyMarker(keyMarker))
+		if versionIDMarker = strings.TrimSpace(versionIDMarker); versionIDMarker != "" {
+			options = append(options, oss.VersionIdMarker(versionIDMarker))
+		}
+	}
+
+	lor, err := bkt.ListObjectVersions(options...)
+	if err != nil {
+		return ObjectVersionListPageResult{}, fmt.Errorf("failed to list object versions: %w", err)
+	}
+
+	folders := make([]ObjectVersionInfo, 0, len(lor.CommonPrefixes))
+	for _, commonPrefix := range lor.CommonPrefixes {
+		relative := strings.TrimSuffix(strings.TrimPrefix(commonPrefix, prefix), "/")
+		if relative == "" || strings.Contains(relative, "/") {
+			continue
+		}
+		folders = append(folders, ObjectVersionInfo{
+			Name:   relative,
+			Path:   buildOssPath(bkt.BucketName, commonPrefix),
+			Key:    commonPrefix,
+			RawKey: rawObjectKey(commonPrefix),
+			Type:   "Folder",
+		})
+	}
+	sort.Slice(folders, func(i, j int) bool { return folders[i].Name < folders[j].Name })
+
+	lastModified := make(map[string]int64, len(lor.ObjectVersions)+len(lor.ObjectDeleteMarkers))
+	files := make([]ObjectVersionInfo, 0, len(lor.ObjectVersions)+len(lor.ObjectDeleteMarkers))
+	for _, version := range lor.ObjectVersions {
+		if version.Key == prefix || !strings.HasPrefix(version.Key, prefix) {
+			continue
+		}
+		item := objectVersionFromProperties(bkt.BucketName, version)
+		item.Name = strings.TrimPrefix(version.Key, prefix)
+		lastModified[objectVersionSortKey(item.Key, item.VersionID)] = version.LastModified.UnixNano()
+		files = append(files, item)
+	}
+	for _, marker := range lor.ObjectDeleteMarkers {
+		if marker.Key == prefix || !strings.HasPrefix(marker.Key, prefix) {
+			continue
+		}
+		item := objectDeleteMarkerInfo(bkt.BucketName, marker)
+		item.Name = strings.TrimPrefix(marker.Key, prefix)
+		lastModified[objectVersionSortKey(item.Key, item.VersionID)] = marker.LastModified.UnixNano()
+		files = append(files, item)
+	}
+	sortObjectVersions(files, lastModified)
+
+	items := make([]ObjectVersionInfo, 0, len(folders)+len(files))
+	items = append(items, folders...)
+	items = append(items, files...)
+
+	return ObjectVersionListPageResult{
+		Items:               items,
+		NextKeyMarker:       objectKeyForClient(lor.NextKeyMarker),
+		NextVersionIdMarker: lor.NextVersionIdMarker,
+		IsTruncated:         lor.IsTruncated,
+	}, nil
+}
+
+// walkObjectVersions lists every version and delete marker whose key starts with prefix.
+func walkObjectVersions(bkt *oss.Bucket, prefix string, fn func(versions []oss.ObjectVersionProperties, markers []oss.ObjectDeleteMarkerProperties) error) error {
+	keyMarker := ""
+	versionIDMarker := ""
+	for {
+		options := []oss.Option{oss.Prefix(prefix), oss.MaxKeys(1000)}
+		if keyMarker != "" {
+			options = append(options, oss.KeyMarker(keyMarker), oss.VersionIdMarker(versionIDMarker))
+		}
+		lor, err := bkt.ListObjectVersions(options...)
+		if err != nil {
+			return fmt.Errorf("failed to list object versions: %w", err)
+		}
+		if err := fn(lor.ObjectVersions, lor.ObjectDeleteMarkers); err != nil {
+			return err
+		}
+		if !lor.IsTruncated || lor.NextKeyMarker == "" {
+			return nil
+		}
+		keyMarker = lor.NextKeyMarker
+		versionIDMarker = lor.NextVersionIdMarker
+	}
+}
+
+// ListObjectKeyVersions returns the full version history of a single object, newest first.
+func (s *OSSService) ListObjectKeyVersions(config OSSConfig, bucketName string, key string) ([]ObjectVersionInfo, error) {
+	key = normalizeObjectKey(key)
+	if key == "" {
+		return nil, errors.New("object key is empty")
+	}
+	bkt, err := s.openBucket(config, bucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	lastModified := map[string]int64{}
+	items := []ObjectVersionInfo{}
+	err = walkObjectVersions(bkt, key, func(versions []oss.ObjectVersionProperties, markers []oss.ObjectDeleteMarkerProperties) error {
+		for _, version := range versions {
+			if version.Key != key {
+				continue
+			}
+			lastModified[objectVersionSortKey(version.Key, version.VersionId)] = version.LastModified.UnixNano()
+			items = append(items, objectVersionFromProperties(bkt.BucketName, version))
+		}
+		for _, marker := range markers {
+			if marker.Key != key {
+				continue
+			}
+			lastModified[objectVersionSortKey(marker.Key, marker.VersionId)] = marker.LastModified.UnixNano()
+			items = append(items, objectDeleteMarkerInfo(bkt.BucketName, marker))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortObjectVersions(items, lastModified)
+	return items, nil
+}
+
+// GetObjectVersionText reads the beginning of a specific object version for preview.
+func (s *OSSService) GetObjectVersionText(config OSSConfig, bucket string, object string, versionID string, maxBytes int) (string, error) {
+	versionID = strings.TrimSpace(versionID)
+	if versionID == "" {
+		return "", errors.New("version id is empty")
+	}
+	return s.getObjectText(config, bucket, object, versionID, maxBytes)
+}
+
+// PresignObjectVersion signs a GET URL for a specific object version, used by media previews.
+func (s *OSSService) PresignObjectVersion(config OSSConfig, bucket string, object string, versionID string, expiresDuration string) (string, error) {
+	versionID = strings.TrimSpace(versionID)
+	if versionID == "" {
+		return "", errors.New("version id is empty")
+	}
+	return s.presignObject(config, bucket, object, expiresDuration, oss.VersionId(versionID))
+}
+
+// RestoreObjectVersion makes an older version current again by copying it over the object.
+// The previous current version is kept as a non-current version.
+func (s *OSSService) RestoreObjectVersion(config OSSConfig, bucketName string, key string, versionID string) error {
+	key = normalizeObjectKey(key)
+	versionID = strings.TrimSpace(versionID)
+	if key == "" || versionID == "" {
+		return errors.New("object key and version id are required")
+	}
+	bkt, err := s.openBucket(config, bucketName)
+	if err != nil {
+		return err
+	}
+
+	header, err := bkt.GetObjectDetailedMeta(key, oss.VersionId(versionID))
+	if err != nil {
+		return fmt.Errorf("failed to read object version: %w", err)
+	}
+	target := objectTargetFromHeader(key, header)
+	src := objectCopySource{Bucket: bkt.BucketName, Key: key, VersionID: versionID, Size: target.Size}
+	if err := copyObjectPreservingMetadata(bkt, src, key); err != nil {
+		return err
+	}
+	s.invalidateListingCacheForConfig(config, bkt.BucketName, key)
+	return nil
+}
+
+// DeleteObjectVersions permanently deletes the given versions or delete markers.
+func (s *OSSService) DeleteObjectVersions(config OSSConfig, bucketName string, versions []ObjectVersionRef) error {
+	bkt, err := s.openBucket(config, bucketName)
+	if err != nil {
+		return err
+	}
+
+	objects := make([]oss.DeleteObject, 0, len(versions))
+	for _, version := range versions {
+		key := normalizeObjectKey(version.Key)
+		versionID := strings.TrimSpace(version.VersionID)
+		if key == "" || versionID == "" {
+			return errors.New("object key and version id are required")
+		}
+		objects = append(objects, oss.DeleteObject{Key: key, VersionId: versionID})
+	}
+	if len(objects) == 0 {
+		return errors.New("no versions to delete")
+	}
+	err = deleteObjectVersionsInBatches(bkt, objects)
+	keys := make([]string, 0, len(objects))
+	for _, object := range objects {
+		keys = append(keys, object.Key)
+	}
+	s.invalidateListingCacheForConfig(config, bkt.BucketName, keys...)
+	return err
+}
+
+func deleteObjectVersionsInBatches(bkt *oss.Bucket, objects []oss.DeleteObject) error {
+	for start := 0; start < len(objects); start += 1000 {
+		end := start + 1000
+		if end > len(objects) {
+			end = len(objects)
+		}
+		if _, err := bkt.DeleteObjectVersions(objects[start:end], oss.DeleteObjectsQuiet(true)); err != nil {
+			return fmt.Errorf("delete versions failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// UndeleteObjects removes the current delete markers of the selected keys so their latest
+// version becomes visible again. Keys ending with "/" are undeleted recursively.
+// It returns the number of objects that were restored.
+func (s *OSSService) UndeleteObjects(config OSSConfig, bucketName string, keys []string) (int, error) {
+	bkt, err := s.openBucket(config, bucketName)
+	if err != nil {
+		return 0, err
+	}
+
+	markers := make([]oss.DeleteObject, 0, len(keys))
+	seen := map[string]struct{}{}
+	for _, key := range keys {
+		key = normalizeObjectKey(key)
+		if key == "" {
+			continue
+		}
+		isFolder := strings.HasSuffix(key, "/")
+		err := walkObjectVersions(bkt, key, func(_ []oss.ObjectVersionProperties, deleteMarkers []oss.ObjectDeleteMarkerProperties) error {
+			for _, marker := range deleteMarkers {
+				if !marker.IsLatest || (!isFolder && marker.Key != key) {
+					continue
+				}
+				if _, ok := seen[marker.Key]; ok {
+					continue
+				}
+				seen[marker.Key] = struct{}{}
+				markers = append(markers, oss.DeleteObject{Key: marker.Key, VersionId: marker.VersionId})
+			}
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if len(markers) == 0 {
+		return 0, nil
+	}
+	err = deleteObjectVersionsInBatches(bkt, markers)
+	s.invalidateListingCacheForConfig(config, bkt.BucketName, keys...)
+	if err != nil {
+		return 0, err
+	}
+	return len(markers), nil
+}
@@ -0,0 +1,767 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+	"golang.org/x/net/webdav"
+)
+
+// The WebDAV server exposes one bucket prefix of a profile on 127.0.0.1, so editors, file
+// managers and davfs can work on OSS with the app's credentials. Folders are listed page by
+// page with a "/" delimiter, reads are ranged GETs, and writes are buffered into a single
+// PUT or, past webdavPartSize, a multipart upload that only completes when the request body
+// was received in full.
+
+const (
+	webdavPartSize        = 8 << 20
+	webdavStatCacheTTL    = 10 * time.Second
+	webdavShutdownWait    = 5 * time.Second
+	webdavListPageSize    = 1000
+	webdavAuthRealm       = "walioss"
+	webdavDefaultUsername = "walioss"
+	webdavListenHostname  = "127.0.0.1"
+)
+
+// WebDAVOptions selects what the WebDAV server exposes. Port 0 picks a free port. Basic
+// auth is always required; an empty Username or Password is generated.
+type WebDAVOptions struct {
+	Bucket   string `json:"bucket"`
+	Prefix   string `json:"prefix"`
+	Port     int    `json:"port"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// WebDAVStatus describes the running WebDAV server. Error is set when the server stopped on
+// its own.
+type WebDAVStatus struct {
+	Running     bool   `json:"running"`
+	URL         string `json:"url,omitempty"`
+	Port        int    `json:"port,omitempty"`
+	ProfileName string `json:"profileName,omitempty"`
+	Bucket      string `json:"bucket,omitempty"`
+	Prefix      string `json:"prefix,omitempty"`
+	Username    string `json:"username,omitempty"`
+	Password    string `json:"password,omitempty"`
+	StartedAtMs int64  `json:"startedAtMs,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+type webdavServer struct {
+	server *http.Server
+	status WebDAVStatus
+}
+
+// StartWebDAVServer serves bucket/prefix over WebDAV on 127.0.0.1. Only one server runs at a
+// time.
+func (s *OSSService) StartWebDAVServer(config OSSConfig, options WebDAVOptions) (WebDAVStatus, error) {
+	s.webdavMu.Lock()
+	defer s.webdavMu.Unlock()
+	if s.webdav != nil && s.webdav.status.Running {
+		return s.webdav.status, fmt.Errorf("a WebDAV server is already running at %s; stop it first", s.webdav.status.URL)
+	}
+
+	username := strings.TrimSpace(options.Username)
+	if username == "" {
+		username = webdavDefaultUsername
+	}
+	password := options.Password
+	if password == "" {
+		generated, err := randomWebDAVPassword()
+		if err != nil {
+			return WebDAVStatus{}, err
+		}
+		password = generated
+	}
+	if options.Port < 0 || options.Port > 65535 {
+		return WebDAVStatus{}, fmt.Errorf("invalid port: %d", options.Port)
+	}
+	bkt, err := s.openBucket(config, options.Bucket)
+	if err != nil {
+		return WebDAVStatus{}, err
+	}
+	prefix := normalizeObjectPrefix(options.Prefix)
+	if _, err := bkt.ListObjects(oss.Prefix(prefix), oss.MaxKeys(1)); err != nil {
+		return WebDAVStatus{}, fmt.Errorf("failed to access bucket: %w", err)
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(webdavListenHostname, strconv.Itoa(options.Port)))
+	if err != nil {
+		return WebDAVStatus{}, fmt.Errorf("failed to listen: %w", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	fs := &ossDavFS{
+		s:         s,
+		config:    config,
+		bkt:       bkt,
+		prefix:    prefix,
+		startedAt: time.Now(),
+		stats:     map[string]ossDavStat{},
+	}
+	var handler http.Handler = &webdav.Handler{FileSystem: fs, LockSystem: webdav.NewMemLS()}
+	handler = webdavTrackRequests(handler)
+	handler = webdavBasicAuth(handler, username, password)
+	handler = webdavLocalHostOnly(handler, port)
+
+	server := &webdavServer{
+		server: &http.Server{Handler: handler, ReadHeaderTimeout: 30 * time.Second},
+		status: WebDAVStatus{
+			Running:     true,
+			URL:         fmt.Sprintf("http://%s/", net.JoinHostPort(webdavListenHostname, strconv.Itoa(port))),
+			Port:        port,
+			ProfileName: s.resolveTransferProfileName(config),
+			Bucket:      bkt.BucketName,
+			Prefix:      prefix,
+			Username:    username,
+			Password:    password,
+			StartedAtMs: fs.startedAt.UnixMilli(),
+		},
+	}
+	s.webdav = server
+	go func() {
+		err := server.server.Serve(listener)
+		s.webdavMu.Lock()
+		defer s.webdavMu.Unlock()
+		server.status.Running = false
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			server.status.Error = err.Error()
+		}
+	}()
+	return server.status, nil
+}
+
+// StopWebDAVServer stops the WebDAV server, waiting briefly for requests in flight. It does
+// nothing when no server is running.
+func (s *OSSService) StopWebDAVServer() error {
+	s.webdavMu.Lock()
+	server := s.webdav
+	s.webdav = nil
+	s.webdavMu.Unlock()
+	if server == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webdavShutdownWait)
+	defer cancel()
+	if err := server.server.Shutdown(ctx); err != nil {
+		server.server.Close()
+		return fmt.Errorf("failed to stop the WebDAV server: %w", err)
+	}
+	return nil
+}
+
+// GetWebDAVStatus reports whether the WebDAV server is running and what it serves.
+func (s *OSSService) GetWebDAVStatus() WebDAVStatus {
+	s.webdavMu.Lock()
+	defer s.webdavMu.Unlock()
+	if s.webdav == nil {
+		return WebDAVStatus{}
+	}
+	return s.webdav.status
+}
+
+func randomWebDAVPassword() (string, error) {
+	buf := make([]byte, 18)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate a password: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// webdavLocalHostOnly rejects requests addressed to any other host than the server's own
+// loopback address, so a web page cannot reach the server through DNS rebinding, and
+// requests a browser sends on behalf of another origin.
+func webdavLocalHostOnly(next http.Handler, port int) http.Handler {
+	allowed := map[string]bool{
+		net.JoinHostPort(webdavListenHostname, strconv.Itoa(port)): true,
+		net.JoinHostPort("localhost", strconv.Itoa(port)):          true,
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !allowed[strings.ToLower(r.Host)] {
+			http.Error(w, "unexpected host", http.StatusForbidden)
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" {
+			u, err := url.Parse(origin)
+			if err != nil || !allowed[strings.ToLower(u.Host)] {
+				http.Error(w, "cross-origin requests are not allowed", http.StatusForbidden)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func webdavBasicAuth(next http.Handler, username string, password string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok ||
+			subtle.ConstantTimeCompare([]byte(user), []byte(username)) != 1 ||
+			subtle.ConstantTimeCompare([]byte(pass), []byte(password)) != 1 {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf("Basic realm=%q", webdavAuthRealm))
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// webdavRequestState records whether reading the request body or a source object failed.
+// The webdav handler closes files it wrote even after a failed copy, so uploads check it to
+// avoid completing with partial content.
+type webdavRequestState struct {
+	err error
+}
+
+type webdavRequestStateKey struct{}
+
+func webdavTrackRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state := &webdavRequestState{}
+		r = r.WithContext(context.WithValue(r.Context(), webdavRequestStateKey{}, state))
+		if r.Body != nil {
+			r.Body = &webdavTrackedBody{ReadCloser: r.Body, state: state}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+type webdavTrackedBody struct {
+	io.ReadCloser
+	state *webdavRequestState
+}
+
+func (b *webdavTrackedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		b.state.err = err
+	}
+	return n, err
+}
+
+func webdavRequestFailed(ctx context.Context, err error) {
+	if state, ok := ctx.Value(webdavRequestStateKey{}).(*webdavRequestState); ok && state.err == nil {
+		state.err = err
+	}
+}
+
+func webdavRequestError(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if state, ok := ctx.Value(webdavRequestStateKey{}).(*webdavRequestState); ok {
+		return state.err
+	}
+	return nil
+}
+
+// ossDavFS implements webdav.FileSystem over one bucket prefix. Names are slash-separated
+// paths relative to the prefix.
+type ossDavFS struct {
+	s         *OSSService
+	config    OSSConfig
+	bkt       *oss.Bucket
+	prefix    string
+	startedAt time.Time
+
+	statMu sync.Mutex
+	stats  map[string]ossDavStat
+}
+
+// ossDavStat caches what a listing or HEAD found for a name, so the per-entry lookups of a
+// PROPFIND do not each cost a request.
+type ossDavStat struct {
+	info    *ossDavFileInfo
+	expires time.Time
+}
+
+func cleanDavName(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+func (fs *ossDavFS) objectKey(name string) string {
+	return fs.prefix + cleanDavName(name)
+}
+
+// dirPrefix returns the listing prefix of the folder at key.
+func (fs *ossDavFS) dirPrefix(key string) string {
+	if key == fs.prefix {
+		return fs.prefix
+	}
+	return key + "/"
+}
+
+func (fs *ossDavFS) cachedStat(key string) (*ossDavFileInfo, bool) {
+	fs.statMu.Lock()
+	defer fs.statMu.Unlock()
+	stat, ok := fs.stats[key]
+	if !ok || time.Now().After(stat.expires) {
+		return nil, false
+	}
+	return stat.info, true
+}
+
+func (fs *ossDavFS) storeStats(keys []string, infos []*ossDavFileInfo) {
+	fs.statMu.Lock()
+	defer fs.statMu.Unlock()
+	expires := time.Now().Add(webdavStatCacheTTL)
+	for i, key := range keys {
+		fs.stats[key] = ossDavStat{info: infos[i], expires: expires}
+	}
+}
+
+// changed drops cached lookups and tells the file browser that the folder holding key
+// changed.
+func (fs *ossDavFS) changed(keys ...string) {
+	fs.statMu.Lock()
+	fs.stats = map[string]ossDavStat{}
+	fs.statMu.Unlock()
+
+	fs.s.invalidateListingCacheForConfig(fs.config, fs.bkt.BucketName, keys...)
+	for _, key := range keys {
+		fs.s.emitEvent("objects:changed", map[string]string{
+			"bucket": fs.bkt.BucketName,
+			"prefix": listingParentPrefix(key),
+		})
+	}
+}
+
+func (fs *ossDavFS) stat(name string) (*ossDavFileInfo, error) {
+	key := fs.objectKey(name)
+	if key == fs.prefix {
+		return &ossDavFileInfo{name: "/", dir: true, modTime: fs.startedAt}, nil
+	}
+	if info, ok := fs.cachedStat(key); ok {
+		return info, nil
+	}
+
+	header, err := fs.bkt.GetObjectDetailedMeta(key)
+	if err == nil {
+		info := &ossDavFileInfo{
+			name:        path.Base(key),
+			etag:        header.Get(oss.HTTPHeaderEtag),
+			contentType: header.Get(oss.HTTPHeaderContentType),
+		}
+		info.size, _ = strconv.ParseInt(header.Get(oss.HTTPHeaderContentLength), 10, 64)
+		info.modTime, _ = http.ParseTime(header.Get(oss.HTTPHeaderLastModified))
+		fs.storeStats([]string{key}, []*ossDavFileInfo{info})
+		return info, nil
+	}
+	if !isObjectNotFound(err) {
+		return nil, err
+	}
+
+	lor, err := fs.bkt.ListObjects(oss.Prefix(key+"/"), oss.MaxKeys(1))
+	if err != nil {
+		return nil, err
+	}
+	if len(lor.Objects) == 0 {
+		return nil, os.ErrNotExist
+	}
+	info := &ossDavFileInfo{name: path.Base(key), dir: true, modTime: fs.startedAt}
+	fs.storeStats([]string{key}, []*ossDavFileInfo{info})
+	return info, nil
+}
+
+// readDir lists the folder at key page by page.
+func (fs *ossDavFS) readDir(key string) ([]os.FileInfo, error) {
+	listPrefix := fs.dirPrefix(key)
+	var keys []string
+	var infos []*ossDavFileInfo
+	marker := ""
+	for {
+		lor, err := fs.bkt.ListObjects(oss.Prefix(listPrefix), oss.Delimiter("/"), oss.Marker(marker), oss.MaxKeys(webdavListPageSize))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w", err)
+		}
+		for _, commonPrefix := range lor.CommonPrefixes {
+			name := strings.TrimSuffix(strings.TrimPrefix(commonPrefix, listPrefix), "/")
+			if name == "" || strings.Contains(name, "/") {
+				continue
+			}
+			keys = append(keys, listPrefix+name)
+			infos = append(infos, &ossDavFileInfo{name: name, dir: true, modTime: fs.startedAt})
+		}
+		for _, object := range lor.Objects {
+			name := strings.TrimPrefix(object.Key, listPrefix)
+			if name == "" || strings.Contains(name, "/") {
+				continue
+			}
+			keys = append(keys, object.Key)
+			infos = append(infos, &ossDavFileInfo{
+				name:    name,
+				size:    object.Size,
+				modTime: object.LastModified,
+				etag:    object.ETag,
+			})
+		}
+		if !lor.IsTruncated {
+			break
+		}
+		marker = lor.NextMarker
+	}
+	fs.storeStats(keys, infos)
+
+	out := make([]os.FileInfo, len(infos))
+	for i, info := range infos {
+		out[i] = info
+	}
+	return out, nil
+}
+
+func (fs *ossDavFS) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	return fs.stat(name)
+}
+
+func (fs *ossDavFS) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	key := fs.objectKey(name)
+	if key == fs.prefix {
+		return os.ErrExist
+	}
+	if _, err := fs.stat(name); err == nil {
+		return os.ErrExist
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	if parent, err := fs.stat(path.Dir("/" + cleanDavName(name))); err != nil || !parent.dir {
+		return os.ErrNotExist
+	}
+	if err := fs.bkt.PutObject(key+"/", bytes.NewReader(nil)); err != nil {
+		return fmt.Errorf("failed to create folder: %w", err)
+	}
+	fs.changed(key + "/")
+	return nil
+}
+
+func (fs *ossDavFS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	key := fs.objectKey(name)
+	if flag&(os.O_WRONLY|os.O_RDWR) == 0 {
+		info, err := fs.stat(name)
+		if err != nil {
+			return nil, err
+		}
+		return &ossDavFile{ctx: ctx, fs: fs, key: key, info: info}, nil
+	}
+
+	if flag&os.O_TRUNC == 0 {
+		return nil, errors.New("only whole-file writes are supported")
+	}
+	if key == fs.prefix {
+		return nil, os.ErrPermission
+	}
+	if info, err := fs.stat(name); err == nil {
+		if info.dir {
+			return nil, fmt.Errorf("%s is a folder", name)
+		}
+		if flag&os.O_EXCL != 0 {
+			return nil, os.ErrExist
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	if parent, err := fs.stat(path.Dir("/" + cleanDavName(name))); err != nil || !parent.dir {
+		return nil, os.ErrNotExist
+	}
+	info := &ossDavFileInfo{name: path.Base(key), modTime: time.Now()}
+	return &ossDavFile{ctx: ctx, fs: fs, key: key, info: info, writer: &ossDavWriter{}}, nil
+}
+
+// RemoveAll deletes name, recursively for folders, the way deletes from the file browser
+// are: into the recycle bin or as delete markers when the profile or bucket has one, and
+// journaled either way.
+func (fs *ossDavFS) RemoveAll(ctx context.Context, name string) error {
+	key := fs.objectKey(name)
+	if key == fs.prefix {
+		return os.ErrPermission
+	}
+	info, err := fs.stat(name)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.dir {
+		key += "/"
+	}
+	defer fs.changed(key)
+	return fs.s.DeleteObject(fs.config, fs.bkt.BucketName, objectKeyForClient(key))
+}
+
+func (fs *ossDavFS) Rename(ctx context.Context, oldName string, newName string) error {
+	srcKey, destKey := fs.objectKey(oldName), fs.objectKey(newName)
+	if srcKey == fs.prefix || destKey == fs.prefix {
+		return os.ErrPermission
+	}
+	info, err := fs.stat(oldName)
+	if err != nil {
+		return err
+	}
+	if info.dir {
+		srcKey += "/"
+		destKey += "/"
+	}
+	defer fs.changed(srcKey, destKey)
+	return fs.s.MoveObject(fs.config, fs.bkt.BucketName, objectKeyForClient(srcKey), fs.bkt.BucketName, objectKeyForClient(destKey))
+}
+
+type ossDavFileInfo struct {
+	name        string
+	size        int64
+	modTime     time.Time
+	dir         bool
+	etag        string
+	contentType string
+}
+
+func (fi *ossDavFileInfo) Name() string       { return fi.name }
+func (fi *ossDavFileInfo) Size() int64        { return fi.size }
+func (fi *ossDavFileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *ossDavFileInfo) IsDir() bool        { return fi.dir }
+func (fi *ossDavFileInfo) Sys() interface{}   { return nil }
+
+func (fi *ossDavFileInfo) Mode() os.FileMode {
+	if fi.dir {
+		return os.ModeDir | 0o755
+	}
+	return 0o644
+}
+
+// ETag implements webdav.ETager with the object's ETag.
+func (fi *ossDavFileInfo) ETag(ctx context.Context) (string, error) {
+	if fi.etag == "" {
+		return "", webdav.ErrNotImplemented
+	}
+	return `"` + strings.Trim(fi.etag, `"`) + `"`, nil
+}
+
+// ContentType implements webdav.ContentTyper so listings never read objects to sniff their
+// type.
+func (fi *ossDavFileInfo) ContentType(ctx context.Context) (string, error) {
+	if fi.contentType != "" {
+		return fi.contentType, nil
+	}
+	if ctype := mime.TypeByExtension(path.Ext(fi.name)); ctype != "" {
+		return ctype, nil
+	}
+	return "application/octet-stream", nil
+}
+
+// ossDavFile is an object or folder opened by the webdav handler. Reads open a ranged GET
+// from the current offset and reopen it after a seek; writes go to writer.
+type ossDavFile struct {
+	ctx  context.Context
+	fs   *ossDavFS
+	key  string
+	info *ossDavFileInfo
+
+	offset int64
+	body   io.ReadCloser
+
+	children []os.FileInfo
+	listed   bool
+
+	writer *ossDavWriter
+}
+
+func (f *ossDavFile) Read(p []byte) (int, error) {
+	if f.info.dir {
+		return 0, fmt.Errorf("%s is a folder", f.info.name)
+	}
+	if f.writer != nil {
+		return 0, os.ErrPermission
+	}
+	if f.offset >= f.info.size {
+		return 0, io.EOF
+	}
+	if f.body == nil {
+		body, err := f.fs.bkt.GetObject(f.key, oss.Range(f.offset, f.info.size-1))
+		if err != nil {
+			webdavRequestFailed(f.ctx, err)
+			return 0, err
+		}
+		f.body = body
+	}
+	n, err := f.body.Read(p)
+	f.offset += int64(n)
+	if err == io.EOF && f.offset < f.info.size {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil && err != io.EOF {
+		webdavRequestFailed(f.ctx, err)
+	}
+	return n, err
+}
+
+func (f *ossDavFile) Seek(offset int64, whence int) (int64, error) {
+	next := offset
+	switch whence {
+	case io.SeekCurrent:
+		next += f.offset
+	case io.SeekEnd:
+		next += f.info.size
+	}
+	if next < 0 {
+		return f.offset, errors.New("negative position")
+	}
+	if next != f.offset && f.body != nil {
+		f.body.Close()
+		f.body = nil
+	}
+	f.offset = next
+	return next, nil
+}
+
+func (f *ossDavFile) Readdir(count int) ([]os.FileInfo, error) {
+	if !f.info.dir {
+		return nil, fmt.Errorf("%s is not a folder", f.info.name)
+	}
+	if !f.listed {
+		children, err := f.fs.readDir(f.key)
+		if err != nil {
+			return nil, err
+		}
+		f.children = children
+		f.listed = true
+	}
+	if count <= 0 {
+		out := f.children
+		f.children = nil
+		return out, nil
+	}
+	if len(f.children) == 0 {
+		return nil, io.EOF
+	}
+	count = min(count, len(f.children))
+	out := f.children[:count]
+	f.children = f.children[count:]
+	return out, nil
+}
+
+func (f *ossDavFile) Stat() (os.FileInfo, error) {
+	return f.info, nil
+}
+
+func (f *ossDavFile) Write(p []byte) (int, error) {
+	if f.writer == nil {
+		return 0, os.ErrPermission
+	}
+	n, err := f.writer.write(f.fs.bkt, f.key, p)
+	f.info.size += int64(n)
+	return n, err
+}
+
+func (f *ossDavFile) Close() error {
+	if f.body != nil {
+		f.body.Close()
+		f.body = nil
+	}
+	if f.writer == nil {
+		return nil
+	}
+	writer := f.writer
+	f.writer = nil
+	if err := webdavRequestError(f.ctx); err != nil {
+		writer.abort(f.fs.bkt)
+		return fmt.Errorf("upload of %s abandoned: %w", f.info.name, err)
+	}
+	err := writer.finish(f.fs.bkt, f.key)
+	f.fs.changed(f.key)
+	return err
+}
+
+// ossDavWriter uploads what the webdav handler writes. Content up to webdavPartSize is sent
+// with a single PUT on close; anything larger becomes a multipart upload.
+type ossDavWriter struct {
+	buf    bytes.Buffer
+	upload *oss.InitiateMultipartUploadResult
+	parts  []oss.UploadPart
+	err    error
+}
+
+func webdavObjectOptions(key string) []oss.Option {
+	if ctype := mime.TypeByExtension(path.Ext(key)); ctype != "" {
+		return []oss.Option{oss.ContentType(ctype)}
+	}
+	return nil
+}
+
+func (w *ossDavWriter) write(bkt *oss.Bucket, key string, p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n, _ := w.buf.Write(p)
+	for w.buf.Len() >= webdavPartSize {
+		if err := w.uploadPart(bkt, key, w.buf.Next(webdavPartSize)); err != nil {
+			w.err = err
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func (w *ossDavWriter) uploadPart(bkt *oss.Bucket, key string, data []byte) error {
+	if w.upload == nil {
+		upload, err := bkt.InitiateMultipartUpload(key, webdavObjectOptions(key)...)
+		if err != nil {
+			return fmt.Errorf("failed to start multipart upload: %w", err)
+		}
+		w.upload = &upload
+	}
+	part, err := bkt.UploadPart(*w.upload, bytes.NewReader(data), int64(len(data)), len(w.parts)+1)
+	if err != nil {
+		return fmt.Errorf("failed to upload part %d: %w", len(w.parts)+1, err)
+	}
+	w.parts = append(w.parts, part)
+	return nil
+}
+
+func (w *ossDavWriter) finish(bkt *oss.Bucket, key string) error {
+	if w.err != nil {
+		w.abort(bkt)
+		return w.err
+	}
+	if w.upload == nil {
+		if err := bkt.PutObject(key, bytes.NewReader(w.buf.Bytes()), webdavObjectOptions(key)...); err != nil {
+			return fmt.Errorf("upload failed: %w", err)
+		}
+		return nil
+	}
+	if w.buf.Len() > 0 {
+		if err := w.uploadPart(bkt, key, w.buf.Bytes()); err != nil {
+			w.abort(bkt)
+			return err
+		}
+	}
+	if _, err := bkt.CompleteMultipartUpload(*w.upload, w.parts); err != nil {
+		w.abort(bkt)
+		return fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+	return nil
+}
+
+func (w *ossDavWriter) abort(bkt *oss.Bucket) {
+	if w.upload != nil {
+		_ = bkt.AbortMultipartUpload(*w.upload)
+		w.upload = nil
+	}
+}
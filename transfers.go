@@ -27,8 +27,12 @@ import (
 type TransferType string
 
 const (
-	TransferTypeUpload   TransferType = "upload"
-	TransferTypeDownload TransferType = "download"
+	TransferTypeUpload       TransferType = "upload"
+	TransferTypeDownload     TransferType = "download"
+	TransferTypeStorageClass TransferType = "storage-class"
+	TransferTypeRestore      TransferType = "restore"
+	TransferTypeRename       TransferType = "rename"
+	TransferTypeCopy         TransferType = "copy"
 )
 
 type TransferStatus string
@@ -49,28 +53,32 @@ const (
 )
 
 type TransferUpdate struct {
-	ID               string         `json:"id"`
-	ProfileName      string         `json:"profileName,omitempty"`
-	Type             TransferType   `json:"type"`
-	Status           TransferStatus `json:"status"`
-	Name             string         `json:"name"`
-	Bucket           string         `json:"bucket"`
-	Key              string         `json:"key"`
-	LocalPath        string         `json:"localPath,omitempty"`
-	ParentID         string         `json:"parentId,omitempty"`
-	IsGroup          bool           `json:"isGroup,omitempty"`
-	FileCount        int            `json:"fileCount,omitempty"`
-	DoneCount        int            `json:"doneCount,omitempty"`
-	SuccessCount     int            `json:"successCount,omitempty"`
-	ErrorCount       int            `json:"errorCount,omitempty"`
-	TotalBytes       int64          `json:"totalBytes,omitempty"`
-	DoneBytes        int64          `json:"doneBytes,omitempty"`
-	SpeedBytesPerSec float64        `json:"speedBytesPerSec,omitempty"`
-	EtaSeconds       int64          `json:"etaSeconds,omitempty"`
-	Message          string         `json:"message,omitempty"`
-	StartedAtMs      int64          `json:"startedAtMs,omitempty"`
-	UpdatedAtMs      int64          `json:"updatedAtMs,omitempty"`
-	FinishedAtMs     int64          `json:"finishedAtMs,omitempty"`
+	ID                string         `json:"id"`
+	ProfileName       string         `json:"profileName,omitempty"`
+	Type              TransferType   `json:"type"`
+	Status            TransferStatus `json:"status"`
+	Name              string         `json:"name"`
+	Bucket            string         `json:"bucket"`
+	Key               string         `json:"key"`
+	LocalPath         string         `json:"localPath,omitempty"`
+	VersionID         string         `json:"versionId,omitempty"`
+	SourceProfileName string         `json:"sourceProfileName,omitempty"`
+	SourceBucket      string         `json:"sourceBucket,omitempty"`
+	SourceKey         string         `json:"sourceKey,omitempty"`
+	ParentID          string         `json:"parentId,omitempty"`
+	IsGroup           bool           `json:"isGroup,omitempty"`
+	FileCount         int            `json:"fileCount,omitempty"`
+	DoneCount         int            `json:"doneCount,omitempty"`
+	SuccessCount      int            `json:"successCount,omitempty"`
+	ErrorCount        int            `json:"errorCount,omitempty"`
+	TotalBytes        int64          `json:"totalBytes,omitempty"`
+	DoneBytes         int64          `json:"doneBytes,omitempty"`
+	SpeedBytesPerSec  float64        `json:"speedBytesPerSec,omitempty"`
+	EtaSeconds        int64          `json:"etaSeconds,omitempty"`
+	Message           string         `json:"message,omitempty"`
+	StartedAtMs       int64          `json:"startedAtMs,omitempty"`
+	UpdatedAtMs       int64          `json:"updatedAtMs,omitempty"`
+	FinishedAtMs      int64          `json:"finishedAtMs,omitempty"`
 }
 
 type transferHistoryStore struct {
@@ -128,14 +136,18 @@ func (s *OSSService) SetContext(ctx context.Context) {
 	s.transferCtxMu.Unlock()
 }
 
-func (s *OSSService) emitTransferUpdate(update TransferUpdate) {
+func (s *OSSService) emitEvent(name string, payload interface{}) {
 	s.transferCtxMu.RLock()
 	ctx := s.transferCtx
 	s.transferCtxMu.RUnlock()
 	if ctx == nil {
 		return
 	}
-	runtime.EventsEmit(ctx, "transfer:update", update)
+	runtime.EventsEmit(ctx, name, payload)
+}
+
+func (s *OSSService) emitTransferUpdate(update TransferUpdate) {
+	s.emitEvent("transfer:update", update)
 }
 
 func (s *OSSService) emitTransfer(update TransferUpdate, onUpdate func(TransferUpdate)) {
@@ -565,16 +577,11 @@ func normalizeTransferBucket(bucket string) string {
 }
 
 func normalizeTransferPrefix(prefix string) string {
-	prefix = strings.TrimSpace(prefix)
-	prefix = strings.TrimLeft(prefix, "/")
-	if prefix != "" && !strings.HasSuffix(prefix, "/") {
-		prefix += "/"
-	}
-	return prefix
+	return normalizeObjectPrefix(prefix)
 }
 
 func normalizeTransferObjectKey(key string) string {
-	return strings.TrimLeft(strings.TrimSpace(key), "/")
+	return normalizeObjectKey(key)
 }
 
 func normalizeTransferFolderKey(key string) string {
@@ -601,6 +608,41 @@ func safeRelativeDownloadPath(relative string) (string, error) {
 	return clean, nil
 }
 
+// downloadPathAllocator hands out the local paths of one download group. Different keys can
+// sanitize to the same name ("a.txt" and "a.txt "), and names differing only in case clash
+// on case-insensitive file systems, so a path that is already taken gets a " (2)", " (3)"...
+// suffix before its extension instead of overwriting the earlier download.
+type downloadPathAllocator struct {
+	used map[string]struct{}
+}
+
+func newDownloadPathAllocator() *downloadPathAllocator {
+	return &downloadPathAllocator{used: map[string]struct{}{}}
+}
+
+func downloadPathSlot(relative string) string {
+	return strings.ToLower(filepath.ToSlash(relative))
+}
+
+// reserve marks relative as taken, for files that exist locally and must not be replaced.
+func (a *downloadPathAllocator) reserve(relative string) {
+	a.used[downloadPathSlot(relative)] = struct{}{}
+}
+
+// allocate returns relative, or the first suffixed variant of it that is still free.
+func (a *downloadPathAllocator) allocate(relative string) string {
+	candidate := relative
+	ext := filepath.Ext(relative)
+	stem := strings.TrimSuffix(relative, ext)
+	for n := 2; ; n++ {
+		if _, taken := a.used[downloadPathSlot(candidate)]; !taken {
+			a.reserve(candidate)
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s (%d)%s", stem, n, ext)
+	}
+}
+
 func (s *OSSService) newTransferID() string {
 	return fmt.Sprintf("tr-%d-%d", time.Now().UnixMilli(), atomic.AddUint64(&s.transferSeq, 1))
 }
@@ -1076,6 +1118,19 @@ func (s *OSSService) EnqueueUpload(config OSSConfig, bucket string, prefix strin
 }
 
 func (s *OSSService) EnqueueDownload(config OSSConfig, bucket string, object string, localPath string, totalBytes int64) (string, error) {
+	return s.enqueueDownload(config, bucket, object, "", localPath, totalBytes)
+}
+
+// EnqueueDownloadVersion downloads a specific version of an object on a versioned bucket.
+func (s *OSSService) EnqueueDownloadVersion(config OSSConfig, bucket string, object string, versionID string, localPath string, totalBytes int64) (string, error) {
+	versionID = strings.TrimSpace(versionID)
+	if versionID == "" {
+		return "", errors.New("version id is empty")
+	}
+	return s.enqueueDownload(config, bucket, object, versionID, localPath, totalBytes)
+}
+
+func (s *OSSService) enqueueDownload(config OSSConfig, bucket string, object string, versionID string, localPath string, totalBytes int64) (string, error) {
 	localPath = strings.TrimSpace(localPath)
 	object = normalizeTransferObjectKey(object)
 	bucket = normalizeTransferBucket(bucket)
@@ -1105,6 +1160,7 @@ func (s *OSSService) EnqueueDownload(config OSSConfig, bucket string, object str
 		Bucket:      bucket,
 		Key:         object,
 		LocalPath:   localPath,
+		VersionID:   versionID,
 		TotalBytes:  totalBytes,
 		UpdatedAtMs: time.Now().UnixMilli(),
 	}
@@ -1149,6 +1205,7 @@ func (s *OSSService) EnqueueDownloadFolder(config OSSConfig, bucket string, fold
 	children := make([]TransferUpdate, 0, 32)
 	totalBytes := int64(0)
 	marker := ""
+	localPaths := newDownloadPathAllocator()
 	for {
 		lor, listErr := bkt.ListObjects(
 			oss.Prefix(folderKey),
@@ -1160,12 +1217,13 @@ func (s *OSSService) EnqueueDownloadFolder(config OSSConfig, bucket string, fold
 		}
 
 		for _, object := range lor.Objects {
-			key := normalizeTransferObjectKey(object.Key)
+			key := object.Key
 			if key == "" || !strings.HasPrefix(key, folderKey) || strings.HasSuffix(key, "/") {
 				continue
 			}
 
-			relative := strings.TrimPrefix(key, folderKey)
+			// Local file names cannot hold every key, so awkward names are sanitized.
+			relative := sanitizeObjectKey(strings.TrimPrefix(key, folderKey))
 			relative = strings.TrimLeft(relative, "/")
 			if relative == "" {
 				continue
@@ -1175,6 +1233,7 @@ func (s *OSSService) EnqueueDownloadFolder(config OSSConfig, bucket string, fold
 			if relErr != nil {
 				return "", relErr
 			}
+			relativeLocal = localPaths.allocate(relativeLocal)
 
 			localPath := filepath.Join(localRoot, relativeLocal)
 			if mkdirErr := os.MkdirAll(filepath.Dir(localPath), 0o755); mkdirErr != nil {
@@ -1396,7 +1455,7 @@ func (b *ringBuffer) String() string {
 	return strings.TrimSpace(string(b.data))
 }
 
-func (s *OSSService) runTransfer(config OSSConfig, update TransferUpdate, onUpdate func(TransferUpdate)) {
+func (s *OSSService) currentTransferLimiter() *transferLimiter {
 	s.transferLimiterMu.RLock()
 	limiter := s.transferLimiter
 	s.transferLimiterMu.RUnlock()
@@ -1410,7 +1469,11 @@ func (s *OSSService) runTransfer(config OSSConfig, update TransferUpdate, onUpda
 		}
 		s.transferLimiterMu.Unlock()
 	}
+	return limiter
+}
 
+func (s *OSSService) runTransfer(config OSSConfig, update TransferUpdate, onUpdate func(TransferUpdate)) {
+	limiter := s.currentTransferLimiter()
 	limiter.Acquire()
 	defer limiter.Release()
 
@@ -1435,27 +1498,31 @@ func (s *OSSService) runTransfer(config OSSConfig, update TransferUpdate, onUpda
 				return
 			}
 		}
-		cloudURL := fmt.Sprintf("oss://%s/%s", update.Bucket, update.Key)
+		cloudURL, localArg, keyArgs := ossutilObjectURL(update.Bucket, update.Key, update.LocalPath)
 		args = []string{
 			"cp",
 			cloudURL,
-			update.LocalPath,
+			localArg,
 			"--access-key-id", config.AccessKeyID,
 			"--access-key-secret", config.AccessKeySecret,
 			"--region", region,
 			"-f",
 		}
+		args = append(args, keyArgs...)
 	case TransferTypeUpload:
-		cloudURL := fmt.Sprintf("oss://%s/%s", update.Bucket, update.Key)
+		cloudURL, localArg, keyArgs := ossutilObjectURL(update.Bucket, update.Key, update.LocalPath)
 		args = []string{
 			"cp",
-			update.LocalPath,
+			localArg,
 			cloudURL,
 			"--access-key-id", config.AccessKeyID,
 			"--access-key-secret", config.AccessKeySecret,
 			"--region", region,
 			"-f",
 		}
+		args = append(args, keyArgs...)
+	case TransferTypeCopy:
+		// Streamed through the SDK by runStreamCopy below.
 	default:
 		update.Status = TransferStatusError
 		update.Message = "unknown transfer type"
@@ -1465,11 +1532,24 @@ func (s *OSSService) runTransfer(config OSSConfig, update TransferUpdate, onUpda
 		return
 	}
 
+	if update.VersionID != "" && update.Type == TransferTypeDownload {
+		args = append(args, "--version-id", update.VersionID)
+	}
 	if endpoint != "" {
 		args = append(args, "--endpoint", endpoint)
 	}
 
-	err := s.runOssutilWithProgress(args, &update, onUpdate)
+	var err error
+	if update.Type == TransferTypeCopy {
+		err = s.runStreamCopy(config, &update, onUpdate)
+		update.SpeedBytesPerSec = 0
+		update.EtaSeconds = 0
+	} else {
+		err = s.runOssutilWithProgress(args, &update, onUpdate)
+	}
+	if update.Type == TransferTypeUpload || update.Type == TransferTypeCopy {
+		s.invalidateListingCache(update.ProfileName, update.Bucket, update.Key)
+	}
 	update.FinishedAtMs = time.Now().UnixMilli()
 	update.UpdatedAtMs = update.FinishedAtMs
 